@@ -1,7 +1,11 @@
 package arwen
 
 import (
+	"math/big"
+	"time"
+
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
 	"github.com/ElrondNetwork/elrond-vm-common"
 )
 
@@ -26,8 +30,148 @@ const (
 
 	// BreakpointOutOfGas means that Wasmer must stop immediately due to gas being exhausted
 	BreakpointOutOfGas
+
+	// BreakpointTimeout means that Wasmer must stop immediately because the execution exceeded its wall-clock budget
+	BreakpointTimeout
+
+	// BreakpointMemoryLimit means that Wasmer must stop immediately because the memory of the instance exceeded its maximum number of pages
+	BreakpointMemoryLimit
+)
+
+// WASMPageSize is the size of a page of the WASM linear memory, in bytes
+const WASMPageSize = 65536
+
+// ExecutionTimeout is the return code of an execution stopped by the host
+// watchdog for exceeding its wall-clock budget; vmcommon does not define such
+// a return code, so it is placed well after the ones it does define
+const ExecutionTimeout vmcommon.ReturnCode = 100
+
+// ReentrancyNotAllowed is the return code of a call rejected because the
+// reentrancy-protected contract it targets is already on the stack
+const ReentrancyNotAllowed vmcommon.ReturnCode = 101
+
+// AsyncCallTimeout is the return code passed to the error callback of an async
+// call which expired before its response arrived
+const AsyncCallTimeout vmcommon.ReturnCode = 102
+
+// CallDepthExceeded is the return code of a call rejected because it would
+// nest the executions deeper than the maximum call depth of the host
+const CallDepthExceeded vmcommon.ReturnCode = 103
+
+// FeatureFlag identifies a behaviour of the VM which becomes active starting
+// with a configured epoch, so that it does not change the outcome of the
+// blocks processed before its activation
+type FeatureFlag string
+
+const (
+	// SelfDestructFeature enables the selfDestruct EEI function
+	SelfDestructFeature FeatureFlag = "SelfDestruct"
+
+	// AsyncContextsFeature enables the EEI functions which group multiple
+	// async calls into async contexts
+	AsyncContextsFeature FeatureFlag = "AsyncContexts"
+
+	// AsyncCallExpiryFeature enables the expiry of async calls and the
+	// resolution of the expired ones by a later transaction
+	AsyncCallExpiryFeature FeatureFlag = "AsyncCallExpiry"
+
+	// CallbackClosureFeature enables the EEI functions which attach a
+	// callback closure to an async call and read it back in the callback
+	CallbackClosureFeature FeatureFlag = "CallbackClosure"
+
+	// FloatingPointRejectionFeature makes Arwen reject the new contracts
+	// containing floating point instructions, naming them in the error;
+	// before its activation, Arwen leaves the verdict to the Wasmer compiler,
+	// only logging a warning about them if it parses the code to enforce the
+	// ContractComplexityLimits
+	FloatingPointRejectionFeature FeatureFlag = "FloatingPointRejection"
+
+	// MemoryGrowthMeteringFeature charges the pages by which an instance
+	// grows its memory and enforces the maximum number of pages per instance;
+	// before its activation, memory.grow only costs its opcode
+	MemoryGrowthMeteringFeature FeatureFlag = "MemoryGrowthMetering"
+
+	// EEIVersionMetadataFeature records the EEI version in the code metadata
+	// of the newly deployed contracts; before its activation, the metadata is
+	// stored as given and the contracts are bound to the legacy EEI version
+	EEIVersionMetadataFeature FeatureFlag = "EEIVersionMetadata"
+)
+
+// FeatureFlags lists all the features gated by an activation epoch
+var FeatureFlags = []FeatureFlag{
+	SelfDestructFeature,
+	AsyncContextsFeature,
+	AsyncCallExpiryFeature,
+	CallbackClosureFeature,
+	FloatingPointRejectionFeature,
+	MemoryGrowthMeteringFeature,
+	EEIVersionMetadataFeature,
+}
+
+// FeatureGatedEEIFunctions maps the EEI functions which may only be imported
+// by contracts deployed after the activation of a feature to that feature
+var FeatureGatedEEIFunctions = map[string]FeatureFlag{
+	"selfDestruct":               SelfDestructFeature,
+	"createAsyncCall":            AsyncContextsFeature,
+	"setAsyncContextCallback":    AsyncContextsFeature,
+	"setAsyncContextExpiry":      AsyncCallExpiryFeature,
+	"asyncCallWithClosure":       CallbackClosureFeature,
+	"createAsyncCallWithClosure": CallbackClosureFeature,
+	"getCallbackClosureLength":   CallbackClosureFeature,
+	"getCallbackClosure":         CallbackClosureFeature,
+}
+
+// FeatureReservedFunctionNames maps the function names which the host handles
+// itself after the activation of a feature to that feature; the contracts
+// deployed after its activation may not export functions with these names
+var FeatureReservedFunctionNames = map[string]FeatureFlag{
+	ResolveExpiredAsyncCallsFunctionName: AsyncCallExpiryFeature,
+}
+
+// EEIVersion identifies a set of EEI functions which contracts may import
+type EEIVersion uint8
+
+const (
+	// EEIVersionUnspecified is recorded by the contracts deployed before the
+	// EEI was versioned; these are bound to the legacy version of the host
+	EEIVersionUnspecified EEIVersion = iota
+
+	// EEIVersion1_3 is the EEI of Arwen v1.3, without the managed buffer API
+	EEIVersion1_3
+
+	// EEIVersion1_4 is the EEI of Arwen v1.4
+	EEIVersion1_4
 )
 
+// CurrentEEIVersion is the EEI version bound to newly deployed contracts
+const CurrentEEIVersion = EEIVersion1_4
+
+// MaxEEIVersion is the largest EEI version which fits in the code metadata
+const MaxEEIVersion = EEIVersion(0x0F)
+
+// eeiVersionMetadataShift positions the EEI version in the upper half of the
+// second byte of the code metadata, which the metadata flags leave unused
+const eeiVersionMetadataShift = 4
+
+// EEIVersionFromCodeMetadata returns the EEI version recorded in the code metadata
+func EEIVersionFromCodeMetadata(codeMetadata []byte) EEIVersion {
+	if len(codeMetadata) < 2 {
+		return EEIVersionUnspecified
+	}
+
+	return EEIVersion(codeMetadata[1] >> eeiVersionMetadataShift)
+}
+
+// CodeMetadataWithEEIVersion returns a copy of the code metadata which
+// records the given EEI version
+func CodeMetadataWithEEIVersion(codeMetadata []byte, version EEIVersion) []byte {
+	result := make([]byte, 2)
+	copy(result, codeMetadata)
+	result[1] &= 1<<eeiVersionMetadataShift - 1
+	result[1] |= byte(version&MaxEEIVersion) << eeiVersionMetadataShift
+	return result
+}
+
 // AsyncCallExecutionMode encodes the execution modes of an AsyncCall
 type AsyncCallExecutionMode uint
 
@@ -66,6 +210,10 @@ const TimeLockKeyPrefix = ProtectedStoragePrefix + "TIMELOCK"
 // AsyncDataPrefix is the storage key prefix used for AsyncContext-related storage.
 const AsyncDataPrefix = ProtectedStoragePrefix + "ASYNC"
 
+// CallbackClosurePrefix is the storage key prefix used for the callback
+// closures of the async calls sent to a different shard.
+const CallbackClosurePrefix = AsyncDataPrefix + "CLOSURE"
+
 // AsyncCallStatus represents the different status an async call can have
 type AsyncCallStatus uint8
 
@@ -79,6 +227,9 @@ const (
 	// AsyncCallRejected is the status of an async call that was executed completely but unsuccessfully
 	AsyncCallRejected
 
+	// AsyncCallExpired is the status of an async call whose response did not arrive before its expiry
+	AsyncCallExpired
+
 	// AddressLen specifies the length of the address
 	AddressLen = 32
 
@@ -99,6 +250,10 @@ const (
 
 	// UpgradeFunctionName specifies if the call is an upgradeContract call
 	UpgradeFunctionName = "upgradeContract"
+
+	// ResolveExpiredAsyncCallsFunctionName specifies the name of the function
+	// handled by the host, which calls back the expired async calls
+	ResolveExpiredAsyncCallsFunctionName = "resolveExpiredAsyncCalls"
 )
 
 // CodeDeployInput contains code deploy state, whether it comes from a ContractCreateInput or a ContractCallInput
@@ -117,15 +272,222 @@ type VMHostParameters struct {
 	BuiltInFuncContainer     vmcommon.BuiltInFunctionContainer
 	ESDTTransferParser       vmcommon.ESDTTransferParser
 	ElrondProtectedKeyPrefix []byte
+	ExecutionObserver        ExecutionObserver
+	EnableCallTree           bool
+	EnableAsyncGasReport     bool
+	ExecutionTimeout         time.Duration
+
+	// ReentrancyProtectedContracts lists the contracts which cannot be called
+	// by ExecuteOnDestContext() or ExecuteOnSameContext() while on the stack
+	ReentrancyProtectedContracts [][]byte
+
+	// MaxCallDepth is how many executions may be nested by
+	// ExecuteOnDestContext() or ExecuteOnSameContext() on top of the one
+	// started by the transaction; 0 leaves the depth unbounded
+	MaxCallDepth int
+
+	// EpochNotifier informs the host about the current epoch; the features
+	// listed in FeatureActivationEpochs are enabled from their epoch onwards,
+	// while the features missing from it are disabled
+	EpochNotifier           vmcommon.EpochNotifier
+	FeatureActivationEpochs map[FeatureFlag]uint32
+
+	// LegacyEEIVersion is the EEI version bound to the contracts which do not
+	// record one in their code metadata; it defaults to CurrentEEIVersion
+	LegacyEEIVersion EEIVersion
+
+	// PanicReportDirectory is where the reports of the panics recovered during
+	// execution are written; if empty, the reports are not written
+	PanicReportDirectory string
+
+	// CompiledCodeCache keeps the compiled contracts in addition to the
+	// BlockchainHook; it may be shared by multiple hosts
+	CompiledCodeCache CompiledCodeCache
+
+	// ContractComplexityLimits bounds the complexity of the contracts
+	// accepted at deployment
+	ContractComplexityLimits ContractComplexityLimits
+
+	// MaxMemoryPagesPerInstance is the maximum size of the memory of each
+	// Wasmer instance, in pages; 0 leaves the memory unbounded. Once
+	// MemoryGrowthMeteringFeature is active, the executions whose memory grows
+	// beyond it fail, and the new contracts whose memory may grow beyond it,
+	// including those declaring no maximum memory size, cannot be deployed
+	MaxMemoryPagesPerInstance uint32
+}
+
+// ContractComplexityLimits bounds the complexity of the contracts accepted at
+// deployment; the limits set to 0 are not enforced. The memories which do not
+// declare a maximum size can grow to 65536 pages, exceeding any MaxMemoryPages.
+type ContractComplexityLimits struct {
+	MaxFunctions          uint32
+	MaxLocalsPerFunction  uint64
+	MaxInitialMemoryPages uint32
+	MaxMemoryPages        uint32
+	MaxTableSize          uint32
+	MaxDataSegmentSize    uint32
+	MaxExportedEndpoints  uint32
+}
+
+// IsEnforced returns true if any of the limits is set
+func (limits ContractComplexityLimits) IsEnforced() bool {
+	return limits != ContractComplexityLimits{}
+}
+
+// CompiledCodeCacheStatistics describes the usage of a CompiledCodeCache
+type CompiledCodeCacheStatistics struct {
+	Hits        uint64
+	DiskHits    uint64
+	Misses      uint64
+	Evictions   uint64
+	Entries     int
+	SizeInBytes uint64
+}
+
+// ExecutionKind encodes the ways in which the host can enter a contract
+type ExecutionKind uint8
+
+const (
+	// ExecutionDirectCall marks a contract call received directly by the host
+	ExecutionDirectCall ExecutionKind = iota
+
+	// ExecutionDeployment marks the direct deployment of a contract
+	ExecutionDeployment
+
+	// ExecutionUpgrade marks the direct upgrade of a contract
+	ExecutionUpgrade
+
+	// ExecutionDestContext marks an indirect call executed with ExecuteOnDestContext()
+	ExecutionDestContext
+
+	// ExecutionSameContext marks an indirect call executed with ExecuteOnSameContext()
+	ExecutionSameContext
+
+	// ExecutionAsyncCall marks the destination call of an async call
+	ExecutionAsyncCall
+
+	// ExecutionAsyncCallback marks the callback of an async call
+	ExecutionAsyncCallback
+
+	// ExecutionBuiltinFunction marks the call of a protocol built-in function
+	ExecutionBuiltinFunction
+)
+
+// String returns the human-readable name of the ExecutionKind
+func (kind ExecutionKind) String() string {
+	switch kind {
+	case ExecutionDirectCall:
+		return "DirectCall"
+	case ExecutionDeployment:
+		return "Deployment"
+	case ExecutionUpgrade:
+		return "Upgrade"
+	case ExecutionDestContext:
+		return "DestContext"
+	case ExecutionSameContext:
+		return "SameContext"
+	case ExecutionAsyncCall:
+		return "AsyncCall"
+	case ExecutionAsyncCallback:
+		return "AsyncCallback"
+	case ExecutionBuiltinFunction:
+		return "BuiltinFunction"
+	}
+
+	return "Unknown"
+}
+
+// ExecutionEvent describes the entry into or the exit from a contract, as
+// seen by the host. The fields describing the outcome of the execution
+// (GasRemaining, GasUsed, ReturnCode, ReturnMessage, ReturnData and Err) are
+// only set on exit.
+type ExecutionEvent struct {
+	Kind          ExecutionKind
+	Depth         int
+	CallerAddr    []byte
+	ContractAddr  []byte
+	Function      string
+	Arguments     [][]byte
+	CallType      vm.CallType
+	CallValue     *big.Int
+	GasProvided   uint64
+	GasLocked     uint64
+	GasRemaining  uint64
+	GasUsed       uint64
+	ReturnCode    vmcommon.ReturnCode
+	ReturnMessage string
+	ReturnData    [][]byte
+	Err           error
+}
+
+// CallTreeNode describes a single contract execution and the executions it
+// has caused in turn, in the order in which they were entered. The Logs and
+// StorageUpdates of a node only contain the entries produced by the node
+// itself, not by its children; StorageUpdates are mapped by address and key.
+// Failed executions have their effects reverted, therefore they report
+// neither logs nor storage updates, and neither do their children.
+type CallTreeNode struct {
+	Kind           ExecutionKind
+	CallerAddr     []byte
+	ContractAddr   []byte
+	Function       string
+	GasProvided    uint64
+	GasUsed        uint64
+	ReturnCode     vmcommon.ReturnCode
+	ReturnMessage  string
+	ReturnData     [][]byte
+	Logs           []*vmcommon.LogEntry
+	StorageUpdates map[string]map[string]*vmcommon.StorageUpdate
+	Children       []*CallTreeNode
+}
+
+// GasEstimate holds the result of a gas estimation. GasLimit is the minimal
+// gas limit with which the execution succeeds; it includes the gas locked for
+// the callbacks of the async calls, but it cannot include the gas required by
+// the destinations of the cross-shard async calls, which execute elsewhere.
+type GasEstimate struct {
+	GasLimit              uint64
+	GasUsed               uint64
+	GasLockedForCallbacks uint64
+	HasCrossShardCalls    bool
+}
+
+// AsyncCallGasEntry describes how the gas of a single async call was spent.
+// The gas provided to the destination and the gas locked for the callback are
+// always accounted for as GasProvided + GasLocked = GasUsedByDestination +
+// GasUsedByCallback + GasRefunded. For a cross-shard async call, the entry in
+// the report of the sending transaction only holds the provided and locked gas,
+// while the entry in the report of the transaction which receives its callback
+// has ReceivedCallback set and holds the gas used by the callback.
+type AsyncCallGasEntry struct {
+	ContextIdentifier     string
+	Destination           []byte
+	Data                  []byte
+	CrossShard            bool
+	ReceivedCallback      bool
+	GasProvided           uint64
+	GasLocked             uint64
+	GasUsedByDestination  uint64
+	GasUsedByCallback     uint64
+	GasRefunded           uint64
+	DestinationReturnCode vmcommon.ReturnCode
+}
+
+// AsyncGasReport lists the gas entries of the async calls of an execution, in
+// the order in which they were processed; the legacy async call has an empty
+// ContextIdentifier
+type AsyncGasReport struct {
+	Entries []*AsyncCallGasEntry
 }
 
 // AsyncCallInfo contains the information required to handle the asynchronous call of another SmartContract
 type AsyncCallInfo struct {
-	Destination []byte
-	Data        []byte
-	GasLimit    uint64
-	GasLocked   uint64
-	ValueBytes  []byte
+	Destination     []byte
+	Data            []byte
+	GasLimit        uint64
+	GasLocked       uint64
+	ValueBytes      []byte
+	CallbackClosure []byte
 }
 
 // GetDestination returns the destination of an async call
@@ -153,6 +515,11 @@ func (aci *AsyncCallInfo) GetValueBytes() []byte {
 	return aci.ValueBytes
 }
 
+// GetCallbackClosure returns the data which the callback of the async call receives back
+func (aci *AsyncCallInfo) GetCallbackClosure() []byte {
+	return aci.CallbackClosure
+}
+
 // AsyncGeneratedCall holds the information abount an async call
 type AsyncGeneratedCall struct {
 	Status          AsyncCallStatus
@@ -163,6 +530,11 @@ type AsyncGeneratedCall struct {
 	SuccessCallback string
 	ErrorCallback   string
 	ProvidedGas     uint64
+	ExpiryNonce     uint64
+	ExpiryTimestamp uint64
+	CallbackClosure []byte
+	Sequence        uint64
+	GasLocked       uint64
 }
 
 // AsyncContext is a structure containing a group of async calls and a callback
@@ -176,6 +548,7 @@ type AsyncContext struct {
 // one or more async calls. It will
 type AsyncContextInfo struct {
 	CallerAddr      []byte
+	CallType        vm.CallType
 	ReturnData      []byte
 	AsyncContextMap map[string]*AsyncContext
 }
@@ -197,7 +570,7 @@ func (ac *AsyncGeneratedCall) GetGasLimit() uint64 {
 
 // GetGasLocked returns the gas locked for the async callback
 func (ac *AsyncGeneratedCall) GetGasLocked() uint64 {
-	return 0
+	return ac.GasLocked
 }
 
 // GetValueBytes returns the byte representation of the value of the async call
@@ -205,6 +578,21 @@ func (ac *AsyncGeneratedCall) GetValueBytes() []byte {
 	return ac.ValueBytes
 }
 
+// GetCallbackClosure returns the data which the callback of the async call receives back
+func (ac *AsyncGeneratedCall) GetCallbackClosure() []byte {
+	return ac.CallbackClosure
+}
+
+// IsExpired returns true if the async call has an expiry, as a block nonce or
+// a timestamp, which has been reached by the given block
+func (ac *AsyncGeneratedCall) IsExpired(nonce uint64, timestamp uint64) bool {
+	if ac.ExpiryNonce > 0 && nonce >= ac.ExpiryNonce {
+		return true
+	}
+
+	return ac.ExpiryTimestamp > 0 && timestamp >= ac.ExpiryTimestamp
+}
+
 // IsInterfaceNil returns true if there is no value under the interface
 func (ac *AsyncGeneratedCall) IsInterfaceNil() bool {
 	return ac == nil
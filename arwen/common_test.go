@@ -0,0 +1,41 @@
+package arwen
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeMetadataWithEEIVersion(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, EEIVersionUnspecified, EEIVersionFromCodeMetadata(nil))
+	require.Equal(t, EEIVersionUnspecified, EEIVersionFromCodeMetadata([]byte{1}))
+	require.Equal(t, EEIVersionUnspecified, EEIVersionFromCodeMetadata([]byte{1, vmcommon.MetadataPayable}))
+
+	original := []byte{vmcommon.MetadataUpgradeable, vmcommon.MetadataPayable}
+	codeMetadata := CodeMetadataWithEEIVersion(original, EEIVersion1_3)
+	require.Equal(t, []byte{vmcommon.MetadataUpgradeable, vmcommon.MetadataPayable}, original)
+	require.Equal(t, EEIVersion1_3, EEIVersionFromCodeMetadata(codeMetadata))
+
+	codeMetadata = CodeMetadataWithEEIVersion(codeMetadata, EEIVersion1_4)
+	require.Equal(t, EEIVersion1_4, EEIVersionFromCodeMetadata(codeMetadata))
+
+	flags := vmcommon.CodeMetadataFromBytes(codeMetadata)
+	require.True(t, flags.Upgradeable)
+	require.True(t, flags.Payable)
+	require.False(t, flags.Readable)
+
+	codeMetadata = CodeMetadataWithEEIVersion(nil, CurrentEEIVersion)
+	require.Len(t, codeMetadata, 2)
+	require.Equal(t, CurrentEEIVersion, EEIVersionFromCodeMetadata(codeMetadata))
+}
+
+func TestBreakpointValues_MatchExecutor(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, uint64(BreakpointNone), uint64(executor.BreakpointNone))
+	require.Equal(t, uint64(BreakpointOutOfGas), uint64(executor.BreakpointOutOfGas))
+}
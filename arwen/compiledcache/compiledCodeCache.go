@@ -0,0 +1,291 @@
+package compiledcache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	logger "github.com/ElrondNetwork/elrond-go-logger"
+)
+
+var log = logger.GetOrCreate("arwen/compiledcache")
+
+var _ arwen.CompiledCodeCache = (*compiledCodeCache)(nil)
+
+// versionHashLength is the number of bytes of the opcode costs hash which
+// are kept in a version
+const versionHashLength = 8
+
+// CacheConfig holds the limits of a compiled code cache
+type CacheConfig struct {
+	// MaxEntries is the largest number of compiled contracts kept in memory;
+	// zero means no limit
+	MaxEntries int
+
+	// MaxSizeInBytes is the largest total size of the compiled contracts kept
+	// in memory; zero means no limit
+	MaxSizeInBytes uint64
+
+	// Directory is where the compiled contracts are persisted; if empty, the
+	// cache is kept in memory only
+	Directory string
+}
+
+type cacheEntry struct {
+	key          string
+	compiledCode []byte
+}
+
+// compiledCodeCache keeps the compiled code of contracts in memory, evicting
+// the least recently used entries beyond its limits, and optionally persists
+// it on disk, where it is not limited. It is safe for concurrent use, so that
+// it can be shared by multiple hosts.
+type compiledCodeCache struct {
+	mutex       sync.Mutex
+	config      CacheConfig
+	entries     map[string]*list.Element
+	recency     *list.List
+	sizeInBytes uint64
+	statistics  arwen.CompiledCodeCacheStatistics
+}
+
+// NewCompiledCodeCache creates a new compiled code cache, creating its
+// directory if the cache is persisted on disk
+func NewCompiledCodeCache(config CacheConfig) (*compiledCodeCache, error) {
+	if config.MaxEntries < 0 {
+		return nil, arwen.ErrInvalidCompiledCodeCacheConfig
+	}
+
+	if len(config.Directory) > 0 {
+		err := os.MkdirAll(config.Directory, os.ModePerm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cache := &compiledCodeCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		recency: list.New(),
+	}
+
+	return cache, nil
+}
+
+// ComputeVersion returns the version of the compiled code produced by the
+// given version of the Wasmer library under the given opcode costs, which are
+// injected into the code by the compilation
+func ComputeVersion(opcodeCosts *config.WASMOpcodeCost, wasmerVersion string) string {
+	costsArray := opcodeCosts.ToOpcodeCostsArray()
+
+	hasher := sha256.New()
+	_ = binary.Write(hasher, binary.LittleEndian, costsArray)
+	_ = binary.Write(hasher, binary.LittleEndian, opcodeCosts.LocalsUnmetered)
+	hash := hasher.Sum(nil)
+
+	return fmt.Sprintf("%s-wasmer%s-%s", arwen.ArwenVersion, wasmerVersion, hex.EncodeToString(hash[:versionHashLength]))
+}
+
+// Get returns the compiled code of the given version, looking for it on disk
+// if it is not kept in memory
+func (cache *compiledCodeCache) Get(codeHash []byte, version string) ([]byte, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	key := cacheKey(codeHash, version)
+	element, found := cache.entries[key]
+	if found {
+		cache.recency.MoveToFront(element)
+		cache.statistics.Hits++
+		return element.Value.(*cacheEntry).compiledCode, true
+	}
+
+	compiledCode, found := cache.readFromDisk(codeHash, version)
+	if !found {
+		cache.statistics.Misses++
+		return nil, false
+	}
+
+	cache.statistics.Hits++
+	cache.statistics.DiskHits++
+	cache.add(key, compiledCode)
+
+	return compiledCode, true
+}
+
+// Put adds the compiled code of the given version to the cache, persisting
+// it on disk if the cache has a directory
+func (cache *compiledCodeCache) Put(codeHash []byte, version string, compiledCode []byte) {
+	if len(compiledCode) == 0 {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	key := cacheKey(codeHash, version)
+	element, found := cache.entries[key]
+	if found {
+		cache.remove(element)
+	}
+
+	cache.add(key, compiledCode)
+	cache.writeToDisk(codeHash, version, compiledCode)
+}
+
+// Statistics returns the hits, misses and evictions of the cache so far,
+// together with its current size
+func (cache *compiledCodeCache) Statistics() arwen.CompiledCodeCacheStatistics {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	statistics := cache.statistics
+	statistics.Entries = cache.recency.Len()
+	statistics.SizeInBytes = cache.sizeInBytes
+
+	return statistics
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (cache *compiledCodeCache) IsInterfaceNil() bool {
+	return cache == nil
+}
+
+// add keeps the compiled code in memory as the most recently used entry,
+// then evicts the least recently used entries beyond the limits; a compiled
+// code larger than the size limit is not kept in memory at all
+func (cache *compiledCodeCache) add(key string, compiledCode []byte) {
+	size := uint64(len(compiledCode))
+	if cache.config.MaxSizeInBytes > 0 && size > cache.config.MaxSizeInBytes {
+		return
+	}
+
+	element := cache.recency.PushFront(&cacheEntry{
+		key:          key,
+		compiledCode: compiledCode,
+	})
+	cache.entries[key] = element
+	cache.sizeInBytes += size
+
+	for cache.isOverLimits() {
+		cache.remove(cache.recency.Back())
+		cache.statistics.Evictions++
+	}
+}
+
+func (cache *compiledCodeCache) isOverLimits() bool {
+	if cache.config.MaxEntries > 0 && cache.recency.Len() > cache.config.MaxEntries {
+		return true
+	}
+
+	return cache.config.MaxSizeInBytes > 0 && cache.sizeInBytes > cache.config.MaxSizeInBytes
+}
+
+func (cache *compiledCodeCache) remove(element *list.Element) {
+	entry := cache.recency.Remove(element).(*cacheEntry)
+	delete(cache.entries, entry.key)
+	cache.sizeInBytes -= uint64(len(entry.compiledCode))
+}
+
+func (cache *compiledCodeCache) readFromDisk(codeHash []byte, version string) ([]byte, bool) {
+	filePath, ok := cache.filePath(codeHash, version)
+	if !ok {
+		return nil, false
+	}
+
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil || len(contents) <= sha256.Size {
+		return nil, false
+	}
+
+	checksum := contents[:sha256.Size]
+	compiledCode := contents[sha256.Size:]
+	actualChecksum := sha256.Sum256(compiledCode)
+	if !bytes.Equal(checksum, actualChecksum[:]) {
+		log.Warn("compiled code cache: corrupted compiled code removed", "file", filePath)
+		_ = os.Remove(filePath)
+		return nil, false
+	}
+
+	return compiledCode, true
+}
+
+// writeToDisk persists the compiled code through a temporary file, so that
+// an interrupted write never leaves a truncated compiled code behind; the
+// file starts with the checksum of the compiled code, verified when it is
+// read back
+func (cache *compiledCodeCache) writeToDisk(codeHash []byte, version string, compiledCode []byte) {
+	filePath, ok := cache.filePath(codeHash, version)
+	if !ok {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+	if err != nil {
+		log.Error("compiled code cache: create version directory", "error", err)
+		return
+	}
+
+	temporaryFile, err := ioutil.TempFile(filepath.Dir(filePath), "compiling-")
+	if err != nil {
+		log.Error("compiled code cache: create temporary file", "error", err)
+		return
+	}
+
+	checksum := sha256.Sum256(compiledCode)
+	_, err = temporaryFile.Write(checksum[:])
+	if err == nil {
+		_, err = temporaryFile.Write(compiledCode)
+	}
+	closeErr := temporaryFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temporaryFile.Name(), filePath)
+	}
+	if err != nil {
+		_ = os.Remove(temporaryFile.Name())
+		log.Error("compiled code cache: write compiled code", "error", err)
+	}
+}
+
+// filePath returns the file of the compiled code on disk, grouped by version;
+// the versions which cannot be used as directory names are not persisted
+func (cache *compiledCodeCache) filePath(codeHash []byte, version string) (string, bool) {
+	if len(cache.config.Directory) == 0 || !isValidVersion(version) {
+		return "", false
+	}
+
+	return filepath.Join(cache.config.Directory, version, hex.EncodeToString(codeHash)), true
+}
+
+func isValidVersion(version string) bool {
+	if len(version) == 0 || version[0] == '.' {
+		return false
+	}
+
+	for _, character := range version {
+		isAlphanumeric := (character >= 'a' && character <= 'z') ||
+			(character >= 'A' && character <= 'Z') ||
+			(character >= '0' && character <= '9')
+		if !isAlphanumeric && character != '.' && character != '-' && character != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cacheKey(codeHash []byte, version string) string {
+	return version + "/" + string(codeHash)
+}
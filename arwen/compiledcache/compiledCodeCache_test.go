@@ -0,0 +1,154 @@
+package compiledcache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testVersion = "v1.4-test"
+
+func TestNewCompiledCodeCache_InvalidConfig(t *testing.T) {
+	cache, err := NewCompiledCodeCache(CacheConfig{MaxEntries: -1})
+	require.Equal(t, arwen.ErrInvalidCompiledCodeCacheConfig, err)
+	require.True(t, cache.IsInterfaceNil())
+}
+
+func TestCompiledCodeCache_HitsAndMisses(t *testing.T) {
+	cache, err := NewCompiledCodeCache(CacheConfig{})
+	require.Nil(t, err)
+
+	_, found := cache.Get([]byte("alpha"), testVersion)
+	require.False(t, found)
+
+	cache.Put([]byte("alpha"), testVersion, []byte("compiled alpha"))
+	compiledCode, found := cache.Get([]byte("alpha"), testVersion)
+	require.True(t, found)
+	require.Equal(t, []byte("compiled alpha"), compiledCode)
+
+	// the compiled code of another version is a different entry
+	_, found = cache.Get([]byte("alpha"), "v1.4-other")
+	require.False(t, found)
+
+	require.Equal(t, arwen.CompiledCodeCacheStatistics{
+		Hits:        1,
+		Misses:      2,
+		Entries:     1,
+		SizeInBytes: uint64(len("compiled alpha")),
+	}, cache.Statistics())
+}
+
+func TestCompiledCodeCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache, err := NewCompiledCodeCache(CacheConfig{MaxEntries: 2})
+	require.Nil(t, err)
+
+	cache.Put([]byte("alpha"), testVersion, []byte("a"))
+	cache.Put([]byte("beta"), testVersion, []byte("b"))
+	_, found := cache.Get([]byte("alpha"), testVersion)
+	require.True(t, found)
+
+	cache.Put([]byte("gamma"), testVersion, []byte("c"))
+	_, found = cache.Get([]byte("beta"), testVersion)
+	require.False(t, found)
+	_, found = cache.Get([]byte("alpha"), testVersion)
+	require.True(t, found)
+
+	statistics := cache.Statistics()
+	require.Equal(t, uint64(1), statistics.Evictions)
+	require.Equal(t, 2, statistics.Entries)
+}
+
+func TestCompiledCodeCache_SizeLimit(t *testing.T) {
+	cache, err := NewCompiledCodeCache(CacheConfig{MaxSizeInBytes: 10})
+	require.Nil(t, err)
+
+	cache.Put([]byte("alpha"), testVersion, []byte("123456"))
+	cache.Put([]byte("beta"), testVersion, []byte("123456"))
+	_, found := cache.Get([]byte("alpha"), testVersion)
+	require.False(t, found)
+
+	// a compiled code larger than the limit is not kept at all
+	cache.Put([]byte("gamma"), testVersion, []byte("12345678901"))
+	_, found = cache.Get([]byte("gamma"), testVersion)
+	require.False(t, found)
+
+	statistics := cache.Statistics()
+	require.Equal(t, 1, statistics.Entries)
+	require.Equal(t, uint64(6), statistics.SizeInBytes)
+}
+
+func TestCompiledCodeCache_PersistsOnDisk(t *testing.T) {
+	directory, err := ioutil.TempDir("", "arwen-compiled-code")
+	require.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(directory)
+	}()
+
+	cache, err := NewCompiledCodeCache(CacheConfig{Directory: directory})
+	require.Nil(t, err)
+	cache.Put([]byte("alpha"), testVersion, []byte("compiled alpha"))
+	cache.Put([]byte("beta"), "../escape", []byte("compiled beta"))
+
+	reopenedCache, err := NewCompiledCodeCache(CacheConfig{Directory: directory})
+	require.Nil(t, err)
+
+	compiledCode, found := reopenedCache.Get([]byte("alpha"), testVersion)
+	require.True(t, found)
+	require.Equal(t, []byte("compiled alpha"), compiledCode)
+
+	// the versions which are not valid directory names are kept in memory only
+	_, found = reopenedCache.Get([]byte("beta"), "../escape")
+	require.False(t, found)
+
+	statistics := reopenedCache.Statistics()
+	require.Equal(t, uint64(1), statistics.DiskHits)
+	require.Equal(t, 1, statistics.Entries)
+}
+
+func TestCompiledCodeCache_VerifiesChecksumOnDisk(t *testing.T) {
+	directory, err := ioutil.TempDir("", "arwen-compiled-code")
+	require.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(directory)
+	}()
+
+	cache, err := NewCompiledCodeCache(CacheConfig{Directory: directory})
+	require.Nil(t, err)
+	cache.Put([]byte("alpha"), testVersion, []byte("compiled alpha"))
+
+	filePath, ok := cache.filePath([]byte("alpha"), testVersion)
+	require.True(t, ok)
+	contents, err := ioutil.ReadFile(filePath)
+	require.Nil(t, err)
+	contents[len(contents)-1] ^= 0xff
+	err = ioutil.WriteFile(filePath, contents, 0644)
+	require.Nil(t, err)
+
+	// the corrupted compiled code is a miss, and it is removed from disk
+	reopenedCache, err := NewCompiledCodeCache(CacheConfig{Directory: directory})
+	require.Nil(t, err)
+	_, found := reopenedCache.Get([]byte("alpha"), testVersion)
+	require.False(t, found)
+
+	_, err = os.Stat(filePath)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestComputeVersion(t *testing.T) {
+	gasCost, err := config.CreateGasConfig(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	version := ComputeVersion(&gasCost.WASMOpcodeCost, "1")
+	require.Equal(t, version, ComputeVersion(&gasCost.WASMOpcodeCost, "1"))
+	require.True(t, isValidVersion(version))
+
+	// the code compiled by another Wasmer library is a different version
+	require.NotEqual(t, version, ComputeVersion(&gasCost.WASMOpcodeCost, "2"))
+
+	gasCost.WASMOpcodeCost.I32Add++
+	require.NotEqual(t, version, ComputeVersion(&gasCost.WASMOpcodeCost, "1"))
+}
@@ -4,6 +4,10 @@ import (
 	"math/big"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/compiledcache"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
+	"github.com/ElrondNetwork/elrond-go-core/core/check"
 	"github.com/ElrondNetwork/elrond-go-core/data/esdt"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
 	"github.com/ElrondNetwork/elrond-vm-common"
@@ -12,9 +16,11 @@ import (
 var log = logger.GetOrCreate("arwen/blockchainContext")
 
 type blockchainContext struct {
-	host           arwen.VMHost
-	blockChainHook vmcommon.BlockchainHook
-	stateStack     []int
+	host                arwen.VMHost
+	blockChainHook      vmcommon.BlockchainHook
+	compiledCodeCache   arwen.CompiledCodeCache
+	compiledCodeVersion string
+	stateStack          []int
 }
 
 // NewBlockchainContext creates a new blockchainContext
@@ -274,14 +280,48 @@ func (context *blockchainContext) IsPayable(addr []byte) (bool, error) {
 	return context.blockChainHook.IsPayable(addr)
 }
 
+// SetCompiledCodeCache sets the cache which keeps the compiled code in
+// addition to the BlockchainHook; a nil cache disables it.
+func (context *blockchainContext) SetCompiledCodeCache(cache arwen.CompiledCodeCache) {
+	context.compiledCodeCache = cache
+	if check.IfNil(cache) {
+		context.compiledCodeCache = nil
+	}
+}
+
 // SaveCompiledCode saves the compiled code to cache and storage.
 func (context *blockchainContext) SaveCompiledCode(codeHash []byte, code []byte) {
+	if context.compiledCodeCache != nil {
+		context.compiledCodeCache.Put(codeHash, context.compiledCodeVersion, code)
+	}
+
 	context.blockChainHook.SaveCompiledCode(codeHash, code)
 }
 
 // GetCompiledCode returns the compiled code if it finds in the cache or storage
 func (context *blockchainContext) GetCompiledCode(codeHash []byte) (bool, []byte) {
-	return context.blockChainHook.GetCompiledCode(codeHash)
+	if context.compiledCodeCache == nil {
+		return context.blockChainHook.GetCompiledCode(codeHash)
+	}
+
+	version := context.compiledCodeVersion
+	compiledCode, found := context.compiledCodeCache.Get(codeHash, version)
+	if found {
+		return true, compiledCode
+	}
+
+	found, compiledCode = context.blockChainHook.GetCompiledCode(codeHash)
+	if found {
+		context.compiledCodeCache.Put(codeHash, version, compiledCode)
+	}
+
+	return found, compiledCode
+}
+
+// UpdateCompiledCodeVersion sets the version under which the compiled code is
+// cached, after the opcode costs injected by the compilation have changed
+func (context *blockchainContext) UpdateCompiledCodeVersion(opcodeCosts *config.WASMOpcodeCost) {
+	context.compiledCodeVersion = compiledcache.ComputeVersion(opcodeCosts, wasmer.LibraryVersion)
 }
 
 // GetUserAccount returns a user account
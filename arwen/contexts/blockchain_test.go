@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/compiledcache"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
 	contextmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
 	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
 	"github.com/ElrondNetwork/elrond-vm-common"
@@ -440,3 +442,45 @@ func TestBlockchainContext_Getters(t *testing.T) {
 	require.Equal(t, randomSeed1[:], blockchainContext.LastRandomSeed())
 	require.Equal(t, randomSeed2[:], blockchainContext.CurrentRandomSeed())
 }
+
+func TestBlockchainContext_CompiledCodeCache(t *testing.T) {
+	t.Parallel()
+
+	gasCost, err := config.CreateGasConfig(config.MakeGasMapForTests())
+	require.Nil(t, err)
+	host := &contextmock.VMHostMock{
+		MeteringContext: &contextmock.MeteringContextMock{GasCost: gasCost},
+	}
+	mockWorld := worldmock.NewMockWorld()
+	mockWorld.SaveCompiledCode([]byte("hook code hash"), []byte("hook compiled code"))
+
+	cache, err := compiledcache.NewCompiledCodeCache(compiledcache.CacheConfig{})
+	require.Nil(t, err)
+
+	blockchainContext, _ := NewBlockchainContext(host, mockWorld)
+	blockchainContext.SetCompiledCodeCache(cache)
+	blockchainContext.UpdateCompiledCodeVersion(&gasCost.WASMOpcodeCost)
+
+	// the compiled code found by the BlockchainHook warms up the cache
+	found, compiledCode := blockchainContext.GetCompiledCode([]byte("hook code hash"))
+	require.True(t, found)
+	require.Equal(t, []byte("hook compiled code"), compiledCode)
+	mockWorld.ClearCompiledCodes()
+	found, _ = blockchainContext.GetCompiledCode([]byte("hook code hash"))
+	require.True(t, found)
+
+	blockchainContext.SaveCompiledCode([]byte("code hash"), []byte("compiled code"))
+	found, compiledCode = mockWorld.GetCompiledCode([]byte("code hash"))
+	require.True(t, found)
+	require.Equal(t, []byte("compiled code"), compiledCode)
+
+	// the cache is bound to the opcode costs under which the code was compiled
+	gasCost.WASMOpcodeCost.I32Add++
+	blockchainContext.UpdateCompiledCodeVersion(&gasCost.WASMOpcodeCost)
+	found, _ = blockchainContext.GetCompiledCode([]byte("hook code hash"))
+	require.False(t, found)
+
+	statistics := cache.Statistics()
+	require.Equal(t, uint64(1), statistics.Hits)
+	require.Equal(t, uint64(2), statistics.Misses)
+}
@@ -1,6 +1,7 @@
 package contexts
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"math/big"
@@ -201,9 +202,41 @@ func (context *outputContext) ClearReturnData() {
 	context.outputState.ReturnData = make([][]byte, 0)
 }
 
-// SelfDestruct does nothing
-// TODO change comment when the function is implemented
-func (context *outputContext) SelfDestruct(_ []byte, _ []byte) {
+// GetLogs returns the log entries of the current output state.
+func (context *outputContext) GetLogs() []*vmcommon.LogEntry {
+	return context.outputState.Logs
+}
+
+// SelfDestruct transfers the entire balance of the given account to the
+// beneficiary and marks the account for deletion, in the DeletedAccounts of
+// the current output state. A contract which is still executing further down
+// the stack cannot be destroyed.
+func (context *outputContext) SelfDestruct(address []byte, beneficiary []byte) error {
+	if context.host.Runtime().ReadOnly() {
+		logOutput.Trace("self destruct", "error", arwen.ErrInvalidCallOnReadOnlyMode)
+		return arwen.ErrInvalidCallOnReadOnlyMode
+	}
+	if bytes.Equal(address, beneficiary) {
+		logOutput.Trace("self destruct", "error", arwen.ErrSelfDestructBeneficiaryIsSelf)
+		return arwen.ErrSelfDestructBeneficiaryIsSelf
+	}
+	if context.host.Runtime().IsContractOnTheStack(address) {
+		logOutput.Trace("self destruct", "error", arwen.ErrSelfDestructContractOnStack)
+		return arwen.ErrSelfDestructContractOnStack
+	}
+
+	balance := context.host.Blockchain().GetBalanceBigInt(address)
+	err := context.TransferValueOnly(beneficiary, address, balance, false)
+	if err != nil {
+		return err
+	}
+
+	if !isAddressInList(context.outputState.DeletedAccounts, address) {
+		context.outputState.DeletedAccounts = append(context.outputState.DeletedAccounts, address)
+	}
+
+	logOutput.Trace("self destruct", "address", address, "beneficiary", beneficiary, "balance", balance)
+	return nil
 }
 
 // Finish appends the given data to the return data of the current output state.
@@ -444,6 +477,9 @@ func (context *outputContext) DeployCode(input arwen.CodeDeployInput) {
 	newSCAccount, _ := context.GetOutputAccount(input.ContractAddress)
 	newSCAccount.Code = input.ContractCode
 	newSCAccount.CodeMetadata = input.ContractCodeMetadata
+	if context.host.IsFeatureEnabled(arwen.EEIVersionMetadataFeature) {
+		newSCAccount.CodeMetadata = arwen.CodeMetadataWithEEIVersion(input.ContractCodeMetadata, arwen.CurrentEEIVersion)
+	}
 	newSCAccount.CodeDeployerAddress = input.CodeDeployerAddress
 
 	var empty struct{}
@@ -524,6 +560,15 @@ func (context *outputContext) resolveReturnCodeFromError(err error) vmcommon.Ret
 	if errors.Is(err, arwen.ErrTransferInsufficientFunds) {
 		return vmcommon.OutOfFunds
 	}
+	if errors.Is(err, arwen.ErrExecutionTimeout) {
+		return arwen.ExecutionTimeout
+	}
+	if errors.Is(err, arwen.ErrReentrancyNotAllowed) {
+		return arwen.ReentrancyNotAllowed
+	}
+	if errors.Is(err, arwen.ErrCallDepthExceeded) {
+		return arwen.CallDepthExceeded
+	}
 
 	return vmcommon.ExecutionFailed
 }
@@ -565,6 +610,12 @@ func mergeVMOutputs(leftOutput *vmcommon.VMOutput, rightOutput *vmcommon.VMOutpu
 		mergeOutputAccounts(leftAccount, rightAccount)
 	}
 
+	for _, deletedAddress := range rightOutput.DeletedAccounts {
+		if !isAddressInList(leftOutput.DeletedAccounts, deletedAddress) {
+			leftOutput.DeletedAccounts = append(leftOutput.DeletedAccounts, deletedAddress)
+		}
+	}
+
 	leftOutput.Logs = append(leftOutput.Logs, rightOutput.Logs...)
 	leftOutput.ReturnData = append(leftOutput.ReturnData, rightOutput.ReturnData...)
 	leftOutput.GasRemaining = rightOutput.GasRemaining
@@ -630,3 +681,13 @@ func mergeStorageUpdates(
 		leftAccount.StorageUpdates[key] = update
 	}
 }
+
+func isAddressInList(addresses [][]byte, address []byte) bool {
+	for _, listedAddress := range addresses {
+		if bytes.Equal(listedAddress, address) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -9,8 +9,10 @@ import (
 	"unsafe"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminspector"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/math"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
+	"github.com/ElrondNetwork/elrond-go-core/core/check"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
 	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
 )
@@ -30,12 +32,14 @@ type runtimeContext struct {
 	readOnly           bool
 	verifyCode         bool
 	maxWasmerInstances uint64
+	maxMemoryPages     uint32
 
 	stateStack    []*runtimeContext
 	instanceStack []wasmer.InstanceHandler
 
 	asyncCallInfo    *arwen.AsyncCallInfo
 	asyncContextInfo *arwen.AsyncContextInfo
+	callbackClosure  []byte
 
 	validator       *wasmValidator
 	instanceBuilder arwen.InstanceBuilder
@@ -76,6 +80,7 @@ func (context *runtimeContext) InitState() {
 	context.asyncContextInfo = &arwen.AsyncContextInfo{
 		AsyncContextMap: make(map[string]*arwen.AsyncContext),
 	}
+	context.callbackClosure = nil
 	context.errors = nil
 
 	logRuntime.Trace("init state")
@@ -97,8 +102,24 @@ func (context *runtimeContext) StartWasmerInstance(contract []byte, gasLimit uin
 		return arwen.ErrMaxInstancesReached
 	}
 
+	eeiVersion := context.eeiVersionOfContract(newCode)
+	unlockEEIVersion, err := context.host.LockEEIVersion(eeiVersion)
+	if err != nil {
+		context.instance = nil
+		logRuntime.Error("create instance", "error", err)
+		return err
+	}
+	defer unlockEEIVersion()
+
 	blockchain := context.host.Blockchain()
 	codeHash := blockchain.GetCodeHash(context.GetSCAddress())
+	err = context.verifyMemoryLimit(contract, newCode)
+	if err != nil {
+		context.instance = nil
+		logRuntime.Trace("create instance", "error", err)
+		return err
+	}
+
 	compiledCodeUsed := context.makeInstanceFromCompiledCode(codeHash, gasLimit, newCode)
 	if compiledCodeUsed {
 		return nil
@@ -107,6 +128,25 @@ func (context *runtimeContext) StartWasmerInstance(contract []byte, gasLimit uin
 	return context.makeInstanceFromContractByteCode(contract, codeHash, gasLimit, newCode)
 }
 
+// eeiVersionOfContract returns the EEI version to which the current contract
+// is bound: new code is bound to the version which DeployCode records for it,
+// while deployed code is bound to the version recorded in its code metadata
+func (context *runtimeContext) eeiVersionOfContract(newCode bool) arwen.EEIVersion {
+	if newCode {
+		if !context.host.IsFeatureEnabled(arwen.EEIVersionMetadataFeature) {
+			return arwen.EEIVersionUnspecified
+		}
+		return arwen.CurrentEEIVersion
+	}
+
+	account, err := context.host.Blockchain().GetUserAccount(context.GetSCAddress())
+	if err != nil || check.IfNil(account) {
+		return arwen.EEIVersionUnspecified
+	}
+
+	return arwen.EEIVersionFromCodeMetadata(account.GetCodeMetadata())
+}
+
 func (context *runtimeContext) makeInstanceFromCompiledCode(codeHash []byte, gasLimit uint64, newCode bool) bool {
 	if newCode || len(codeHash) == 0 {
 		return false
@@ -144,6 +184,15 @@ func (context *runtimeContext) makeInstanceFromCompiledCode(codeHash []byte, gas
 }
 
 func (context *runtimeContext) makeInstanceFromContractByteCode(contract []byte, codeHash []byte, gasLimit uint64, newCode bool) error {
+	if newCode && context.verifyCode {
+		err := context.validator.verifyBytecode(contract, context.host)
+		if err != nil {
+			context.instance = nil
+			logRuntime.Trace("instance creation", "code", "bytecode", "error", err)
+			return err
+		}
+	}
+
 	gasSchedule := context.host.Metering().GasSchedule()
 	options := wasmer.CompilationOptions{
 		GasLimit:           gasLimit,
@@ -227,6 +276,81 @@ func (context *runtimeContext) SetMaxInstanceCount(maxInstances uint64) {
 	context.maxWasmerInstances = maxInstances
 }
 
+// SetContractComplexityLimits sets the limits of the complexity of the
+// contracts accepted at deployment.
+func (context *runtimeContext) SetContractComplexityLimits(limits arwen.ContractComplexityLimits) {
+	context.validator.complexityLimits = limits
+}
+
+// SetMaxMemoryPages sets the maximum size of the memory of each instance, in
+// pages; 0 leaves the memory unbounded.
+func (context *runtimeContext) SetMaxMemoryPages(pages uint32) {
+	context.maxMemoryPages = pages
+}
+
+// verifyMemoryLimit refuses to deploy the code whose memory may grow beyond
+// the maximum number of pages per instance, that is the code declaring a
+// larger maximum memory size, or none. The contracts already deployed are
+// instantiated regardless of their declared maximum, and their actual memory
+// is checked by ChargeMemoryGrowth instead.
+func (context *runtimeContext) verifyMemoryLimit(contract []byte, newCode bool) error {
+	if !newCode || context.maxMemoryPages == 0 || !context.host.IsFeatureEnabled(arwen.MemoryGrowthMeteringFeature) {
+		return nil
+	}
+
+	module, err := wasminspector.ParseModule(contract)
+	if err != nil {
+		return err
+	}
+
+	for _, memory := range module.Memories {
+		if memory.MaximumPages() > context.maxMemoryPages {
+			return arwen.ErrMemoryLimitExceeded
+		}
+	}
+
+	return nil
+}
+
+// MemoryPages returns the size of the memory of the current instance, in pages.
+func (context *runtimeContext) MemoryPages() uint32 {
+	return context.instance.GetInstanceCtxMemory().Length() / arwen.WASMPageSize
+}
+
+// ChargeMemoryGrowth charges the pages by which the current instance grew its
+// memory since it had the given number of pages, and stops the execution if
+// the memory exceeds the maximum number of pages. Wasmer does not notify Arwen
+// about memory.grow, therefore the growth is measured only after the exported
+// functions return; the failed functions consume all their gas anyway.
+func (context *runtimeContext) ChargeMemoryGrowth(pagesBefore uint32) error {
+	if !context.host.IsFeatureEnabled(arwen.MemoryGrowthMeteringFeature) {
+		return nil
+	}
+
+	pages := context.MemoryPages()
+	if context.isMemoryLimitExceeded(pages) {
+		context.SetRuntimeBreakpointValue(arwen.BreakpointMemoryLimit)
+		return arwen.ErrMemoryLimitExceeded
+	}
+	if pages <= pagesBefore {
+		return nil
+	}
+
+	metering := context.host.Metering()
+	costPerPage := metering.GasSchedule().BaseOperationCost.MemoryGrowPerPage
+	err := metering.UseGasBounded(math.MulUint64(uint64(pages-pagesBefore), costPerPage))
+	if err != nil {
+		context.SetRuntimeBreakpointValue(arwen.BreakpointOutOfGas)
+		return err
+	}
+
+	return nil
+}
+
+func (context *runtimeContext) isMemoryLimitExceeded(pages uint32) bool {
+	return context.maxMemoryPages > 0 && pages > context.maxMemoryPages
+}
+
 // InitStateFromContractCallInput initializes the runtime context state with the values from the given input
 func (context *runtimeContext) InitStateFromContractCallInput(input *vmcommon.ContractCallInput) {
 	context.SetVMInput(&input.VMInput)
@@ -235,8 +359,10 @@ func (context *runtimeContext) InitStateFromContractCallInput(input *vmcommon.Co
 	// Reset async map for initial state
 	context.asyncContextInfo = &arwen.AsyncContextInfo{
 		CallerAddr:      input.CallerAddr,
+		CallType:        input.CallType,
 		AsyncContextMap: make(map[string]*arwen.AsyncContext),
 	}
+	context.callbackClosure = nil
 
 	logRuntime.Trace("init state from call input",
 		"caller", input.CallerAddr,
@@ -260,6 +386,7 @@ func (context *runtimeContext) PushState() {
 		readOnly:         context.readOnly,
 		asyncCallInfo:    context.asyncCallInfo,
 		asyncContextInfo: context.asyncContextInfo,
+		callbackClosure:  context.callbackClosure,
 	}
 	newState.SetVMInput(context.vmInput)
 
@@ -289,6 +416,7 @@ func (context *runtimeContext) PopSetActiveState() {
 	context.readOnly = prevState.readOnly
 	context.asyncCallInfo = prevState.asyncCallInfo
 	context.asyncContextInfo = prevState.asyncContextInfo
+	context.callbackClosure = prevState.callbackClosure
 	context.popInstance()
 }
 
@@ -524,6 +652,18 @@ func (context *runtimeContext) VerifyContractCode() error {
 		return err
 	}
 
+	err = context.validator.verifyFeatureGatedImports(context.instance, context.host)
+	if err != nil {
+		logRuntime.Trace("verify contract code", "error", err)
+		return err
+	}
+
+	err = context.validator.verifyFeatureReservedFunctions(context.instance, context.host)
+	if err != nil {
+		logRuntime.Trace("verify contract code", "error", err)
+		return err
+	}
+
 	logRuntime.Trace("verified contract code")
 
 	return nil
@@ -613,6 +753,11 @@ func (context *runtimeContext) IsContractOnTheStack(address []byte) bool {
 	return false
 }
 
+// CallDepth returns the number of contract executions below the current one.
+func (context *runtimeContext) CallDepth() int {
+	return len(context.stateStack)
+}
+
 // GetFunctionToCall returns the function to call from the wasmer instance exports.
 func (context *runtimeContext) GetFunctionToCall() (wasmer.ExportedFunctionCallback, error) {
 	exports := context.instance.GetExports()
@@ -689,6 +834,13 @@ func (context *runtimeContext) AddAsyncContextCall(contextIdentifier []byte, asy
 		}
 	}
 
+	// the calls are never removed from the map of the running contract, so
+	// their number orders them by creation
+	asyncCall.Sequence = 0
+	for _, asyncContext := range currentContextMap {
+		asyncCall.Sequence += uint64(len(asyncContext.AsyncCalls))
+	}
+
 	currentContextMap[string(contextIdentifier)].AsyncCalls =
 		append(currentContextMap[string(contextIdentifier)].AsyncCalls, asyncCall)
 
@@ -700,6 +852,11 @@ func (context *runtimeContext) GetAsyncContextInfo() *arwen.AsyncContextInfo {
 	return context.asyncContextInfo
 }
 
+// SetAsyncContextInfo replaces the async context info of the current context.
+func (context *runtimeContext) SetAsyncContextInfo(asyncContextInfo *arwen.AsyncContextInfo) {
+	context.asyncContextInfo = asyncContextInfo
+}
+
 // GetAsyncContext returns the async context mapped to the given context identifier.
 func (context *runtimeContext) GetAsyncContext(contextIdentifier []byte) (*arwen.AsyncContext, error) {
 	asyncContext, ok := context.asyncContextInfo.AsyncContextMap[string(contextIdentifier)]
@@ -710,6 +867,18 @@ func (context *runtimeContext) GetAsyncContext(contextIdentifier []byte) (*arwen
 	return asyncContext, nil
 }
 
+// SetCallbackClosure sets the data attached to the async call which the
+// current context calls back.
+func (context *runtimeContext) SetCallbackClosure(callbackClosure []byte) {
+	context.callbackClosure = callbackClosure
+}
+
+// GetCallbackClosure returns the data attached to the async call which the
+// current context calls back, or nil if the current context is not a callback.
+func (context *runtimeContext) GetCallbackClosure() []byte {
+	return context.callbackClosure
+}
+
 // GetAsyncCallInfo returns the async call info for the current context.
 func (context *runtimeContext) GetAsyncCallInfo() *arwen.AsyncCallInfo {
 	return context.asyncCallInfo
@@ -799,6 +968,11 @@ func (context *runtimeContext) MemStore(offset int32, data []byte) error {
 		return arwen.ErrBadLowerBounds
 	}
 	if isNewPageNecessary {
+		isLimitEnforced := context.host.IsFeatureEnabled(arwen.MemoryGrowthMeteringFeature)
+		if isLimitEnforced && context.isMemoryLimitExceeded(memoryLength/arwen.WASMPageSize+1) {
+			return arwen.ErrMemoryLimitExceeded
+		}
+
 		err := memory.Grow(1)
 		if err != nil {
 			return err
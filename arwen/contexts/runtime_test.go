@@ -685,3 +685,57 @@ func TestRuntimeContext_PopInstanceIfStackIsEmptyShouldNotPanic(t *testing.T) {
 
 	require.Equal(t, 0, len(runtimeContext.stateStack))
 }
+
+func TestRuntimeContext_MemoryGrowth(t *testing.T) {
+	host := InitializeArwenAndWasmer()
+	mockMetering := host.MeteringContext.(*contextmock.MeteringContextMock)
+
+	vmType := []byte("type")
+	runtimeContext, _ := NewRuntimeContext(host, vmType, builtInFunctions.NewBuiltInFunctionContainer())
+	runtimeContext.SetMaxInstanceCount(1)
+	runtimeContext.SetMaxMemoryPages(3)
+
+	// the counter declares no maximum memory size, so its memory could grow
+	// beyond the limit; it cannot be deployed, but it stays callable if it
+	// was deployed before
+	gasLimit := uint64(100000000)
+	contractCode := arwen.GetSCCode(counterWasmCode)
+	err := runtimeContext.StartWasmerInstance(contractCode, gasLimit, true)
+	require.Equal(t, arwen.ErrMemoryLimitExceeded, err)
+	require.Nil(t, runtimeContext.instance)
+
+	err = runtimeContext.StartWasmerInstance(contractCode, gasLimit, false)
+	require.Nil(t, err)
+
+	pagesBefore := runtimeContext.MemoryPages()
+	require.Equal(t, uint32(2), pagesBefore)
+	require.Nil(t, runtimeContext.ChargeMemoryGrowth(pagesBefore))
+
+	memory := runtimeContext.instance.GetInstanceCtxMemory()
+	err = memory.Grow(1)
+	require.Nil(t, err)
+	require.Nil(t, runtimeContext.ChargeMemoryGrowth(pagesBefore))
+
+	// the memory may not be grown by MemStore beyond the limit
+	err = runtimeContext.MemStore(int32(3*WASMPageSize-2), []byte("test"))
+	require.Equal(t, arwen.ErrMemoryLimitExceeded, err)
+	require.Equal(t, uint32(3), runtimeContext.MemoryPages())
+
+	// nor by the contract itself
+	err = memory.Grow(1)
+	require.Nil(t, err)
+	err = runtimeContext.ChargeMemoryGrowth(pagesBefore)
+	require.Equal(t, arwen.ErrMemoryLimitExceeded, err)
+	require.Equal(t, arwen.BreakpointMemoryLimit, runtimeContext.GetRuntimeBreakpointValue())
+
+	// the growth must be paid for
+	runtimeContext.SetRuntimeBreakpointValue(arwen.BreakpointNone)
+	runtimeContext.SetMaxMemoryPages(0)
+	mockMetering.Err = arwen.ErrNotEnoughGas
+	err = runtimeContext.ChargeMemoryGrowth(pagesBefore)
+	require.Equal(t, arwen.ErrNotEnoughGas, err)
+	require.Equal(t, arwen.BreakpointOutOfGas, runtimeContext.GetRuntimeBreakpointValue())
+
+	err = runtimeContext.ChargeMemoryGrowth(runtimeContext.MemoryPages())
+	require.Nil(t, err)
+}
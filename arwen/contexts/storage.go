@@ -20,6 +20,7 @@ type storageContext struct {
 	stateStack                    [][]byte
 	elrondProtectedKeyPrefix      []byte
 	arwenStorageProtectionEnabled bool
+	writtenUpdates                map[*vmcommon.StorageUpdate]struct{}
 }
 
 // NewStorageContext creates a new storageContext
@@ -37,13 +38,15 @@ func NewStorageContext(
 		stateStack:                    make([][]byte, 0),
 		elrondProtectedKeyPrefix:      elrondProtectedKeyPrefix,
 		arwenStorageProtectionEnabled: true,
+		writtenUpdates:                make(map[*vmcommon.StorageUpdate]struct{}),
 	}
 
 	return context, nil
 }
 
-// InitState does nothing
+// InitState forgets the storage updates written by the previous execution
 func (context *storageContext) InitState() {
+	context.writtenUpdates = make(map[*vmcommon.StorageUpdate]struct{})
 }
 
 // PushState appends the current address to the state stack.
@@ -244,15 +247,15 @@ func (context *storageContext) SetStorage(key []byte, value []byte) (arwen.Stora
 		useGas := math.MulUint64(metering.GasSchedule().BaseOperationCost.DataCopyPerByte, uint64(length))
 		metering.UseGas(useGas)
 		logStorage.Trace("storage set to identical value")
+		if context.host.IsCallTreeEnabled() {
+			context.recordWrittenUpdate(storageUpdates, key, oldValue)
+		}
 		return arwen.StorageUnchanged, nil
 	}
 
-	newUpdate := &vmcommon.StorageUpdate{
-		Offset: key,
-		Data:   make([]byte, length),
-	}
-	copy(newUpdate.Data[:length], value[:length])
-	storageUpdates[strKey] = newUpdate
+	newData := make([]byte, length)
+	copy(newData[:length], value[:length])
+	context.recordWrittenUpdate(storageUpdates, key, newData)
 
 	if bytes.Equal(oldValue, zero) {
 		useGas := math.MulUint64(metering.GasSchedule().BaseOperationCost.StorePerByte, uint64(length))
@@ -290,3 +293,27 @@ func (context *storageContext) SetStorage(key []byte, value []byte) (arwen.Stora
 	logStorage.Trace("storage modified", "key", key, "value", value, "lengthDelta", newValueExtraLength)
 	return arwen.StorageModified, nil
 }
+
+// recordWrittenUpdate replaces the storage update of the key with a new one
+// holding the written value; the update is remembered as a write only while
+// the call tree is built, since only the call tree needs to tell the writes
+// apart from the updates created when reading the storage
+func (context *storageContext) recordWrittenUpdate(storageUpdates map[string]*vmcommon.StorageUpdate, key []byte, value []byte) {
+	writtenUpdate := &vmcommon.StorageUpdate{
+		Offset: key,
+		Data:   value,
+	}
+	storageUpdates[string(key)] = writtenUpdate
+	if context.host.IsCallTreeEnabled() {
+		context.writtenUpdates[writtenUpdate] = struct{}{}
+	}
+}
+
+// IsStorageUpdateWritten returns true if the given storage update has been
+// created by a write to the storage during the current execution, as opposed
+// to caching a value read from the blockchain; the writes are only tracked
+// while the call tree is built
+func (context *storageContext) IsStorageUpdateWritten(update *vmcommon.StorageUpdate) bool {
+	_, written := context.writtenUpdates[update]
+	return written
+}
@@ -202,6 +202,40 @@ func TestStorageContext_SetStorage(t *testing.T) {
 	require.Equal(t, arwen.ErrStoreElrondReservedKey, err)
 }
 
+func TestStorageContext_WrittenUpdatesTrackedForCallTree(t *testing.T) {
+	t.Parallel()
+
+	address := []byte("account")
+	mockOutput := &contextmock.OutputContextMock{}
+	mockOutput.OutputAccountMock = mockOutput.NewVMOutputAccount(address)
+
+	mockMetering := &contextmock.MeteringContextMock{}
+	mockMetering.SetGasSchedule(config.MakeGasMapForTests())
+	mockMetering.BlockGasLimitMock = uint64(15000)
+
+	host := &contextmock.VMHostMock{
+		OutputContext:   mockOutput,
+		MeteringContext: mockMetering,
+		RuntimeContext:  &contextmock.RuntimeContextMock{},
+	}
+	storageContext, _ := NewStorageContext(host, &contextmock.BlockchainHookStub{}, elrondReservedTestPrefix)
+	storageContext.SetAddress(address)
+
+	key := []byte("key")
+	_, _ = storageContext.SetStorage(key, []byte("value"))
+	update := storageContext.GetStorageUpdates(address)[string(key)]
+	require.False(t, storageContext.IsStorageUpdateWritten(update))
+
+	// without the call tree, writing an unchanged value keeps the update
+	_, _ = storageContext.SetStorage(key, []byte("value"))
+	require.True(t, update == storageContext.GetStorageUpdates(address)[string(key)])
+
+	host.SetCallTreeEnabled(true)
+	_, _ = storageContext.SetStorage(key, []byte("value"))
+	update = storageContext.GetStorageUpdates(address)[string(key)]
+	require.True(t, storageContext.IsStorageUpdateWritten(update))
+}
+
 func TestStorageContext_StorageProtection(t *testing.T) {
 	address := []byte("account")
 	mockOutput := &contextmock.OutputContextMock{}
@@ -2,9 +2,12 @@ package contexts
 
 import (
 	"fmt"
+	"sort"
 	"unicode"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminspector"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
 	"github.com/ElrondNetwork/elrond-vm-common"
 )
@@ -13,7 +16,8 @@ const noArity = -1
 
 // wasmValidator is a validator for WASM SmartContracts
 type wasmValidator struct {
-	reserved *reservedFunctions
+	reserved         *reservedFunctions
+	complexityLimits arwen.ContractComplexityLimits
 }
 
 // newWASMValidator creates a new WASMValidator
@@ -23,6 +27,49 @@ func newWASMValidator(scAPINames vmcommon.FunctionNames, builtInFuncContainer vm
 	}
 }
 
+// maxVerifiedCodeSize bounds the size of the code parsed by verifyBytecode
+const maxVerifiedCodeSize = 2 * 1024 * 1024
+
+// verifyBytecode applies the checks which Arwen makes on the code itself,
+// before it is compiled by Wasmer: the complexity limits and the floating
+// point policy; the code is only parsed if any of these checks is enforced,
+// and rejected if it is larger than maxVerifiedCodeSize
+func (validator *wasmValidator) verifyBytecode(contract []byte, host arwen.VMHost) error {
+	rejectFloatingPoint := host.IsFeatureEnabled(arwen.FloatingPointRejectionFeature)
+	if !validator.complexityLimits.IsEnforced() && !rejectFloatingPoint {
+		return nil
+	}
+
+	if len(contract) > maxVerifiedCodeSize {
+		return fmt.Errorf("%w: %d bytes", arwen.ErrCodeTooLarge, len(contract))
+	}
+
+	module, err := wasminspector.ParseModule(contract)
+	if err != nil {
+		return err
+	}
+
+	err = wasminspector.VerifyComplexityLimits(module, validator.complexityLimits)
+	if err != nil {
+		return err
+	}
+
+	return validator.verifyFloatingPoint(module, rejectFloatingPoint)
+}
+
+// verifyFloatingPoint rejects the contracts containing floating point
+// instructions, or only logs a warning about them before the activation of
+// the FloatingPointRejectionFeature
+func (validator *wasmValidator) verifyFloatingPoint(module *wasmparser.Module, reject bool) error {
+	err := wasminspector.VerifyNoFloatOpcodes(module)
+	if err == nil || reject {
+		return err
+	}
+
+	logRuntime.Warn("contract code contains floating point instructions", "opcodes", module.FloatOpcodeNames())
+	return nil
+}
+
 func (validator *wasmValidator) verifyMemoryDeclaration(instance wasmer.InstanceHandler) error {
 	if !instance.HasMemory() {
 		return arwen.ErrMemoryDeclarationMissing
@@ -47,6 +94,46 @@ func (validator *wasmValidator) verifyFunctions(instance wasmer.InstanceHandler)
 	return nil
 }
 
+// verifyFeatureGatedImports rejects the contracts which import EEI functions
+// whose feature is not yet enabled
+func (validator *wasmValidator) verifyFeatureGatedImports(instance wasmer.InstanceHandler, host arwen.VMHost) error {
+	for _, functionName := range sortedFunctionNames(arwen.FeatureGatedEEIFunctions) {
+		flag := arwen.FeatureGatedEEIFunctions[functionName]
+		if instance.IsFunctionImported(functionName) && !host.IsFeatureEnabled(flag) {
+			return fmt.Errorf("%w: %s", arwen.ErrFunctionNotEnabled, functionName)
+		}
+	}
+
+	return nil
+}
+
+// verifyFeatureReservedFunctions rejects the contracts which export functions
+// handled by the host itself once their feature is enabled
+func (validator *wasmValidator) verifyFeatureReservedFunctions(instance wasmer.InstanceHandler, host arwen.VMHost) error {
+	exports := instance.GetExports()
+	for _, functionName := range sortedFunctionNames(arwen.FeatureReservedFunctionNames) {
+		flag := arwen.FeatureReservedFunctionNames[functionName]
+		_, isExported := exports[functionName]
+		if isExported && host.IsFeatureEnabled(flag) {
+			return fmt.Errorf("%w: %s", arwen.ErrInvalidFunctionName, functionName)
+		}
+	}
+
+	return nil
+}
+
+// sortedFunctionNames returns the function names of the given map in order,
+// so that the first offending function reported is always the same one
+func sortedFunctionNames(functions map[string]arwen.FeatureFlag) []string {
+	functionNames := make([]string, 0, len(functions))
+	for functionName := range functions {
+		functionNames = append(functionNames, functionName)
+	}
+	sort.Strings(functionNames)
+
+	return functionNames
+}
+
 func (validator *wasmValidator) verifyVoidFunction(instance wasmer.InstanceHandler, functionName string) error {
 	inArity, err := validator.getInputArity(instance, functionName)
 	if err != nil {
@@ -1,11 +1,13 @@
 package contexts
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/mock"
+	contextmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
 	"github.com/ElrondNetwork/elrond-vm-common/builtInFunctions"
 	"github.com/stretchr/testify/require"
@@ -95,3 +97,122 @@ func TestFunctionsGuard_Arity(t *testing.T) {
 	err = validator.verifyVoidFunction(instance, "wrongParamsAndReturn")
 	require.NotNil(t, err)
 }
+
+func TestFunctionsGuard_FeatureGatedImports(t *testing.T) {
+	validator := newWASMValidator(MakeAPIImports().Names(), builtInFunctions.NewBuiltInFunctionContainer())
+
+	featureEnabled := false
+	host := &contextmock.VMHostStub{
+		IsFeatureEnabledCalled: func(flag arwen.FeatureFlag) bool {
+			require.Equal(t, arwen.SelfDestructFeature, flag)
+			return featureEnabled
+		},
+	}
+
+	instance := contextmock.NewInstanceMock(nil)
+	require.Nil(t, validator.verifyFeatureGatedImports(instance, host))
+
+	instance.Exports["selfDestruct"] = nil
+	err := validator.verifyFeatureGatedImports(instance, host)
+	require.True(t, errors.Is(err, arwen.ErrFunctionNotEnabled))
+
+	featureEnabled = true
+	require.Nil(t, validator.verifyFeatureGatedImports(instance, host))
+}
+
+func TestFunctionsGuard_FeatureGatedImportsReportedInOrder(t *testing.T) {
+	validator := newWASMValidator(MakeAPIImports().Names(), builtInFunctions.NewBuiltInFunctionContainer())
+
+	host := &contextmock.VMHostStub{
+		IsFeatureEnabledCalled: func(flag arwen.FeatureFlag) bool {
+			return false
+		},
+	}
+
+	instance := contextmock.NewInstanceMock(nil)
+	instance.Exports["selfDestruct"] = nil
+	instance.Exports["getCallbackClosure"] = nil
+	instance.Exports["createAsyncCall"] = nil
+
+	for i := 0; i < 10; i++ {
+		err := validator.verifyFeatureGatedImports(instance, host)
+		require.True(t, errors.Is(err, arwen.ErrFunctionNotEnabled))
+		require.Contains(t, err.Error(), "createAsyncCall")
+	}
+}
+
+func TestFunctionsGuard_FeatureReservedFunctions(t *testing.T) {
+	validator := newWASMValidator(MakeAPIImports().Names(), builtInFunctions.NewBuiltInFunctionContainer())
+
+	featureEnabled := false
+	host := &contextmock.VMHostStub{
+		IsFeatureEnabledCalled: func(flag arwen.FeatureFlag) bool {
+			require.Equal(t, arwen.AsyncCallExpiryFeature, flag)
+			return featureEnabled
+		},
+	}
+
+	instance := contextmock.NewInstanceMock(nil)
+	instance.Exports[arwen.ResolveExpiredAsyncCallsFunctionName] = nil
+	require.Nil(t, validator.verifyFeatureReservedFunctions(instance, host))
+
+	featureEnabled = true
+	err := validator.verifyFeatureReservedFunctions(instance, host)
+	require.True(t, errors.Is(err, arwen.ErrInvalidFunctionName))
+
+	delete(instance.Exports, arwen.ResolveExpiredAsyncCallsFunctionName)
+	require.Nil(t, validator.verifyFeatureReservedFunctions(instance, host))
+}
+
+func TestFunctionsGuard_ComplexityLimits(t *testing.T) {
+	imports := MakeAPIImports()
+	validator := newWASMValidator(imports.Names(), builtInFunctions.NewBuiltInFunctionContainer())
+	contractCode := arwen.GetSCCode(counterWasmCode)
+	host := &contextmock.VMHostStub{
+		IsFeatureEnabledCalled: func(flag arwen.FeatureFlag) bool {
+			return false
+		},
+	}
+
+	require.Nil(t, validator.verifyBytecode(contractCode, host))
+	require.Nil(t, validator.verifyBytecode([]byte("not a WASM module"), host))
+
+	validator.complexityLimits = arwen.ContractComplexityLimits{MaxFunctions: 100}
+	require.Nil(t, validator.verifyBytecode(contractCode, host))
+
+	err := validator.verifyBytecode([]byte("not a WASM module"), host)
+	require.True(t, errors.Is(err, arwen.ErrContractInvalid))
+
+	validator.complexityLimits = arwen.ContractComplexityLimits{MaxInitialMemoryPages: 1}
+	err = validator.verifyBytecode(contractCode, host)
+	require.True(t, errors.Is(err, arwen.ErrInitialMemoryTooLarge))
+
+	err = validator.verifyBytecode(make([]byte, maxVerifiedCodeSize+1), host)
+	require.True(t, errors.Is(err, arwen.ErrCodeTooLarge))
+}
+
+func TestFunctionsGuard_FloatingPointPolicy(t *testing.T) {
+	validator := newWASMValidator(MakeAPIImports().Names(), builtInFunctions.NewBuiltInFunctionContainer())
+	contractCode := arwen.GetSCCode("./../../test/contracts/num-with-fp/output/num-with-fp.wasm")
+
+	rejectionEnabled := false
+	host := &contextmock.VMHostStub{
+		IsFeatureEnabledCalled: func(flag arwen.FeatureFlag) bool {
+			require.Equal(t, arwen.FloatingPointRejectionFeature, flag)
+			return rejectionEnabled
+		},
+	}
+
+	require.Nil(t, validator.verifyBytecode(contractCode, host))
+	require.Nil(t, validator.verifyBytecode([]byte("not a WASM module"), host))
+
+	rejectionEnabled = true
+	err := validator.verifyBytecode(contractCode, host)
+	require.True(t, errors.Is(err, arwen.ErrFloatingPointOpcode))
+	require.True(t, errors.Is(err, arwen.ErrContractInvalid))
+
+	err = validator.verifyBytecode([]byte("not a WASM module"), host)
+	require.True(t, errors.Is(err, arwen.ErrMalformedWASMModule))
+
+	require.Nil(t, validator.verifyBytecode(arwen.GetSCCode(counterWasmCode), host))
+}
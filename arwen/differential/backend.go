@@ -0,0 +1,85 @@
+package differential
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	arwenHost "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/host"
+	am "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwenmandos"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	"github.com/ElrondNetwork/elrond-vm-common/parsers"
+)
+
+// blockGasLimit is the block gas limit of the VMs under comparison, the
+// same as the one of the mandos executors
+const blockGasLimit = uint64(10000000)
+
+// Backend configures one of the two VMs under comparison. The backends are
+// run one after the other, never concurrently, because the opcode costs are
+// global to the wasmer and executor packages.
+type Backend struct {
+	// Name identifies the backend in the reports
+	Name string
+
+	// InstanceBuilder creates the contract instances; nil keeps Wasmer
+	InstanceBuilder arwen.InstanceBuilder
+
+	// GasSchedule replaces the gas costs; nil keeps the gas schedule declared
+	// by the scenarios, or the gas schedule for tests when replaying calls
+	GasSchedule config.GasScheduleMap
+}
+
+func (backend Backend) newTestExecutor() (*am.ArwenTestExecutor, error) {
+	var executor *am.ArwenTestExecutor
+	var err error
+	if backend.InstanceBuilder == nil {
+		executor, err = am.NewArwenTestExecutor()
+	} else {
+		executor, err = am.NewArwenTestExecutorWithInstanceBuilder(backend.InstanceBuilder)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if backend.GasSchedule != nil {
+		executor.OverrideGasSchedule(backend.GasSchedule)
+	}
+
+	return executor, nil
+}
+
+func (backend Backend) newVMHost(world *worldmock.MockWorld) (arwen.VMHost, error) {
+	gasSchedule := backend.GasSchedule
+	if gasSchedule == nil {
+		gasSchedule = config.MakeGasMapForTests()
+	}
+
+	err := world.InitBuiltinFunctions(gasSchedule)
+	if err != nil {
+		return nil, err
+	}
+
+	esdtTransferParser, err := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := arwenHost.NewArwenVM(world, &arwen.VMHostParameters{
+		VMType:                   am.TestVMType,
+		BlockGasLimit:            blockGasLimit,
+		GasSchedule:              gasSchedule,
+		BuiltInFuncContainer:     world.BuiltinFuncs.Container,
+		ElrondProtectedKeyPrefix: []byte(am.ElrondProtectedKeyPrefix),
+		ESDTTransferParser:       esdtTransferParser,
+		EpochNotifier:            world.EpochNotifier,
+		FeatureActivationEpochs:  worldmock.MakeFeatureActivationEpochsForTests(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if backend.InstanceBuilder != nil {
+		host.Runtime().ReplaceInstanceBuilder(backend.InstanceBuilder)
+	}
+
+	return host, nil
+}
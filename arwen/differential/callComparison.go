@@ -0,0 +1,240 @@
+package differential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// WorldFactory creates the world in which the calls are replayed; it is
+// called once for each backend and must always create the same world
+type WorldFactory func() (*worldmock.MockWorld, error)
+
+// RecordedInput is an input of the VM recorded by a CallRecorder; either the
+// deployment of a contract or a call to a contract, never both
+type RecordedInput struct {
+	Create *vmcommon.ContractCreateInput `json:"create,omitempty"`
+	Call   *vmcommon.ContractCallInput   `json:"call,omitempty"`
+}
+
+// CompareCalls replays the given calls, in order, with each of the two
+// backends, every backend in its own world, then compares their outputs.
+// The changes of the successful calls are applied to the world before the
+// next call, like the protocol does.
+func CompareCalls(calls []*vmcommon.ContractCallInput, newWorld WorldFactory, first Backend, second Backend) (*Report, error) {
+	inputs := make([]*RecordedInput, len(calls))
+	for i, call := range calls {
+		inputs[i] = &RecordedInput{Call: call}
+	}
+
+	return CompareInputs(inputs, newWorld, first, second)
+}
+
+// CompareInputs replays the given deployments and calls, such as those
+// recorded by a CallRecorder, like CompareCalls.
+func CompareInputs(inputs []*RecordedInput, newWorld WorldFactory, first Backend, second Backend) (*Report, error) {
+	firstExecutions, err := runInputs(inputs, newWorld, first)
+	if err != nil {
+		return nil, err
+	}
+
+	secondExecutions, err := runInputs(inputs, newWorld, second)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport("calls", first, second)
+	report.compareExecutions(firstExecutions, secondExecutions)
+
+	return report, nil
+}
+
+func runInputs(inputs []*RecordedInput, newWorld WorldFactory, backend Backend) ([]*txExecution, error) {
+	world, err := newWorld()
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := backend.newVMHost(world)
+	if err != nil {
+		return nil, err
+	}
+
+	executions := make([]*txExecution, 0, len(inputs))
+	for i, input := range inputs {
+		var vmOutput *vmcommon.VMOutput
+		var txID string
+		switch {
+		case input.Create != nil:
+			txID = fmt.Sprintf("%d:deploy", i)
+			vmOutput, err = host.RunSmartContractCreate(input.Create)
+		case input.Call != nil:
+			txID = fmt.Sprintf("%d:%s", i, input.Call.Function)
+			vmOutput, err = host.RunSmartContractCall(input.Call)
+		default:
+			return nil, fmt.Errorf("input %d is neither a deployment nor a call", i)
+		}
+
+		executions = append(executions, &txExecution{
+			txID:     txID,
+			vmOutput: vmOutput,
+			err:      err,
+		})
+
+		if err != nil || vmOutput == nil || vmOutput.ReturnCode != vmcommon.Ok {
+			continue
+		}
+
+		err = world.UpdateAccounts(vmOutput.OutputAccounts, vmOutput.DeletedAccounts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return executions, nil
+}
+
+// CallRecorder is a VMExecutionHandler which records the ContractCreateInputs
+// and the ContractCallInputs passed to the VM it wraps, so that they can be
+// replayed by CompareInputs
+type CallRecorder struct {
+	vmcommon.VMExecutionHandler
+	inputs []*RecordedInput
+	mutex  sync.Mutex
+}
+
+// NewCallRecorder creates a CallRecorder wrapping the given VM
+func NewCallRecorder(vm vmcommon.VMExecutionHandler) *CallRecorder {
+	return &CallRecorder{
+		VMExecutionHandler: vm,
+		inputs:             make([]*RecordedInput, 0),
+	}
+}
+
+// RunSmartContractCreate records a copy of the input, then deploys the
+// contract with the wrapped VM
+func (recorder *CallRecorder) RunSmartContractCreate(input *vmcommon.ContractCreateInput) (*vmcommon.VMOutput, error) {
+	if input != nil {
+		recordedInput := &vmcommon.ContractCreateInput{
+			VMInput:              copyVMInput(&input.VMInput),
+			ContractCode:         copyBytes(input.ContractCode),
+			ContractCodeMetadata: copyBytes(input.ContractCodeMetadata),
+		}
+		recorder.record(&RecordedInput{Create: recordedInput})
+	}
+
+	return recorder.VMExecutionHandler.RunSmartContractCreate(input)
+}
+
+// RunSmartContractCall records a copy of the input, then executes the call
+// with the wrapped VM
+func (recorder *CallRecorder) RunSmartContractCall(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+	if input != nil {
+		recordedInput := &vmcommon.ContractCallInput{
+			VMInput:           copyVMInput(&input.VMInput),
+			RecipientAddr:     copyBytes(input.RecipientAddr),
+			Function:          input.Function,
+			AllowInitFunction: input.AllowInitFunction,
+		}
+		recorder.record(&RecordedInput{Call: recordedInput})
+	}
+
+	return recorder.VMExecutionHandler.RunSmartContractCall(input)
+}
+
+func (recorder *CallRecorder) record(input *RecordedInput) {
+	recorder.mutex.Lock()
+	recorder.inputs = append(recorder.inputs, input)
+	recorder.mutex.Unlock()
+}
+
+// Inputs returns the inputs recorded so far, in their order of execution
+func (recorder *CallRecorder) Inputs() []*RecordedInput {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	inputs := make([]*RecordedInput, len(recorder.inputs))
+	copy(inputs, recorder.inputs)
+	return inputs
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (recorder *CallRecorder) IsInterfaceNil() bool {
+	return recorder == nil
+}
+
+// copyVMInput copies the given input, so that the recorded input is not
+// changed when the caller or the VM reuse the slices and the big integers
+// of the original one
+func copyVMInput(input *vmcommon.VMInput) vmcommon.VMInput {
+	vmInput := *input
+	vmInput.CallerAddr = copyBytes(input.CallerAddr)
+	vmInput.OriginalTxHash = copyBytes(input.OriginalTxHash)
+	vmInput.CurrentTxHash = copyBytes(input.CurrentTxHash)
+	vmInput.PrevTxHash = copyBytes(input.PrevTxHash)
+
+	if input.CallValue != nil {
+		vmInput.CallValue = big.NewInt(0).Set(input.CallValue)
+	}
+
+	if input.Arguments != nil {
+		vmInput.Arguments = make([][]byte, len(input.Arguments))
+		for i, argument := range input.Arguments {
+			vmInput.Arguments[i] = copyBytes(argument)
+		}
+	}
+
+	if input.ESDTTransfers != nil {
+		vmInput.ESDTTransfers = make([]*vmcommon.ESDTTransfer, len(input.ESDTTransfers))
+		for i, esdtTransfer := range input.ESDTTransfers {
+			vmInput.ESDTTransfers[i] = copyESDTTransfer(esdtTransfer)
+		}
+	}
+
+	return vmInput
+}
+
+func copyESDTTransfer(esdtTransfer *vmcommon.ESDTTransfer) *vmcommon.ESDTTransfer {
+	if esdtTransfer == nil {
+		return nil
+	}
+
+	newESDTTransfer := *esdtTransfer
+	newESDTTransfer.ESDTTokenName = copyBytes(esdtTransfer.ESDTTokenName)
+	if esdtTransfer.ESDTValue != nil {
+		newESDTTransfer.ESDTValue = big.NewInt(0).Set(esdtTransfer.ESDTValue)
+	}
+
+	return &newESDTTransfer
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+
+	return append(make([]byte, 0, len(data)), data...)
+}
+
+// WriteInputs writes the inputs as JSON, to be read back by ReadInputs
+func WriteInputs(writer io.Writer, inputs []*RecordedInput) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(inputs)
+}
+
+// ReadInputs reads the inputs written by WriteInputs
+func ReadInputs(reader io.Reader) ([]*RecordedInput, error) {
+	inputs := make([]*RecordedInput, 0)
+	err := json.NewDecoder(reader).Decode(&inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,129 @@
+package differential
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminterpreter"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func newCounterWorld() (*worldmock.MockWorld, error) {
+	world := worldmock.NewMockWorld()
+	caller := world.AcctMap.CreateAccount(test.UserAddress, world)
+	caller.Balance = big.NewInt(1000)
+	test.AddTestSmartContractToWorld(world, "counter", test.GetTestSCCode("counter", "../../"))
+
+	return world, nil
+}
+
+func makeCounterCalls() []*vmcommon.ContractCallInput {
+	calls := make([]*vmcommon.ContractCallInput, 0)
+	for _, function := range []string{"increment", "increment", "get", "missing"} {
+		calls = append(calls, test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.MakeTestSCAddress("counter")).
+			WithGasProvided(100000).
+			WithFunction(function).
+			Build())
+	}
+
+	return calls
+}
+
+func TestCallRecorder(t *testing.T) {
+	world, err := newCounterWorld()
+	require.Nil(t, err)
+	host, err := Backend{}.newVMHost(world)
+	require.Nil(t, err)
+
+	recorder := NewCallRecorder(host)
+	deploy := test.CreateTestContractCreateInputBuilder().
+		WithCallerAddr(test.UserAddress).
+		WithContractCode(test.GetTestSCCode("counter", "../../")).
+		WithGasProvided(1000000).
+		Build()
+	vmOutput, err := recorder.RunSmartContractCreate(deploy)
+	require.Nil(t, err)
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+
+	calls := makeCounterCalls()
+	calls[0].Arguments = [][]byte{{1}}
+	for _, call := range calls[:2] {
+		vmOutput, err = recorder.RunSmartContractCall(call)
+		require.Nil(t, err)
+		require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+	}
+
+	inputs := recorder.Inputs()
+	require.Len(t, inputs, 3)
+	require.Equal(t, deploy, inputs[0].Create)
+	require.Nil(t, inputs[0].Call)
+	require.Equal(t, calls[0], inputs[1].Call)
+	require.Equal(t, calls[1], inputs[2].Call)
+
+	deploy.Arguments[0][0] = 'A'
+	deploy.CallValue.SetInt64(100)
+	calls[0].Arguments[0][0] = 2
+	require.Equal(t, []byte("argument 1"), inputs[0].Create.Arguments[0])
+	require.Equal(t, int64(0), inputs[0].Create.CallValue.Int64())
+	require.Equal(t, [][]byte{{1}}, inputs[1].Call.Arguments)
+
+	buffer := &bytes.Buffer{}
+	err = WriteInputs(buffer, inputs)
+	require.Nil(t, err)
+	readInputs, err := ReadInputs(buffer)
+	require.Nil(t, err)
+	require.Equal(t, inputs, readInputs)
+
+	report, err := CompareInputs(readInputs, newCounterWorld, Backend{Name: "first"}, Backend{Name: "second"})
+	require.Nil(t, err)
+	require.Len(t, report.Transactions, 3)
+	require.False(t, report.HasDifferences(), report.String())
+}
+
+func TestCallRecorder_CopyESDTTransfers(t *testing.T) {
+	input := &vmcommon.VMInput{
+		CallValue: big.NewInt(0),
+		ESDTTransfers: []*vmcommon.ESDTTransfer{
+			{ESDTValue: big.NewInt(10), ESDTTokenName: []byte("TOKEN-abcdef"), ESDTTokenNonce: 1},
+		},
+	}
+
+	recordedInput := copyVMInput(input)
+	require.Equal(t, *input, recordedInput)
+
+	input.ESDTTransfers[0].ESDTValue.SetInt64(20)
+	input.ESDTTransfers[0].ESDTTokenName[0] = 'X'
+	require.Equal(t, int64(10), recordedInput.ESDTTransfers[0].ESDTValue.Int64())
+	require.Equal(t, []byte("TOKEN-abcdef"), recordedInput.ESDTTransfers[0].ESDTTokenName)
+}
+
+func TestCompareCalls_SameBackend(t *testing.T) {
+	report, err := CompareCalls(makeCounterCalls(), newCounterWorld, Backend{Name: "first"}, Backend{Name: "second"})
+	require.Nil(t, err)
+	require.Len(t, report.Transactions, 4)
+	require.False(t, report.HasDifferences(), report.String())
+}
+
+func TestCompareCalls_Interpreter(t *testing.T) {
+	interpreter := Backend{Name: "interpreter", InstanceBuilder: wasminterpreter.NewInstanceBuilder()}
+	report, err := CompareCalls(makeCounterCalls(), newCounterWorld, Backend{Name: "wasmer"}, interpreter)
+	require.Nil(t, err)
+	require.False(t, report.HasDifferences(), report.String())
+}
+
+func TestCompareCalls_GasSchedules(t *testing.T) {
+	gasSchedule := config.MakeGasMapForTests()
+	gasSchedule["ElrondAPICost"]["Int64StorageStore"] *= 2
+
+	doubled := Backend{Name: "doubled", GasSchedule: gasSchedule}
+	report, err := CompareCalls(makeCounterCalls(), newCounterWorld, Backend{Name: "tests"}, doubled)
+	require.Nil(t, err)
+	require.True(t, report.HasDifferences())
+	require.Equal(t, "GasRemaining", report.Transactions[0].Differences[0].Field)
+}
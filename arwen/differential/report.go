@@ -0,0 +1,138 @@
+package differential
+
+import (
+	"fmt"
+	"strings"
+
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// TxComparison holds the differences between the outputs which the two
+// backends have produced for the same transaction
+type TxComparison struct {
+	TxID        string
+	Differences []Difference
+}
+
+// Report holds the result of running the same transactions with two backends
+type Report struct {
+	Source        string
+	FirstBackend  string
+	SecondBackend string
+	Transactions  []*TxComparison
+
+	// FirstError and SecondError hold the errors which have stopped the runs
+	// of the backends before their last transaction, if any
+	FirstError  error
+	SecondError error
+}
+
+// HasDifferences returns true if the backends have produced different
+// outputs for any transaction, or if any of the runs has been stopped
+func (report *Report) HasDifferences() bool {
+	if report.FirstError != nil || report.SecondError != nil {
+		return true
+	}
+
+	for _, transaction := range report.Transactions {
+		if len(transaction.Differences) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String formats the differences of the report, one per line
+func (report *Report) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "%s: %s vs %s, %d transactions\n", report.Source, report.FirstBackend, report.SecondBackend, len(report.Transactions))
+	if report.FirstError != nil {
+		fmt.Fprintf(builder, "  %s stopped: %s\n", report.FirstBackend, report.FirstError.Error())
+	}
+	if report.SecondError != nil {
+		fmt.Fprintf(builder, "  %s stopped: %s\n", report.SecondBackend, report.SecondError.Error())
+	}
+
+	for _, transaction := range report.Transactions {
+		for _, difference := range transaction.Differences {
+			fmt.Fprintf(builder, "  tx %s: %s\n", transaction.TxID, difference.String())
+		}
+	}
+
+	return builder.String()
+}
+
+// txExecution is a transaction executed by one of the backends
+type txExecution struct {
+	txID     string
+	vmOutput *vmcommon.VMOutput
+	err      error
+}
+
+func newReport(source string, first Backend, second Backend) *Report {
+	return &Report{
+		Source:        source,
+		FirstBackend:  first.Name,
+		SecondBackend: second.Name,
+		Transactions:  make([]*TxComparison, 0),
+	}
+}
+
+// compareExecutions pairs the transactions executed by the two backends in
+// their order of execution; a transaction which only one backend has reached
+// is compared against an absent output; transactions without an identifier
+// are named after their position
+func (report *Report) compareExecutions(first []*txExecution, second []*txExecution) {
+	for i := 0; i < len(first) || i < len(second); i++ {
+		firstExecution := &txExecution{}
+		if i < len(first) {
+			firstExecution = first[i]
+		}
+		secondExecution := &txExecution{}
+		if i < len(second) {
+			secondExecution = second[i]
+		}
+
+		txID := firstExecution.txID
+		if len(txID) == 0 {
+			txID = secondExecution.txID
+		}
+		if len(txID) == 0 {
+			txID = fmt.Sprintf("#%d", i+1)
+		}
+
+		differences := make([]Difference, 0)
+		if len(firstExecution.txID) > 0 && len(secondExecution.txID) > 0 && firstExecution.txID != secondExecution.txID {
+			differences = append(differences, Difference{
+				Field:  "TxID",
+				First:  firstExecution.txID,
+				Second: secondExecution.txID,
+			})
+		}
+
+		firstErr := errorString(firstExecution.err)
+		secondErr := errorString(secondExecution.err)
+		if firstErr != secondErr {
+			differences = append(differences, Difference{
+				Field:  "Error",
+				First:  firstErr,
+				Second: secondErr,
+			})
+		}
+
+		differences = append(differences, CompareVMOutputs(firstExecution.vmOutput, secondExecution.vmOutput)...)
+		report.Transactions = append(report.Transactions, &TxComparison{
+			TxID:        txID,
+			Differences: differences,
+		})
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return "none"
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,101 @@
+package differential
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	mc "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mandos-go/controller"
+	mj "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mandos-go/json/model"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// scenarioSuffix is the suffix of the mandos scenario files
+const scenarioSuffix = ".scen.json"
+
+// CompareScenario runs the mandos scenario at the given path with each of
+// the two backends and compares the outputs of its transactions. The
+// expected results declared by the scenario are not checked, so that a
+// difference is reported instead of stopping the run.
+func CompareScenario(scenarioPath string, first Backend, second Backend) (*Report, error) {
+	firstRun, err := runScenario(scenarioPath, first)
+	if err != nil {
+		return nil, err
+	}
+
+	secondRun, err := runScenario(scenarioPath, second)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(scenarioPath, first, second)
+	report.FirstError = firstRun.err
+	report.SecondError = secondRun.err
+	report.compareExecutions(firstRun.executions, secondRun.executions)
+
+	return report, nil
+}
+
+// CompareScenariosInDirectory compares the runs of all the mandos scenarios
+// found in the given directory and its subdirectories, returning a report
+// for each scenario, in the order of their paths
+func CompareScenariosInDirectory(directory string, first Backend, second Backend) ([]*Report, error) {
+	scenarioPaths := make([]string, 0)
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, scenarioSuffix) {
+			scenarioPaths = append(scenarioPaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(scenarioPaths)
+
+	reports := make([]*Report, 0, len(scenarioPaths))
+	for _, scenarioPath := range scenarioPaths {
+		report, err := CompareScenario(scenarioPath, first, second)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// scenarioRun holds the transactions of a scenario executed by a backend,
+// and the error which has stopped the run, if any
+type scenarioRun struct {
+	executions []*txExecution
+	err        error
+}
+
+// runScenario runs the scenario with the given backend; the returned error
+// is only set if the backend could not be set up
+func runScenario(scenarioPath string, backend Backend) (*scenarioRun, error) {
+	executor, err := backend.newTestExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &scenarioRun{
+		executions: make([]*txExecution, 0),
+	}
+	executor.SetResultChecks(false)
+	executor.SetTxStepObserver(func(step *mj.TxStep, output *vmcommon.VMOutput) {
+		run.executions = append(run.executions, &txExecution{
+			txID:     step.TxIdent,
+			vmOutput: output,
+		})
+	})
+
+	runner := mc.NewScenarioRunner(executor, mc.NewDefaultFileResolver())
+	run.err = runner.RunSingleJSONScenario(scenarioPath)
+
+	return run, nil
+}
@@ -0,0 +1,61 @@
+package differential
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminterpreter"
+	gasSchedules "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwenmandos/gasSchedules"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	"github.com/stretchr/testify/require"
+)
+
+const pingPongScenario = "../../test/ping-pong-egld/mandos/ping-pong-call-pong.scen.json"
+
+func TestCompareScenario_SameBackend(t *testing.T) {
+	report, err := CompareScenario(pingPongScenario, Backend{Name: "first"}, Backend{Name: "second"})
+	require.Nil(t, err)
+	require.Nil(t, report.FirstError)
+	require.Nil(t, report.SecondError)
+	require.NotEmpty(t, report.Transactions)
+	require.False(t, report.HasDifferences(), report.String())
+}
+
+func TestCompareScenario_GasSchedules(t *testing.T) {
+	gasScheduleV3, err := gasSchedules.LoadGasScheduleConfig(gasSchedules.GetV3())
+	require.Nil(t, err)
+
+	dummy := Backend{Name: "dummy", GasSchedule: config.MakeGasMapForTests()}
+	v3 := Backend{Name: "v3", GasSchedule: gasScheduleV3}
+	report, err := CompareScenario(pingPongScenario, dummy, v3)
+	require.Nil(t, err)
+	require.True(t, report.HasDifferences())
+
+	gasDifferences := 0
+	for _, transaction := range report.Transactions {
+		for _, difference := range transaction.Differences {
+			if difference.Field == "GasRemaining" {
+				gasDifferences++
+			}
+		}
+	}
+	require.Equal(t, len(report.Transactions), gasDifferences)
+	require.True(t, strings.Contains(report.String(), "GasRemaining"))
+}
+
+func TestCompareScenario_Interpreter(t *testing.T) {
+	interpreter := Backend{Name: "interpreter", InstanceBuilder: wasminterpreter.NewInstanceBuilder()}
+	report, err := CompareScenario(pingPongScenario, Backend{Name: "wasmer"}, interpreter)
+	require.Nil(t, err)
+	require.False(t, report.HasDifferences(), report.String())
+}
+
+func TestCompareScenariosInDirectory(t *testing.T) {
+	reports, err := CompareScenariosInDirectory("../../test/ping-pong-egld/mandos", Backend{Name: "first"}, Backend{Name: "second"})
+	require.Nil(t, err)
+	require.True(t, len(reports) > 1)
+	for _, report := range reports {
+		require.True(t, strings.HasSuffix(report.Source, scenarioSuffix))
+		require.False(t, report.HasDifferences(), report.String())
+	}
+}
@@ -0,0 +1,255 @@
+package differential
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// maxFormattedBytes limits the bytes shown for a value, e.g. a contract code
+const maxFormattedBytes = 64
+
+// Difference is a field of two compared VMOutputs which holds different
+// values; the values are formatted for reading
+type Difference struct {
+	Field  string
+	First  string
+	Second string
+}
+
+// String formats the difference on a single line
+func (difference Difference) String() string {
+	return fmt.Sprintf("%s: %s != %s", difference.Field, difference.First, difference.Second)
+}
+
+// CompareVMOutputs compares two VMOutputs field by field: the return code,
+// message and data, the gas, the output accounts with their storage updates
+// and output transfers, the deleted and touched accounts, and the logs. The
+// differences are returned in a deterministic order.
+func CompareVMOutputs(first *vmcommon.VMOutput, second *vmcommon.VMOutput) []Difference {
+	comparison := &outputComparison{
+		differences: make([]Difference, 0),
+	}
+	comparison.compareVMOutputs(first, second)
+
+	return comparison.differences
+}
+
+type outputComparison struct {
+	differences []Difference
+}
+
+func (comparison *outputComparison) add(field string, first string, second string) {
+	comparison.differences = append(comparison.differences, Difference{
+		Field:  field,
+		First:  first,
+		Second: second,
+	})
+}
+
+func (comparison *outputComparison) compareVMOutputs(first *vmcommon.VMOutput, second *vmcommon.VMOutput) {
+	if first == nil || second == nil {
+		if first != second {
+			comparison.add("VMOutput", describePresence(first != nil), describePresence(second != nil))
+		}
+		return
+	}
+
+	comparison.compareStrings("ReturnCode", first.ReturnCode.String(), second.ReturnCode.String())
+	comparison.compareStrings("ReturnMessage", first.ReturnMessage, second.ReturnMessage)
+	comparison.compareBytesLists("ReturnData", first.ReturnData, second.ReturnData)
+	comparison.compareUint64s("GasRemaining", first.GasRemaining, second.GasRemaining)
+	comparison.compareBigInts("GasRefund", first.GasRefund, second.GasRefund)
+	comparison.compareOutputAccounts(first.OutputAccounts, second.OutputAccounts)
+	comparison.compareAddressSets("DeletedAccounts", first.DeletedAccounts, second.DeletedAccounts)
+	comparison.compareAddressSets("TouchedAccounts", first.TouchedAccounts, second.TouchedAccounts)
+	comparison.compareLogs(first.Logs, second.Logs)
+}
+
+func (comparison *outputComparison) compareOutputAccounts(
+	first map[string]*vmcommon.OutputAccount,
+	second map[string]*vmcommon.OutputAccount,
+) {
+	addresses := make(map[string]struct{})
+	for address := range first {
+		addresses[address] = struct{}{}
+	}
+	for address := range second {
+		addresses[address] = struct{}{}
+	}
+
+	for _, address := range sortedKeys(addresses) {
+		field := fmt.Sprintf("OutputAccounts[%s]", hex.EncodeToString([]byte(address)))
+		firstAccount, firstFound := first[address]
+		secondAccount, secondFound := second[address]
+		if !firstFound || !secondFound {
+			comparison.add(field, describePresence(firstFound), describePresence(secondFound))
+			continue
+		}
+
+		comparison.compareOutputAccount(field, firstAccount, secondAccount)
+	}
+}
+
+func (comparison *outputComparison) compareOutputAccount(
+	field string,
+	first *vmcommon.OutputAccount,
+	second *vmcommon.OutputAccount,
+) {
+	comparison.compareUint64s(field+".Nonce", first.Nonce, second.Nonce)
+	comparison.compareBigInts(field+".Balance", first.Balance, second.Balance)
+	comparison.compareBigInts(field+".BalanceDelta", first.BalanceDelta, second.BalanceDelta)
+	comparison.compareBytes(field+".Code", first.Code, second.Code)
+	comparison.compareBytes(field+".CodeMetadata", first.CodeMetadata, second.CodeMetadata)
+	comparison.compareBytes(field+".CodeDeployerAddress", first.CodeDeployerAddress, second.CodeDeployerAddress)
+	comparison.compareUint64s(field+".GasUsed", first.GasUsed, second.GasUsed)
+	comparison.compareStorageUpdates(field+".StorageUpdates", first.StorageUpdates, second.StorageUpdates)
+	comparison.compareOutputTransfers(field+".OutputTransfers", first.OutputTransfers, second.OutputTransfers)
+}
+
+func (comparison *outputComparison) compareStorageUpdates(
+	field string,
+	first map[string]*vmcommon.StorageUpdate,
+	second map[string]*vmcommon.StorageUpdate,
+) {
+	keys := make(map[string]struct{})
+	for key := range first {
+		keys[key] = struct{}{}
+	}
+	for key := range second {
+		keys[key] = struct{}{}
+	}
+
+	for _, key := range sortedKeys(keys) {
+		keyField := fmt.Sprintf("%s[%s]", field, hex.EncodeToString([]byte(key)))
+		firstUpdate, firstFound := first[key]
+		secondUpdate, secondFound := second[key]
+		if !firstFound || !secondFound {
+			comparison.add(keyField, describePresence(firstFound), describePresence(secondFound))
+			continue
+		}
+
+		comparison.compareBytes(keyField, firstUpdate.Data, secondUpdate.Data)
+	}
+}
+
+func (comparison *outputComparison) compareOutputTransfers(
+	field string,
+	first []vmcommon.OutputTransfer,
+	second []vmcommon.OutputTransfer,
+) {
+	comparison.compareLengths(field, len(first), len(second))
+	for i := 0; i < len(first) && i < len(second); i++ {
+		transferField := fmt.Sprintf("%s[%d]", field, i)
+		comparison.compareBigInts(transferField+".Value", first[i].Value, second[i].Value)
+		comparison.compareUint64s(transferField+".GasLimit", first[i].GasLimit, second[i].GasLimit)
+		comparison.compareUint64s(transferField+".GasLocked", first[i].GasLocked, second[i].GasLocked)
+		comparison.compareBytes(transferField+".Data", first[i].Data, second[i].Data)
+		comparison.compareUint64s(transferField+".CallType", uint64(first[i].CallType), uint64(second[i].CallType))
+		comparison.compareBytes(transferField+".SenderAddress", first[i].SenderAddress, second[i].SenderAddress)
+	}
+}
+
+func (comparison *outputComparison) compareLogs(first []*vmcommon.LogEntry, second []*vmcommon.LogEntry) {
+	comparison.compareLengths("Logs", len(first), len(second))
+	for i := 0; i < len(first) && i < len(second); i++ {
+		field := fmt.Sprintf("Logs[%d]", i)
+		comparison.compareBytes(field+".Identifier", first[i].Identifier, second[i].Identifier)
+		comparison.compareBytes(field+".Address", first[i].Address, second[i].Address)
+		comparison.compareBytesLists(field+".Topics", first[i].Topics, second[i].Topics)
+		comparison.compareBytes(field+".Data", first[i].Data, second[i].Data)
+	}
+}
+
+func (comparison *outputComparison) compareStrings(field string, first string, second string) {
+	if first != second {
+		comparison.add(field, first, second)
+	}
+}
+
+func (comparison *outputComparison) compareUint64s(field string, first uint64, second uint64) {
+	if first != second {
+		comparison.add(field, fmt.Sprintf("%d", first), fmt.Sprintf("%d", second))
+	}
+}
+
+func (comparison *outputComparison) compareLengths(field string, first int, second int) {
+	if first != second {
+		comparison.add(field+".length", fmt.Sprintf("%d", first), fmt.Sprintf("%d", second))
+	}
+}
+
+// compareBigInts compares two values, a nil value being equal to zero
+func (comparison *outputComparison) compareBigInts(field string, first *big.Int, second *big.Int) {
+	firstValue := big.NewInt(0)
+	if first != nil {
+		firstValue = first
+	}
+	secondValue := big.NewInt(0)
+	if second != nil {
+		secondValue = second
+	}
+
+	if firstValue.Cmp(secondValue) != 0 {
+		comparison.add(field, firstValue.String(), secondValue.String())
+	}
+}
+
+func (comparison *outputComparison) compareBytes(field string, first []byte, second []byte) {
+	if !bytes.Equal(first, second) {
+		comparison.add(field, formatBytes(first), formatBytes(second))
+	}
+}
+
+func (comparison *outputComparison) compareBytesLists(field string, first [][]byte, second [][]byte) {
+	comparison.compareLengths(field, len(first), len(second))
+	for i := 0; i < len(first) && i < len(second); i++ {
+		comparison.compareBytes(fmt.Sprintf("%s[%d]", field, i), first[i], second[i])
+	}
+}
+
+// compareAddressSets compares two lists of addresses regardless of their order
+func (comparison *outputComparison) compareAddressSets(field string, first [][]byte, second [][]byte) {
+	comparison.compareStrings(field, formatAddressSet(first), formatAddressSet(second))
+}
+
+func formatBytes(value []byte) string {
+	if len(value) > maxFormattedBytes {
+		return fmt.Sprintf("0x%s... (%d bytes)", hex.EncodeToString(value[:maxFormattedBytes]), len(value))
+	}
+
+	return "0x" + hex.EncodeToString(value)
+}
+
+func formatAddressSet(addresses [][]byte) string {
+	encoded := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		encoded = append(encoded, hex.EncodeToString(address))
+	}
+	sort.Strings(encoded)
+
+	return "[" + strings.Join(encoded, " ") + "]"
+}
+
+func describePresence(present bool) string {
+	if present {
+		return "present"
+	}
+
+	return "absent"
+}
+
+func sortedKeys(keys map[string]struct{}) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
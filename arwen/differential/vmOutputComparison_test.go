@@ -0,0 +1,71 @@
+package differential
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func makeComparedVMOutput() *vmcommon.VMOutput {
+	return &vmcommon.VMOutput{
+		ReturnCode:   vmcommon.Ok,
+		ReturnData:   [][]byte{{1}, {2}},
+		GasRemaining: 1000,
+		GasRefund:    big.NewInt(0),
+		OutputAccounts: map[string]*vmcommon.OutputAccount{
+			"address": {
+				Address:      []byte("address"),
+				BalanceDelta: big.NewInt(10),
+				StorageUpdates: map[string]*vmcommon.StorageUpdate{
+					"key": {Offset: []byte("key"), Data: []byte("value")},
+				},
+				OutputTransfers: []vmcommon.OutputTransfer{
+					{Value: big.NewInt(5), GasLimit: 100, Data: []byte("transfer"), CallType: vm.AsynchronousCall},
+				},
+			},
+		},
+		DeletedAccounts: [][]byte{[]byte("first"), []byte("second")},
+		Logs: []*vmcommon.LogEntry{
+			{Identifier: []byte("event"), Address: []byte("address"), Topics: [][]byte{[]byte("topic")}},
+		},
+	}
+}
+
+func TestCompareVMOutputs_Equal(t *testing.T) {
+	require.Empty(t, CompareVMOutputs(nil, nil))
+	require.Empty(t, CompareVMOutputs(makeComparedVMOutput(), makeComparedVMOutput()))
+
+	second := makeComparedVMOutput()
+	second.GasRefund = nil
+	second.DeletedAccounts = [][]byte{[]byte("second"), []byte("first")}
+	require.Empty(t, CompareVMOutputs(makeComparedVMOutput(), second))
+}
+
+func TestCompareVMOutputs_Absent(t *testing.T) {
+	differences := CompareVMOutputs(makeComparedVMOutput(), nil)
+	require.Equal(t, []Difference{{Field: "VMOutput", First: "present", Second: "absent"}}, differences)
+}
+
+func TestCompareVMOutputs_Differences(t *testing.T) {
+	second := makeComparedVMOutput()
+	second.ReturnData = [][]byte{{1}, {3}}
+	second.GasRemaining = 900
+	account := second.OutputAccounts["address"]
+	account.StorageUpdates["key"].Data = []byte("other")
+	account.StorageUpdates["new"] = &vmcommon.StorageUpdate{Offset: []byte("new"), Data: []byte("value")}
+	account.OutputTransfers[0].GasLimit = 200
+	second.Logs = append(second.Logs, &vmcommon.LogEntry{Identifier: []byte("event")})
+
+	differences := CompareVMOutputs(makeComparedVMOutput(), second)
+	require.Equal(t, []Difference{
+		{Field: "ReturnData[1]", First: "0x02", Second: "0x03"},
+		{Field: "GasRemaining", First: "1000", Second: "900"},
+		{Field: "OutputAccounts[61646472657373].StorageUpdates[6b6579]", First: "0x76616c7565", Second: "0x6f74686572"},
+		{Field: "OutputAccounts[61646472657373].StorageUpdates[6e6577]", First: "absent", Second: "present"},
+		{Field: "OutputAccounts[61646472657373].OutputTransfers[0].GasLimit", First: "100", Second: "200"},
+		{Field: "Logs.length", First: "1", Second: "2"},
+	}, differences)
+}
@@ -63,10 +63,16 @@ package elrondapi
 // extern void		v1_4_asyncCall(void *context, int32_t dstOffset, int32_t valueOffset, int32_t dataOffset, int32_t length);
 // extern void		v1_4_createAsyncCall(void *context, int32_t identifierOffset, int32_t identifierLength, int32_t dstOffset, int32_t valueOffset, int32_t dataOffset, int32_t length, int32_t successCallback, int32_t successLength, int32_t errorCallback, int32_t errorLength, long long gas);
 // extern int32_t	v1_4_setAsyncContextCallback(void *context, int32_t identifierOffset, int32_t identifierLength, int32_t callback, int32_t callbackLength);
+// extern int32_t	v1_4_setAsyncContextExpiry(void *context, int32_t identifierOffset, int32_t identifierLength, long long expiryNonce, long long expiryTimestamp);
+// extern void		v1_4_asyncCallWithClosure(void *context, int32_t dstOffset, int32_t valueOffset, int32_t dataOffset, int32_t length, int32_t closureOffset, int32_t closureLength);
+// extern void		v1_4_createAsyncCallWithClosure(void *context, int32_t identifierOffset, int32_t identifierLength, int32_t dstOffset, int32_t valueOffset, int32_t dataOffset, int32_t length, int32_t successCallback, int32_t successLength, int32_t errorCallback, int32_t errorLength, long long gas, int32_t closureOffset, int32_t closureLength);
+// extern int32_t	v1_4_getCallbackClosureLength(void *context);
+// extern int32_t	v1_4_getCallbackClosure(void *context, int32_t resultOffset);
 //
 // extern int32_t	v1_4_getNumReturnData(void *context);
 // extern int32_t	v1_4_getReturnDataSize(void *context, int32_t resultID);
 // extern int32_t	v1_4_getReturnData(void *context, int32_t resultID, int32_t dataOffset);
+// extern int32_t	v1_4_selfDestruct(void *context, int32_t beneficiaryOffset);
 //
 // extern int32_t	v1_4_setStorageLock(void *context, int32_t keyOffset, int32_t keyLength, long long lockTimestamp);
 // extern long long v1_4_getStorageLock(void *context, int32_t keyOffset, int32_t keyLength);
@@ -190,15 +196,40 @@ func ElrondEIImports() (*wasmer.Imports, error) {
 		return nil, err
 	}
 
-	// imports, err = imports.Append("createAsyncCall", createAsyncCall, C.createAsyncCall)
-	// if err != nil {
-	// 	return nil, err
-	// }
+	imports, err = imports.Append("createAsyncCall", v1_4_createAsyncCall, C.v1_4_createAsyncCall)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = imports.Append("setAsyncContextCallback", v1_4_setAsyncContextCallback, C.v1_4_setAsyncContextCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = imports.Append("setAsyncContextExpiry", v1_4_setAsyncContextExpiry, C.v1_4_setAsyncContextExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = imports.Append("asyncCallWithClosure", v1_4_asyncCallWithClosure, C.v1_4_asyncCallWithClosure)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = imports.Append("createAsyncCallWithClosure", v1_4_createAsyncCallWithClosure, C.v1_4_createAsyncCallWithClosure)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = imports.Append("getCallbackClosureLength", v1_4_getCallbackClosureLength, C.v1_4_getCallbackClosureLength)
+	if err != nil {
+		return nil, err
+	}
 
-	// imports, err = imports.Append("setAsyncContextCallback", setAsyncContextCallback, C.setAsyncContextCallback)
-	// if err != nil {
-	// 	return nil, err
-	// }
+	imports, err = imports.Append("getCallbackClosure", v1_4_getCallbackClosure, C.v1_4_getCallbackClosure)
+	if err != nil {
+		return nil, err
+	}
 
 	imports, err = imports.Append("getArgumentLength", v1_4_getArgumentLength, C.v1_4_getArgumentLength)
 	if err != nil {
@@ -480,6 +511,11 @@ func ElrondEIImports() (*wasmer.Imports, error) {
 		return nil, err
 	}
 
+	imports, err = imports.Append("selfDestruct", v1_4_selfDestruct, C.v1_4_selfDestruct)
+	if err != nil {
+		return nil, err
+	}
+
 	imports, err = imports.Append("getESDTBalance", v1_4_getESDTBalance, C.v1_4_getESDTBalance)
 	if err != nil {
 		return nil, err
@@ -1358,11 +1394,83 @@ func v1_4_createAsyncCall(context unsafe.Pointer,
 	errorOffset int32,
 	errorLength int32,
 	gas int64,
+) {
+	createAsyncCallWithClosure(context,
+		asyncContextIdentifier,
+		identifierLength,
+		destOffset,
+		valueOffset,
+		dataOffset,
+		length,
+		successOffset,
+		successLength,
+		errorOffset,
+		errorLength,
+		gas,
+		nil,
+	)
+}
+
+//export v1_4_createAsyncCallWithClosure
+func v1_4_createAsyncCallWithClosure(context unsafe.Pointer,
+	asyncContextIdentifier int32,
+	identifierLength int32,
+	destOffset int32,
+	valueOffset int32,
+	dataOffset int32,
+	length int32,
+	successOffset int32,
+	successLength int32,
+	errorOffset int32,
+	errorLength int32,
+	gas int64,
+	closureOffset int32,
+	closureLength int32,
+) {
+	callbackClosure, ok := loadCallbackClosure(context, closureOffset, closureLength)
+	if !ok {
+		return
+	}
+
+	createAsyncCallWithClosure(context,
+		asyncContextIdentifier,
+		identifierLength,
+		destOffset,
+		valueOffset,
+		dataOffset,
+		length,
+		successOffset,
+		successLength,
+		errorOffset,
+		errorLength,
+		gas,
+		callbackClosure,
+	)
+}
+
+// createAsyncCallWithClosure adds an async call to an async context; its
+// callbacks will receive the given callback closure
+func createAsyncCallWithClosure(context unsafe.Pointer,
+	asyncContextIdentifier int32,
+	identifierLength int32,
+	destOffset int32,
+	valueOffset int32,
+	dataOffset int32,
+	length int32,
+	successOffset int32,
+	successLength int32,
+	errorOffset int32,
+	errorLength int32,
+	gas int64,
+	callbackClosure []byte,
 ) {
 	host := arwen.GetVMHost(context)
 	runtime := host.Runtime()
+	metering := host.Metering()
 
-	// TODO consume gas
+	gasSchedule := metering.GasSchedule()
+	gasToUse := gasSchedule.ElrondAPICost.AsyncCallStep
+	metering.UseGas(gasToUse)
 
 	acIdentifier, err := runtime.MemLoad(asyncContextIdentifier, identifierLength)
 	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
@@ -1394,6 +1502,17 @@ func v1_4_createAsyncCall(context unsafe.Pointer,
 		return
 	}
 
+	// the gas for the callbacks is locked as for asyncCall(), so that a
+	// callback can still execute when the async call consumes all its gas
+	gasToLock := uint64(0)
+	if len(successFunc) > 0 || len(errorFunc) > 0 {
+		gasToLock = metering.ComputeGasLockedForAsync()
+		err = metering.UseGasBounded(gasToLock)
+		if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+			return
+		}
+	}
+
 	err = runtime.AddAsyncContextCall(acIdentifier, &arwen.AsyncGeneratedCall{
 		Destination:     calledSCAddress,
 		Data:            data,
@@ -1401,6 +1520,8 @@ func v1_4_createAsyncCall(context unsafe.Pointer,
 		SuccessCallback: string(successFunc),
 		ErrorCallback:   string(errorFunc),
 		ProvidedGas:     uint64(gas),
+		GasLocked:       gasToLock,
+		CallbackClosure: callbackClosure,
 	})
 	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
 		return
@@ -1416,8 +1537,10 @@ func v1_4_setAsyncContextCallback(context unsafe.Pointer,
 ) int32 {
 	host := arwen.GetVMHost(context)
 	runtime := host.Runtime()
+	metering := host.Metering()
 
-	// TODO consume gas
+	gasToUse := metering.GasSchedule().ElrondAPICost.AsyncCallStep
+	metering.UseGas(gasToUse)
 
 	acIdentifier, err := runtime.MemLoad(asyncContextIdentifier, identifierLength)
 	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
@@ -1439,6 +1562,44 @@ func v1_4_setAsyncContextCallback(context unsafe.Pointer,
 	return 0
 }
 
+//export v1_4_setAsyncContextExpiry
+func v1_4_setAsyncContextExpiry(context unsafe.Pointer,
+	asyncContextIdentifier int32,
+	identifierLength int32,
+	expiryNonce int64,
+	expiryTimestamp int64,
+) int32 {
+	host := arwen.GetVMHost(context)
+	runtime := host.Runtime()
+	metering := host.Metering()
+
+	gasToUse := metering.GasSchedule().ElrondAPICost.AsyncCallStep
+	metering.UseGas(gasToUse)
+
+	if expiryNonce < 0 || expiryTimestamp < 0 {
+		arwen.WithFault(arwen.ErrArgOutOfRange, context, runtime.ElrondAPIErrorShouldFailExecution())
+		return -1
+	}
+
+	acIdentifier, err := runtime.MemLoad(asyncContextIdentifier, identifierLength)
+	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+		return -1
+	}
+
+	asyncContext, err := runtime.GetAsyncContext(acIdentifier)
+	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+		return -1
+	}
+
+	// the expiry applies to the async calls created so far in the context
+	for _, asyncCall := range asyncContext.AsyncCalls {
+		asyncCall.ExpiryNonce = uint64(expiryNonce)
+		asyncCall.ExpiryTimestamp = uint64(expiryTimestamp)
+	}
+
+	return 0
+}
+
 //export v1_4_upgradeContract
 func v1_4_upgradeContract(
 	context unsafe.Pointer,
@@ -1623,13 +1784,24 @@ func upgradeContract(
 
 //export v1_4_asyncCall
 func v1_4_asyncCall(context unsafe.Pointer, destOffset int32, valueOffset int32, dataOffset int32, length int32) {
+	asyncCallWithClosure(context, destOffset, valueOffset, dataOffset, length, nil)
+}
+
+//export v1_4_asyncCallWithClosure
+func v1_4_asyncCallWithClosure(context unsafe.Pointer, destOffset int32, valueOffset int32, dataOffset int32, length int32, closureOffset int32, closureLength int32) {
+	callbackClosure, ok := loadCallbackClosure(context, closureOffset, closureLength)
+	if !ok {
+		return
+	}
+
+	asyncCallWithClosure(context, destOffset, valueOffset, dataOffset, length, callbackClosure)
+}
+
+// asyncCallWithClosure starts an async call whose callback will receive the
+// given callback closure
+func asyncCallWithClosure(context unsafe.Pointer, destOffset int32, valueOffset int32, dataOffset int32, length int32, callbackClosure []byte) {
 	host := arwen.GetVMHost(context)
 	runtime := host.Runtime()
-	metering := host.Metering()
-
-	gasSchedule := metering.GasSchedule()
-	gasToUse := gasSchedule.ElrondAPICost.AsyncCallStep
-	metering.UseGas(gasToUse)
 
 	calledSCAddress, err := runtime.MemLoad(destOffset, arwen.AddressLen)
 	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
@@ -1641,22 +1813,82 @@ func v1_4_asyncCall(context unsafe.Pointer, destOffset int32, valueOffset int32,
 		return
 	}
 
-	gasToUse = math.MulUint64(gasSchedule.BaseOperationCost.DataCopyPerByte, uint64(length))
-	metering.UseGas(gasToUse)
-
 	data, err := runtime.MemLoad(dataOffset, length)
 	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
 		return
 	}
 
-	err = runtime.ExecuteAsyncCall(calledSCAddress, data, value)
+	AsyncCallWithTypedArgs(host, calledSCAddress, value, data, callbackClosure)
+}
+
+// AsyncCallWithTypedArgs - asyncCallWithClosure with args already read from memory
+func AsyncCallWithTypedArgs(host arwen.VMHost, calledSCAddress []byte, value []byte, data []byte, callbackClosure []byte) {
+	runtime := host.Runtime()
+	metering := host.Metering()
+
+	gasSchedule := metering.GasSchedule()
+	gasToUse := gasSchedule.ElrondAPICost.AsyncCallStep
+	metering.UseGas(gasToUse)
+
+	gasToUse = math.MulUint64(gasSchedule.BaseOperationCost.DataCopyPerByte, uint64(len(data)))
+	metering.UseGas(gasToUse)
+
+	err := runtime.ExecuteAsyncCall(calledSCAddress, data, value)
 	if errors.Is(err, arwen.ErrNotEnoughGas) {
 		runtime.SetRuntimeBreakpointValue(arwen.BreakpointOutOfGas)
 		return
 	}
-	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+	if arwen.WithFaultAndHost(host, err, runtime.ElrondAPIErrorShouldFailExecution()) {
 		return
 	}
+
+	runtime.GetAsyncCallInfo().CallbackClosure = callbackClosure
+}
+
+// loadCallbackClosure loads the callback closure of an async call from the
+// memory of the contract, charging for its copy
+func loadCallbackClosure(context unsafe.Pointer, closureOffset int32, closureLength int32) ([]byte, bool) {
+	runtime := arwen.GetRuntimeContext(context)
+	metering := arwen.GetMeteringContext(context)
+
+	gasToUse := math.MulUint64(metering.GasSchedule().BaseOperationCost.DataCopyPerByte, uint64(closureLength))
+	metering.UseGas(gasToUse)
+
+	callbackClosure, err := runtime.MemLoad(closureOffset, closureLength)
+	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+		return nil, false
+	}
+
+	return callbackClosure, true
+}
+
+//export v1_4_getCallbackClosureLength
+func v1_4_getCallbackClosureLength(context unsafe.Pointer) int32 {
+	runtime := arwen.GetRuntimeContext(context)
+	metering := arwen.GetMeteringContext(context)
+
+	gasToUse := metering.GasSchedule().ElrondAPICost.GetArgument
+	metering.UseGas(gasToUse)
+
+	return int32(len(runtime.GetCallbackClosure()))
+}
+
+//export v1_4_getCallbackClosure
+func v1_4_getCallbackClosure(context unsafe.Pointer, resultOffset int32) int32 {
+	runtime := arwen.GetRuntimeContext(context)
+	metering := arwen.GetMeteringContext(context)
+
+	callbackClosure := runtime.GetCallbackClosure()
+	gasToUse := metering.GasSchedule().ElrondAPICost.GetArgument
+	gasToUse = math.AddUint64(gasToUse, math.MulUint64(metering.GasSchedule().BaseOperationCost.DataCopyPerByte, uint64(len(callbackClosure))))
+	metering.UseGas(gasToUse)
+
+	err := runtime.MemStore(resultOffset, callbackClosure)
+	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+		return -1
+	}
+
+	return int32(len(callbackClosure))
 }
 
 //export v1_4_getArgumentLength
@@ -2816,9 +3048,10 @@ func ExecuteReadOnlyWithTypedArguments(
 		return 1
 	}
 
+	wasReadOnly := runtime.ReadOnly()
 	runtime.SetReadOnly(true)
 	_, err = host.ExecuteOnSameContext(contractCallInput)
-	runtime.SetReadOnly(false)
+	runtime.SetReadOnly(wasReadOnly)
 	if arwen.WithFaultAndHost(host, err, runtime.ElrondAPIErrorShouldFailExecution()) {
 		return 1
 	}
@@ -3058,6 +3291,28 @@ func v1_4_getReturnData(context unsafe.Pointer, resultID int32, dataOffset int32
 	return int32(len(returnData[resultID]))
 }
 
+//export v1_4_selfDestruct
+func v1_4_selfDestruct(context unsafe.Pointer, beneficiaryOffset int32) int32 {
+	runtime := arwen.GetRuntimeContext(context)
+	output := arwen.GetOutputContext(context)
+	metering := arwen.GetMeteringContext(context)
+
+	gasToUse := metering.GasSchedule().ElrondAPICost.SelfDestruct
+	metering.UseGas(gasToUse)
+
+	beneficiary, err := runtime.MemLoad(beneficiaryOffset, arwen.AddressLen)
+	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+		return 1
+	}
+
+	err = output.SelfDestruct(runtime.GetSCAddress(), beneficiary)
+	if arwen.WithFault(err, context, runtime.ElrondAPIErrorShouldFailExecution()) {
+		return 1
+	}
+
+	return 0
+}
+
 //export v1_4_getOriginalTxHash
 func v1_4_getOriginalTxHash(context unsafe.Pointer, dataOffset int32) {
 	runtime := arwen.GetRuntimeContext(context)
@@ -80,9 +80,57 @@ var ErrContractNotFound = fmt.Errorf("%w (not found)", ErrContractInvalid)
 // ErrMemoryDeclarationMissing signals that a memory declaration is missing
 var ErrMemoryDeclarationMissing = fmt.Errorf("%w (missing memory declaration)", ErrContractInvalid)
 
+// ErrFunctionNotEnabled signals that the contract imports an EEI function which is not enabled in the current epoch
+var ErrFunctionNotEnabled = fmt.Errorf("%w (imported function not enabled)", ErrContractInvalid)
+
+// ErrInvalidEEIVersion signals that the contract is bound to an EEI version which is not registered
+var ErrInvalidEEIVersion = fmt.Errorf("%w (invalid EEI version)", ErrContractInvalid)
+
+// ErrMalformedWASMModule signals that the contract code is not a well-formed WASM module
+var ErrMalformedWASMModule = fmt.Errorf("%w (malformed WASM module)", ErrContractInvalid)
+
+// ErrCodeTooLarge signals that the contract code is too large to be inspected before deployment
+var ErrCodeTooLarge = fmt.Errorf("%w (code too large)", ErrContractInvalid)
+
+// ErrUnknownImport signals that the contract imports an entity which the VM does not provide
+var ErrUnknownImport = fmt.Errorf("%w (unknown import)", ErrContractInvalid)
+
+// ErrFloatingPointOpcode signals that the contract code contains floating point instructions
+var ErrFloatingPointOpcode = fmt.Errorf("%w (floating point instructions)", ErrContractInvalid)
+
+// ErrTooManyFunctions signals that the contract defines more functions than allowed
+var ErrTooManyFunctions = fmt.Errorf("%w (too many functions)", ErrContractInvalid)
+
+// ErrTooManyLocals signals that a function of the contract declares more locals than allowed
+var ErrTooManyLocals = fmt.Errorf("%w (too many locals)", ErrContractInvalid)
+
+// ErrInitialMemoryTooLarge signals that the contract declares more initial memory pages than allowed
+var ErrInitialMemoryTooLarge = fmt.Errorf("%w (initial memory too large)", ErrContractInvalid)
+
+// ErrMaximumMemoryTooLarge signals that the contract declares more maximum memory pages than allowed
+var ErrMaximumMemoryTooLarge = fmt.Errorf("%w (maximum memory too large)", ErrContractInvalid)
+
+// ErrTableTooLarge signals that the contract declares a table larger than allowed
+var ErrTableTooLarge = fmt.Errorf("%w (table too large)", ErrContractInvalid)
+
+// ErrDataSegmentTooLarge signals that the contract contains a data segment larger than allowed
+var ErrDataSegmentTooLarge = fmt.Errorf("%w (data segment too large)", ErrContractInvalid)
+
+// ErrTooManyEndpoints signals that the contract exports more functions than allowed
+var ErrTooManyEndpoints = fmt.Errorf("%w (too many exported endpoints)", ErrContractInvalid)
+
+// ErrNilEEIImports signals that nil imports were provided for an EEI version
+var ErrNilEEIImports = errors.New("nil EEI imports")
+
 // ErrMaxInstancesReached signals that the max number of Wasmer instances has been reached.
 var ErrMaxInstancesReached = fmt.Errorf("%w (max instances reached)", ErrExecutionFailed)
 
+// ErrExecutionTimeout signals that the execution was stopped for exceeding its wall-clock budget
+var ErrExecutionTimeout = fmt.Errorf("%w (timeout)", ErrExecutionFailed)
+
+// ErrMemoryLimitExceeded signals that the memory of an instance exceeded its maximum number of pages
+var ErrMemoryLimitExceeded = fmt.Errorf("%w (memory limit exceeded)", ErrExecutionFailed)
+
 // ErrStoreElrondReservedKey signals that an attempt to write under an reserved key has been made
 var ErrStoreElrondReservedKey = errors.New("cannot write to storage under Elrond reserved key")
 
@@ -174,8 +222,47 @@ var ErrNilESDTTransferParser = errors.New("nil esdt transfer parser")
 // ErrNilBuiltInFunctionsContainer signals that nil built in functions container was provided
 var ErrNilBuiltInFunctionsContainer = errors.New("nil built in functions container")
 
+// ErrNilVMHost signals that a nil VM host was provided
+var ErrNilVMHost = errors.New("nil VM host")
+
 // ErrNilBlockChainHook signals that nil blockchain hook was provided
 var ErrNilBlockChainHook = errors.New("nil blockchain hook")
 
 // ErrTooManyESDTTransfers signals that too many ESDT transfers are in sc call
 var ErrTooManyESDTTransfers = errors.New("too many ESDT transfers")
+
+// ErrInvalidNumberOfHosts signals that a host pool was requested with an invalid number of hosts
+var ErrInvalidNumberOfHosts = errors.New("invalid number of hosts")
+
+// ErrUpgradeNotAllowedInQuery signals that a read-only query attempted to upgrade a contract
+var ErrUpgradeNotAllowedInQuery = fmt.Errorf("%w (not allowed in read-only queries)", ErrUpgradeFailed)
+
+// ErrSelfDestructContractOnStack signals that a contract attempted to destroy itself while still executing further down the stack
+var ErrSelfDestructContractOnStack = errors.New("cannot self destruct a contract which is on the stack")
+
+// ErrSelfDestructBeneficiaryIsSelf signals that a contract attempted to destroy itself in its own favour
+var ErrSelfDestructBeneficiaryIsSelf = errors.New("self destruct beneficiary cannot be the destroyed contract")
+
+// ErrReentrancyNotAllowed signals that a reentrancy-protected contract was called while already on the stack
+var ErrReentrancyNotAllowed = fmt.Errorf("%w (reentrancy not allowed)", ErrExecutionFailed)
+
+// ErrCallDepthExceeded signals that a call would nest the executions deeper than the maximum call depth
+var ErrCallDepthExceeded = fmt.Errorf("%w (call depth exceeded)", ErrExecutionFailed)
+
+// ErrGasEstimationFailed signals that the execution does not succeed even with the maximum gas limit
+var ErrGasEstimationFailed = errors.New("gas estimation failed")
+
+// ErrAsyncCallExpired signals that the response of an async call did not arrive before its expiry
+var ErrAsyncCallExpired = errors.New("async call expired")
+
+// ErrNoExpiredAsyncCalls signals that the resolution of expired async calls found none to resolve
+var ErrNoExpiredAsyncCalls = fmt.Errorf("%w (no expired async calls)", ErrInvalidFunction)
+
+// ErrInvalidExpiryResolutionArguments signals that the resolution of expired async calls did not receive the hash of the original transaction
+var ErrInvalidExpiryResolutionArguments = fmt.Errorf("%w (invalid arguments)", ErrNoExpiredAsyncCalls)
+
+// ErrExpiryResolutionNotAllowed signals that the resolution of expired async calls was requested by neither the original caller nor the contract
+var ErrExpiryResolutionNotAllowed = fmt.Errorf("%w (resolution not allowed)", ErrNoExpiredAsyncCalls)
+
+// ErrInvalidCompiledCodeCacheConfig signals that the limits of a compiled code cache are invalid
+var ErrInvalidCompiledCodeCacheConfig = errors.New("invalid compiled code cache config")
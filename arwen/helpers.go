@@ -8,6 +8,7 @@ import (
 	"unsafe"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/crypto"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/math"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
@@ -136,8 +137,15 @@ type nilInterfaceChecker interface {
 
 // GetVMHost returns the vm Context from the vm context map
 func GetVMHost(vmHostPtr unsafe.Pointer) VMHost {
-	instCtx := wasmer.IntoInstanceContext(vmHostPtr)
-	var ptr = *(*uintptr)(instCtx.Data())
+	var dataPtr unsafe.Pointer
+	if executor.IsInstanceContextPointer(vmHostPtr) {
+		dataPtr = executor.IntoInstanceContextProvider(vmHostPtr).GetContextDataPointer()
+	} else {
+		instCtx := wasmer.IntoInstanceContext(vmHostPtr)
+		dataPtr = instCtx.Data()
+	}
+
+	var ptr = *(*uintptr)(dataPtr)
 	return *(*VMHost)(unsafe.Pointer(ptr))
 }
 
@@ -6,8 +6,6 @@ import (
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/contexts"
-	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/cryptoapi"
-	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/elrondapi"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/crypto"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/crypto/factory"
@@ -46,6 +44,24 @@ type vmHost struct {
 	scAPIMethods         *wasmer.Imports
 	builtInFuncContainer vmcommon.BuiltInFunctionContainer
 	esdtTransferParser   vmcommon.ESDTTransferParser
+
+	executionObserver arwen.ExecutionObserver
+	executionDepth    int
+	callTree          *callTreeBuilder
+	callbackClosure   []byte
+	asyncGasReporter  *asyncGasReporter
+	watchdog          *executionWatchdog
+	readOnlyQueries   bool
+	estimating        bool
+
+	reentrancyProtected map[string]struct{}
+	maxCallDepth        int
+	featureFlags        *featureFlags
+	eeiVersions         map[arwen.EEIVersion]*wasmer.Imports
+	legacyEEIVersion    arwen.EEIVersion
+
+	panicReportDirectory string
+	panicReport          *arwen.ExecutionPanicReport
 }
 
 // NewArwenVM creates a new Arwen vmHost
@@ -53,6 +69,18 @@ func NewArwenVM(
 	blockChainHook vmcommon.BlockchainHook,
 	hostParameters *arwen.VMHostParameters,
 ) (arwen.VMHost, error) {
+	host, err := newVMHost(blockChainHook, hostParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+func newVMHost(
+	blockChainHook vmcommon.BlockchainHook,
+	hostParameters *arwen.VMHostParameters,
+) (*vmHost, error) {
 
 	if check.IfNil(blockChainHook) {
 		return nil, arwen.ErrNilBlockChainHook
@@ -79,46 +107,43 @@ func NewArwenVM(
 		scAPIMethods:         nil,
 		builtInFuncContainer: hostParameters.BuiltInFuncContainer,
 		esdtTransferParser:   hostParameters.ESDTTransferParser,
+		executionObserver:    hostParameters.ExecutionObserver,
+		callTree:             newCallTreeBuilder(hostParameters.EnableCallTree),
+		asyncGasReporter:     newAsyncGasReporter(hostParameters.EnableAsyncGasReport),
+		watchdog:             newExecutionWatchdog(hostParameters.ExecutionTimeout),
+		reentrancyProtected:  newReentrancyProtectedSet(hostParameters.ReentrancyProtectedContracts),
+		maxCallDepth:         hostParameters.MaxCallDepth,
+		featureFlags:         newFeatureFlags(hostParameters.FeatureActivationEpochs),
+		eeiVersions:          make(map[arwen.EEIVersion]*wasmer.Imports),
+		legacyEEIVersion:     hostParameters.LegacyEEIVersion,
+		panicReportDirectory: hostParameters.PanicReportDirectory,
 	}
 
-	var err error
-
-	imports, err := elrondapi.ElrondEIImports()
-	if err != nil {
-		return nil, err
-	}
-
-	imports, err = elrondapi.BigIntImports(imports)
-	if err != nil {
-		return nil, err
+	if host.legacyEEIVersion == arwen.EEIVersionUnspecified {
+		host.legacyEEIVersion = arwen.CurrentEEIVersion
 	}
 
-	imports, err = elrondapi.SmallIntImports(imports)
-	if err != nil {
-		return nil, err
+	if !check.IfNil(hostParameters.EpochNotifier) {
+		hostParameters.EpochNotifier.RegisterNotifyHandler(host.featureFlags)
 	}
 
-	imports, err = elrondapi.ManagedBufferImports(imports)
+	err := host.registerDefaultEEIVersions()
 	if err != nil {
 		return nil, err
 	}
 
-	imports, err = cryptoapi.CryptoImports(imports)
+	unlockEEIVersion, err := host.LockEEIVersion(arwen.CurrentEEIVersion)
 	if err != nil {
 		return nil, err
 	}
+	unlockEEIVersion()
 
-	err = wasmer.SetImports(imports)
-	if err != nil {
-		return nil, err
-	}
-
-	host.scAPIMethods = imports
-
-	host.blockchainContext, err = contexts.NewBlockchainContext(host, blockChainHook)
+	blockchainContext, err := contexts.NewBlockchainContext(host, blockChainHook)
 	if err != nil {
 		return nil, err
 	}
+	blockchainContext.SetCompiledCodeCache(hostParameters.CompiledCodeCache)
+	host.blockchainContext = blockchainContext
 
 	host.runtimeContext, err = contexts.NewRuntimeContext(
 		host,
@@ -155,9 +180,12 @@ func NewArwenVM(
 	}
 
 	host.runtimeContext.SetMaxInstanceCount(MaximumWasmerInstanceCount)
+	host.runtimeContext.SetContractComplexityLimits(hostParameters.ContractComplexityLimits)
+	host.runtimeContext.SetMaxMemoryPages(hostParameters.MaxMemoryPagesPerInstance)
 
 	opcodeCosts := gasCostConfig.WASMOpcodeCost.ToOpcodeCostsArray()
 	wasmer.SetOpcodeCosts(&opcodeCosts)
+	host.blockchainContext.UpdateCompiledCodeVersion(&gasCostConfig.WASMOpcodeCost)
 
 	host.initContexts()
 
@@ -227,13 +255,24 @@ func (host *vmHost) InitState() {
 }
 
 func (host *vmHost) initContexts() {
-	host.ClearContextStateStack()
+	// During a gas estimation, the state stacks hold the state saved before
+	// the estimation, which the trial runs must not discard; the runtime
+	// state is saved elsewhere, see isolateEstimation()
+	if host.estimating {
+		host.runtimeContext.ClearStateStack()
+	} else {
+		host.ClearContextStateStack()
+	}
 	host.managedTypesContext.InitState()
 	host.outputContext.InitState()
 	host.meteringContext.InitState()
 	host.runtimeContext.InitState()
 	host.storageContext.InitState()
 	host.ethInput = nil
+	host.executionDepth = 0
+	host.callTree.reset()
+	host.asyncGasReporter.reset()
+	host.callbackClosure = nil
 }
 
 // ClearContextStateStack cleans the state stacks of all the contexts of the host
@@ -250,7 +289,7 @@ func (host *vmHost) Clean() {
 	host.runtimeContext.CleanWasmerInstance()
 }
 
-// GetAPIMethods returns the EEI as a set of imports for Wasmer
+// GetAPIMethods returns the current version of the EEI as a set of imports for Wasmer
 func (host *vmHost) GetAPIMethods() *wasmer.Imports {
 	return host.scAPIMethods
 }
@@ -269,6 +308,7 @@ func (host *vmHost) GasScheduleChange(newGasSchedule config.GasScheduleMap) {
 
 	opcodeCosts := gasCostConfig.WASMOpcodeCost.ToOpcodeCostsArray()
 	wasmer.SetOpcodeCosts(&opcodeCosts)
+	host.blockchainContext.UpdateCompiledCodeVersion(&gasCostConfig.WASMOpcodeCost)
 
 	host.meteringContext.SetGasSchedule(newGasSchedule)
 }
@@ -294,7 +334,7 @@ func (host *vmHost) RunSmartContractCreate(input *vmcommon.ContractCreateInput)
 		log.Error("RunSmartContractCreate", "error", err)
 	}
 
-	TryCatch(try, catch, "arwen.RunSmartContractCreate")
+	host.tryCatch(try, catch, "arwen.RunSmartContractCreate")
 	if vmOutput != nil {
 		log.Trace("RunSmartContractCreate end", "returnCode", vmOutput.ReturnCode, "returnMessage", vmOutput.ReturnMessage)
 	}
@@ -324,9 +364,9 @@ func (host *vmHost) RunSmartContractCall(input *vmcommon.ContractCallInput) (vmO
 
 	isUpgrade := input.Function == arwen.UpgradeFunctionName
 	if isUpgrade {
-		TryCatch(tryUpgrade, catch, "arwen.RunSmartContractUpgrade")
+		host.tryCatch(tryUpgrade, catch, "arwen.RunSmartContractUpgrade")
 	} else {
-		TryCatch(tryCall, catch, "arwen.RunSmartContractCall")
+		host.tryCatch(tryCall, catch, "arwen.RunSmartContractCall")
 	}
 
 	return
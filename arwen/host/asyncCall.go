@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"math/big"
+	"sort"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/math"
@@ -29,6 +30,13 @@ func (host *vmHost) handleAsyncCallBreakpoint() error {
 	log.Trace("async call", "execMode", execMode)
 
 	if execMode == arwen.AsyncUnknown {
+		err = host.saveCrossShardCallbackClosure(asyncCallInfo.Destination, asyncCallInfo.CallbackClosure)
+		if err != nil {
+			log.Trace("async call failed: save callback closure", "error", err)
+			return err
+		}
+
+		host.asyncGasReporter.newEntry("", asyncCallInfo, true)
 		err = host.sendAsyncCallToDestination(asyncCallInfo)
 		if err != nil {
 			log.Trace("async call failed: send cross-shard", "error", err)
@@ -39,7 +47,8 @@ func (host *vmHost) handleAsyncCallBreakpoint() error {
 	// Cross-shard calls for built-in functions must be executed in both the
 	// sender and destination shards.
 	if execMode == arwen.AsyncBuiltinFuncCrossShard {
-		vmOutput, err := host.executeSyncDestinationCall(asyncCallInfo)
+		gasEntry := host.asyncGasReporter.newEntry("", asyncCallInfo, true)
+		vmOutput, err := host.executeSyncDestinationCall(asyncCallInfo, gasEntry)
 		if vmOutput != nil && err != nil {
 			log.Trace("async call failed: sync built-in", "error", err,
 				"retCode", vmOutput.ReturnCode,
@@ -58,13 +67,16 @@ func (host *vmHost) handleAsyncCallBreakpoint() error {
 		// callback, therefore the gas locked for callback execution must be
 		// restored.
 		host.Metering().RestoreGas(asyncCallInfo.GetGasLocked())
+		gasEntry := host.asyncGasReporter.newEntry("", asyncCallInfo, false)
+		gasEntry.GasRefunded = asyncCallInfo.GetGasLocked()
 		return nil
 	}
 
 	// Start calling the destination SC, synchronously.
-	destinationVMOutput, destinationErr := host.executeSyncDestinationCall(asyncCallInfo)
+	gasEntry := host.asyncGasReporter.newEntry("", asyncCallInfo, false)
+	destinationVMOutput, destinationErr := host.executeSyncDestinationCall(asyncCallInfo, gasEntry)
 
-	callbackVMOutput, callBackErr := host.executeSyncCallbackCall(asyncCallInfo, destinationVMOutput, destinationErr)
+	callbackVMOutput, callBackErr := host.executeSyncCallbackCall(asyncCallInfo, destinationVMOutput, destinationErr, gasEntry)
 
 	err = host.processCallbackVMOutput(callbackVMOutput, callBackErr)
 	if err != nil {
@@ -139,7 +151,10 @@ func (host *vmHost) determineAsyncCallExecutionMode(asyncCallInfo *arwen.AsyncCa
 	return arwen.AsyncUnknown, nil
 }
 
-func (host *vmHost) executeSyncDestinationCall(asyncCallInfo arwen.AsyncCallInfoHandler) (*vmcommon.VMOutput, error) {
+func (host *vmHost) executeSyncDestinationCall(
+	asyncCallInfo arwen.AsyncCallInfoHandler,
+	gasEntry *arwen.AsyncCallGasEntry,
+) (*vmcommon.VMOutput, error) {
 	destinationCallInput, err := host.createDestinationContractCallInput(asyncCallInfo)
 	if err != nil {
 		log.Trace("async call: sync dest call failed", "error", err)
@@ -153,6 +168,7 @@ func (host *vmHost) executeSyncDestinationCall(asyncCallInfo arwen.AsyncCallInfo
 		"args", destinationCallInput.Arguments)
 
 	destinationVMOutput, _, err := host.ExecuteOnDestContext(destinationCallInput)
+	recordDestinationGas(gasEntry, destinationCallInput, destinationVMOutput)
 	if destinationVMOutput != nil {
 		log.Trace("async call: sync dest call",
 			"retCode", destinationVMOutput.ReturnCode,
@@ -168,6 +184,7 @@ func (host *vmHost) executeSyncCallbackCall(
 	asyncCallInfo arwen.AsyncCallInfoHandler,
 	destinationVMOutput *vmcommon.VMOutput,
 	destinationErr error,
+	gasEntry *arwen.AsyncCallGasEntry,
 ) (*vmcommon.VMOutput, error) {
 	callbackCallInput, err := host.createCallbackContractCallInput(
 		asyncCallInfo,
@@ -178,6 +195,7 @@ func (host *vmHost) executeSyncCallbackCall(
 	)
 	if err != nil {
 		log.Trace("async call: sync callback failed", "error", err)
+		recordCallbackGas(gasEntry, nil, nil)
 		return nil, err
 	}
 
@@ -191,7 +209,8 @@ func (host *vmHost) executeSyncCallbackCall(
 	// locked gas will appear to have been used twice by the caller instance.
 	host.Metering().RestoreGas(asyncCallInfo.GetGasLocked())
 
-	callbackVMOutput, _, callBackErr := host.ExecuteOnDestContext(callbackCallInput)
+	callbackVMOutput, _, callBackErr := host.executeCallbackOnDestContext(callbackCallInput, asyncCallInfo.GetCallbackClosure())
+	recordCallbackGas(gasEntry, callbackCallInput, callbackVMOutput)
 	if callbackVMOutput != nil {
 		log.Trace("async call: sync callback call",
 			"retCode", callbackVMOutput.ReturnCode,
@@ -244,18 +263,13 @@ func (host *vmHost) sendCallbackToCurrentCaller() error {
 	metering := host.Metering()
 	currentCall := runtime.GetVMInput()
 
-	retData := []byte("@" + hex.EncodeToString([]byte(output.ReturnCode().String())))
-	for _, data := range output.ReturnData() {
-		retData = append(retData, []byte("@"+hex.EncodeToString(data))...)
-	}
-
 	err := output.Transfer(
 		currentCall.CallerAddr,
 		runtime.GetSCAddress(),
 		metering.GasLeft(),
 		0,
 		currentCall.CallValue,
-		retData,
+		host.createCallbackData(),
 		vm.AsynchronousCallBack,
 	)
 	if err != nil {
@@ -269,6 +283,19 @@ func (host *vmHost) sendCallbackToCurrentCaller() error {
 	return nil
 }
 
+// createCallbackData encodes the return code and the return data of the
+// current contract as the data of a callback to its caller
+func (host *vmHost) createCallbackData() []byte {
+	output := host.Output()
+
+	callbackData := []byte("@" + hex.EncodeToString([]byte(output.ReturnCode().String())))
+	for _, data := range output.ReturnData() {
+		callbackData = append(callbackData, []byte("@"+hex.EncodeToString(data))...)
+	}
+
+	return callbackData
+}
+
 func (host *vmHost) sendStorageCallbackToDestination(callerAddress, returnData []byte) error {
 	runtime := host.Runtime()
 	output := host.Output()
@@ -475,25 +502,39 @@ func (host *vmHost) processAsyncInfo(asyncInfo *arwen.AsyncContextInfo) (*arwen.
 		return nil, err
 	}
 
-	for _, asyncContext := range asyncInfo.AsyncContextMap {
-		for _, asyncCall := range asyncContext.AsyncCalls {
+	for _, contextIdentifier := range sortedAsyncContextIdentifiers(asyncInfo) {
+		for _, asyncCall := range asyncInfo.AsyncContextMap[contextIdentifier].AsyncCalls {
 			if !host.canExecuteSynchronously(asyncCall.Destination, asyncCall.Data) {
 				continue
 			}
 
-			procErr := host.processAsyncCall(asyncCall)
+			procErr := host.processAsyncCall(contextIdentifier, asyncCall)
 			if procErr != nil {
 				return nil, procErr
 			}
 		}
 	}
 
+	for _, contextIdentifier := range sortedAsyncContextIdentifiers(asyncInfo) {
+		asyncContext := asyncInfo.AsyncContextMap[contextIdentifier]
+		if !isAsyncContextComplete(asyncContext) {
+			continue
+		}
+
+		err = host.callbackAsyncContext(contextIdentifier, asyncContext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	pendingMapInfo := host.getPendingAsyncCalls(asyncInfo)
 	if len(pendingMapInfo.AsyncContextMap) == 0 {
 		return pendingMapInfo, nil
 	}
 
-	err = host.savePendingAsyncCalls(pendingMapInfo)
+	incompleteMapInfo := host.getIncompleteAsyncContexts(asyncInfo)
+	incompleteMapInfo.ReturnData = host.createCallbackData()
+	err = host.savePendingAsyncCalls(incompleteMapInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -503,13 +544,12 @@ func (host *vmHost) processAsyncInfo(asyncInfo *arwen.AsyncContextInfo) (*arwen.
 		return nil, err
 	}
 
-	for _, asyncContext := range pendingMapInfo.AsyncContextMap {
-		for _, asyncCall := range asyncContext.AsyncCalls {
-			if !host.canExecuteSynchronously(asyncCall.Destination, asyncCall.Data) {
-				sendErr := host.sendAsyncCallToDestination(asyncCall)
-				if sendErr != nil {
-					return nil, sendErr
-				}
+	for _, entry := range asyncCallsInCreationOrder(pendingMapInfo) {
+		if !host.canExecuteSynchronously(entry.asyncCall.Destination, entry.asyncCall.Data) {
+			host.asyncGasReporter.newEntry(entry.contextIdentifier, entry.asyncCall, true)
+			sendErr := host.sendAsyncCallToDestination(entry.asyncCall)
+			if sendErr != nil {
+				return nil, sendErr
 			}
 		}
 	}
@@ -520,13 +560,15 @@ func (host *vmHost) processAsyncInfo(asyncInfo *arwen.AsyncContextInfo) (*arwen.
 /**
  * processAsyncCall executes an async call and processes the callback if no extra calls are pending
  */
-func (host *vmHost) processAsyncCall(asyncCall *arwen.AsyncGeneratedCall) error {
+func (host *vmHost) processAsyncCall(contextIdentifier string, asyncCall *arwen.AsyncGeneratedCall) error {
+	gasEntry := host.asyncGasReporter.newEntry(contextIdentifier, asyncCall, false)
 	input, _ := host.createDestinationContractCallInput(asyncCall)
 	output, asyncMap, executionError := host.ExecuteOnDestContext(input)
+	recordDestinationGas(gasEntry, input, output)
 
 	pendingMap := host.getPendingAsyncCalls(asyncMap)
 	if len(pendingMap.AsyncContextMap) == 0 {
-		return host.callbackAsync(asyncCall, output, executionError)
+		return host.callbackAsync(asyncCall, output, executionError, gasEntry)
 	}
 
 	return executionError
@@ -535,7 +577,12 @@ func (host *vmHost) processAsyncCall(asyncCall *arwen.AsyncGeneratedCall) error
 /**
  * callbackAsync will execute a callback from an async call that was ran on this host and set it's status to resolved or rejected
  */
-func (host *vmHost) callbackAsync(asyncCall *arwen.AsyncGeneratedCall, vmOutput *vmcommon.VMOutput, executionError error) error {
+func (host *vmHost) callbackAsync(
+	asyncCall *arwen.AsyncGeneratedCall,
+	vmOutput *vmcommon.VMOutput,
+	executionError error,
+	gasEntry *arwen.AsyncCallGasEntry,
+) error {
 	asyncCall.Status = arwen.AsyncCallResolved
 	callbackFunction := asyncCall.SuccessCallback
 	if vmOutput.ReturnCode != vmcommon.Ok {
@@ -551,11 +598,17 @@ func (host *vmHost) callbackAsync(asyncCall *arwen.AsyncGeneratedCall, vmOutput
 		executionError,
 	)
 	if err != nil {
+		recordCallbackGas(gasEntry, nil, nil)
 		return err
 	}
 
+	// Restore gas locked while still on the caller instance; otherwise, the
+	// locked gas will appear to have been used twice by the caller instance.
+	host.Metering().RestoreGas(asyncCall.GetGasLocked())
+
 	// Callback omits for now any async call - TODO: take into consideration async calls generated from callbacks
-	callbackVMOutput, _, callBackErr := host.ExecuteOnDestContext(callbackCallInput)
+	callbackVMOutput, _, callBackErr := host.executeCallbackOnDestContext(callbackCallInput, asyncCall.CallbackClosure)
+	recordCallbackGas(gasEntry, callbackCallInput, callbackVMOutput)
 	err = host.processCallbackVMOutput(callbackVMOutput, callBackErr)
 	if err != nil {
 		return err
@@ -564,6 +617,39 @@ func (host *vmHost) callbackAsync(asyncCall *arwen.AsyncGeneratedCall, vmOutput
 	return nil
 }
 
+/**
+ * callbackAsyncContext executes the callback of an async context, once all of its async calls have been resolved or
+ *  rejected. The callback receives the identifier of the async context as its only argument.
+ */
+func (host *vmHost) callbackAsyncContext(contextIdentifier string, asyncContext *arwen.AsyncContext) error {
+	if len(asyncContext.Callback) == 0 {
+		return nil
+	}
+
+	runtime := host.Runtime()
+	metering := host.Metering()
+
+	callbackCallInput := &vmcommon.ContractCallInput{
+		VMInput: vmcommon.VMInput{
+			CallerAddr:     runtime.GetSCAddress(),
+			Arguments:      [][]byte{[]byte(contextIdentifier)},
+			CallValue:      big.NewInt(0),
+			CallType:       vm.AsynchronousCallBack,
+			GasPrice:       runtime.GetVMInput().GasPrice,
+			GasProvided:    metering.GasLeft(),
+			CurrentTxHash:  runtime.GetCurrentTxHash(),
+			OriginalTxHash: runtime.GetOriginalTxHash(),
+		},
+		RecipientAddr: runtime.GetSCAddress(),
+		Function:      asyncContext.Callback,
+	}
+
+	log.Trace("async context callback", "context", contextIdentifier, "func", asyncContext.Callback)
+
+	callbackVMOutput, _, callBackErr := host.ExecuteOnDestContext(callbackCallInput)
+	return host.processCallbackVMOutput(callbackVMOutput, callBackErr)
+}
+
 /**
  * savePendingAsyncCalls takes a list of pending async calls and save them to storage so the info will be available on callback
  */
@@ -572,16 +658,18 @@ func (host *vmHost) savePendingAsyncCalls(pendingAsyncMap *arwen.AsyncContextInf
 		return nil
 	}
 
-	storage := host.Storage()
 	runtime := host.Runtime()
-
 	asyncCallStorageKey := arwen.CustomStorageKey(arwen.AsyncDataPrefix, runtime.GetOriginalTxHash())
-	data, err := json.Marshal(pendingAsyncMap)
+	return host.saveAsyncContextInfo(asyncCallStorageKey, pendingAsyncMap)
+}
+
+func (host *vmHost) saveAsyncContextInfo(storageKey []byte, asyncInfo *arwen.AsyncContextInfo) error {
+	data, err := json.Marshal(asyncInfo)
 	if err != nil {
 		return err
 	}
 
-	_, err = storage.SetProtectedStorage(asyncCallStorageKey, data)
+	_, err = host.Storage().SetProtectedStorage(storageKey, data)
 	if err != nil {
 		return err
 	}
@@ -626,6 +714,7 @@ func (host *vmHost) saveCrossShardCalls(asyncInfo *arwen.AsyncContextInfo) error
 func (host *vmHost) getPendingAsyncCalls(asyncInfo *arwen.AsyncContextInfo) *arwen.AsyncContextInfo {
 	pendingMap := &arwen.AsyncContextInfo{
 		CallerAddr:      asyncInfo.CallerAddr,
+		CallType:        asyncInfo.CallType,
 		ReturnData:      asyncInfo.ReturnData,
 		AsyncContextMap: make(map[string]*arwen.AsyncContext),
 	}
@@ -653,11 +742,32 @@ func (host *vmHost) getPendingAsyncCalls(asyncInfo *arwen.AsyncContextInfo) *arw
 	return pendingMap
 }
 
+/**
+ * getIncompleteAsyncContexts returns the async contexts which still have pending async calls, together with the
+ *  async calls of these contexts which were already resolved or rejected
+ */
+func (host *vmHost) getIncompleteAsyncContexts(asyncInfo *arwen.AsyncContextInfo) *arwen.AsyncContextInfo {
+	incompleteMap := &arwen.AsyncContextInfo{
+		CallerAddr:      asyncInfo.CallerAddr,
+		CallType:        asyncInfo.CallType,
+		ReturnData:      asyncInfo.ReturnData,
+		AsyncContextMap: make(map[string]*arwen.AsyncContext),
+	}
+
+	for contextIdentifier, asyncContext := range asyncInfo.AsyncContextMap {
+		if !isAsyncContextComplete(asyncContext) {
+			incompleteMap.AsyncContextMap[contextIdentifier] = asyncContext
+		}
+	}
+
+	return incompleteMap
+}
+
 /**
  * processCallbackStack is triggered when a callback was received from another host through a transaction.
  *  It will return an error if we receive a callback and we don't have it's associated data in the storage.
- *  If the associated callback was found in the pending set, it will be removed - It should not be executed
- *   again since it was executed in the callSCMethod step
+ *  The async call which the callback belongs to is marked as resolved or rejected and, once all the async calls
+ *  of its context are done, the context is finished.
  */
 func (host *vmHost) processCallbackStack() error {
 	runtime := host.Runtime()
@@ -676,80 +786,102 @@ func (host *vmHost) processCallbackStack() error {
 	}
 
 	vmInput := runtime.GetVMInput()
-	var asyncCallPosition int
-	var currentContextIdentifier string
-	for contextIdentifier, asyncContext := range asyncInfo.AsyncContextMap {
-		for position, asyncCall := range asyncContext.AsyncCalls {
-			if bytes.Equal(vmInput.CallerAddr, asyncCall.Destination) {
-				asyncCallPosition = position
-				currentContextIdentifier = contextIdentifier
-				break
-			}
-		}
-
-		if len(currentContextIdentifier) > 0 {
-			break
-		}
+	contextIdentifier, asyncCall := findPendingAsyncCall(asyncInfo, vmInput.CallerAddr)
+	if asyncCall == nil {
+		return arwen.ErrCallBackFuncNotExpected
 	}
 
-	if len(currentContextIdentifier) == 0 {
-		return arwen.ErrCallBackFuncNotExpected
+	asyncCall.Status = arwen.AsyncCallRejected
+	if isAsyncCallbackSuccessful(vmInput.Arguments) {
+		asyncCall.Status = arwen.AsyncCallResolved
 	}
 
-	// Remove current async call from the pending list
-	currentContextCalls := asyncInfo.AsyncContextMap[currentContextIdentifier].AsyncCalls
-	contextCallId := len(currentContextCalls) - 1
-	if contextCallId >= 0 {
-		currentContextCalls[asyncCallPosition] = currentContextCalls[contextCallId]
-		currentContextCalls[contextCallId] = nil
-		currentContextCalls = currentContextCalls[:contextCallId]
+	// If we are still waiting for callbacks in the current context, we return
+	asyncContext := asyncInfo.AsyncContextMap[contextIdentifier]
+	if !isAsyncContextComplete(asyncContext) {
+		return host.saveAsyncContextInfo(storageKey, asyncInfo)
 	}
 
-	if len(currentContextCalls) == 0 {
-		// call OUR callback for resolving a full context
-		delete(asyncInfo.AsyncContextMap, currentContextIdentifier)
+	return host.finishAsyncContexts(storageKey, asyncInfo, []string{contextIdentifier})
+}
+
+/**
+ * finishAsyncContexts removes the given async contexts, which have no more pending async calls, from the stored
+ *  data and executes their callbacks. The stored data is updated before calling OUR callbacks, which may in turn
+ *  read it. When no async context remains, the stored data is removed and, if the contract was itself called
+ *  asynchronously, its caller is finally called back.
+ */
+func (host *vmHost) finishAsyncContexts(storageKey []byte, asyncInfo *arwen.AsyncContextInfo, contextIdentifiers []string) error {
+	finishedContexts := make([]*arwen.AsyncContext, len(contextIdentifiers))
+	for index, contextIdentifier := range contextIdentifiers {
+		finishedContexts[index] = asyncInfo.AsyncContextMap[contextIdentifier]
+		delete(asyncInfo.AsyncContextMap, contextIdentifier)
 	}
 
-	// If we are still waiting for callbacks we return
+	var err error
 	if len(asyncInfo.AsyncContextMap) > 0 {
-		return nil
+		err = host.saveAsyncContextInfo(storageKey, asyncInfo)
+	} else {
+		_, err = host.Storage().SetProtectedStorage(storageKey, nil)
 	}
-
-	_, err = storage.SetProtectedStorage(storageKey, nil)
 	if err != nil {
 		return err
 	}
 
-	// Now figure out if we can execute the callback here or different shard
-	if !host.canExecuteSynchronously(asyncInfo.CallerAddr, asyncInfo.ReturnData) {
-		err = host.sendStorageCallbackToDestination(asyncInfo.CallerAddr, asyncInfo.ReturnData)
+	for index, contextIdentifier := range contextIdentifiers {
+		err = host.callbackAsyncContext(contextIdentifier, finishedContexts[index])
 		if err != nil {
 			return err
 		}
+	}
 
+	if len(asyncInfo.AsyncContextMap) > 0 || asyncInfo.CallType != vm.AsynchronousCall {
 		return nil
 	}
 
-	// The caller is in the same shard, execute it's callback
-	// TODO nil pointer exception warning: must refactor
-	callbackCallInput, err := host.createCallbackContractCallInput(
-		nil,
-		host.Output().GetVMOutput(),
-		asyncInfo.CallerAddr,
-		arwen.CallbackFunctionName,
-		nil,
-	)
-	if err != nil {
-		return err
+	// Now figure out if we can execute the callback of the caller here or in a different shard
+	if !host.canExecuteSynchronously(asyncInfo.CallerAddr, asyncInfo.ReturnData) {
+		return host.sendStorageCallbackToDestination(asyncInfo.CallerAddr, asyncInfo.ReturnData)
 	}
 
-	callbackVMOutput, _, callBackErr := host.ExecuteOnDestContext(callbackCallInput)
-	err = host.processCallbackVMOutput(callbackVMOutput, callBackErr)
+	return host.callbackCallerInShard(asyncInfo)
+}
+
+/**
+ * callbackCallerInShard executes the callback of a caller from the same shard, which has called the current contract
+ *  asynchronously and is still waiting for its async contexts to finish. The callback receives the return code and
+ *  the return data of the contract from the time it was called, like a callback sent to a different shard.
+ */
+func (host *vmHost) callbackCallerInShard(asyncInfo *arwen.AsyncContextInfo) error {
+	runtime := host.Runtime()
+	metering := host.Metering()
+
+	argParser := parsers.NewCallArgsParser()
+	_, arguments, err := argParser.ParseData(arwen.CallbackFunctionName + string(asyncInfo.ReturnData))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	callbackCallInput := &vmcommon.ContractCallInput{
+		VMInput: vmcommon.VMInput{
+			CallerAddr:           runtime.GetSCAddress(),
+			Arguments:            arguments,
+			CallValue:            big.NewInt(0),
+			CallType:             vm.AsynchronousCallBack,
+			GasPrice:             runtime.GetVMInput().GasPrice,
+			GasProvided:          metering.GasLeft(),
+			CurrentTxHash:        runtime.GetCurrentTxHash(),
+			OriginalTxHash:       runtime.GetOriginalTxHash(),
+			ReturnCallAfterError: !isAsyncCallbackSuccessful(arguments),
+		},
+		RecipientAddr: asyncInfo.CallerAddr,
+		Function:      arwen.CallbackFunctionName,
+	}
+
+	log.Trace("async context: in-shard callback of the caller", "caller", asyncInfo.CallerAddr)
+
+	callbackVMOutput, _, callBackErr := host.ExecuteOnDestContext(callbackCallInput)
+	return host.processCallbackVMOutput(callbackVMOutput, callBackErr)
 }
 
 /**
@@ -816,24 +948,23 @@ func (host *vmHost) getFunctionByCallType(callType vm.CallType) (wasmer.Exported
 	}
 
 	vmInput := runtime.GetVMInput()
-
-	customCallback := false
-	for _, asyncContext := range asyncInfo.AsyncContextMap {
-		for _, asyncCall := range asyncContext.AsyncCalls {
-			if bytes.Equal(vmInput.CallerAddr, asyncCall.Destination) {
-				customCallback = true
-				runtime.SetCustomCallFunction(asyncCall.SuccessCallback)
-				break
-			}
+	_, asyncCall := findPendingAsyncCall(asyncInfo, vmInput.CallerAddr)
+	if asyncCall != nil {
+		callbackFunction := asyncCall.ErrorCallback
+		if isAsyncCallbackSuccessful(vmInput.Arguments) {
+			callbackFunction = asyncCall.SuccessCallback
 		}
-
-		if customCallback {
-			break
+		runtime.SetCustomCallFunction(callbackFunction)
+		runtime.SetCallbackClosure(asyncCall.CallbackClosure)
+	} else {
+		err = host.loadCrossShardCallbackClosure(vmInput.CallerAddr)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	function, err := runtime.GetFunctionToCall()
-	if err != nil && !customCallback {
+	if err != nil {
 		log.Trace("get function by call type", "error", arwen.ErrNilCallbackFunction)
 		return nil, arwen.ErrNilCallbackFunction
 	}
@@ -859,3 +990,86 @@ func (host *vmHost) getCurrentAsyncInfo() (*arwen.AsyncContextInfo, error) {
 
 	return asyncInfo, nil
 }
+
+// sortedAsyncContextIdentifiers returns the identifiers of the async contexts
+// in a deterministic order, in which their async calls are to be processed
+func sortedAsyncContextIdentifiers(asyncInfo *arwen.AsyncContextInfo) []string {
+	identifiers := make([]string, 0, len(asyncInfo.AsyncContextMap))
+	for contextIdentifier := range asyncInfo.AsyncContextMap {
+		identifiers = append(identifiers, contextIdentifier)
+	}
+	sort.Strings(identifiers)
+
+	return identifiers
+}
+
+// contextAsyncCall is an async call together with the identifier of its
+// async context
+type contextAsyncCall struct {
+	contextIdentifier string
+	asyncCall         *arwen.AsyncGeneratedCall
+}
+
+// asyncCallsInCreationOrder returns the async calls of all the async contexts
+// in the order in which they were created, regardless of their contexts
+func asyncCallsInCreationOrder(asyncInfo *arwen.AsyncContextInfo) []*contextAsyncCall {
+	asyncCalls := make([]*contextAsyncCall, 0)
+	for _, contextIdentifier := range sortedAsyncContextIdentifiers(asyncInfo) {
+		for _, asyncCall := range asyncInfo.AsyncContextMap[contextIdentifier].AsyncCalls {
+			asyncCalls = append(asyncCalls, &contextAsyncCall{
+				contextIdentifier: contextIdentifier,
+				asyncCall:         asyncCall,
+			})
+		}
+	}
+
+	sort.SliceStable(asyncCalls, func(i, j int) bool {
+		return asyncCalls[i].asyncCall.Sequence < asyncCalls[j].asyncCall.Sequence
+	})
+
+	return asyncCalls
+}
+
+// findPendingAsyncCall returns the earliest created pending async call sent
+// to the given destination, together with the identifier of its async
+// context; callbacks carry no identifier of their own, so the async calls
+// sent to the same destination, which are also sent in the order of their
+// creation, are resolved in that order
+func findPendingAsyncCall(asyncInfo *arwen.AsyncContextInfo, destination []byte) (string, *arwen.AsyncGeneratedCall) {
+	for _, entry := range asyncCallsInCreationOrder(asyncInfo) {
+		if entry.asyncCall.Status == arwen.AsyncCallPending && bytes.Equal(destination, entry.asyncCall.Destination) {
+			return entry.contextIdentifier, entry.asyncCall
+		}
+	}
+
+	return "", nil
+}
+
+// isAsyncContextComplete returns true if all the async calls of the context
+// have been resolved or rejected
+func isAsyncContextComplete(asyncContext *arwen.AsyncContext) bool {
+	for _, asyncCall := range asyncContext.AsyncCalls {
+		if asyncCall.Status == arwen.AsyncCallPending {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isAsyncCallbackSuccessful interprets the return code received as the first
+// argument of a callback, which is either the numeric value of the return code
+// (for callbacks executed in the same shard) or its name (for callbacks
+// received from another shard)
+func isAsyncCallbackSuccessful(arguments [][]byte) bool {
+	if len(arguments) == 0 {
+		return true
+	}
+
+	returnCode := arguments[0]
+	if string(returnCode) == vmcommon.Ok.String() {
+		return true
+	}
+
+	return big.NewInt(0).SetBytes(returnCode).Cmp(arwen.Zero) == 0
+}
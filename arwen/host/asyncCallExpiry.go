@@ -0,0 +1,154 @@
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	"github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// isExpiredAsyncCallsResolution returns true if the current call asks the
+// host to resolve the expired async calls of the contract, instead of calling
+// the function of the contract with the given name; the name is reserved by
+// the AsyncCallExpiryFeature, so only the contracts deployed before its
+// activation may export it, and their function is called as before
+func (host *vmHost) isExpiredAsyncCallsResolution(functionName string) bool {
+	runtime := host.Runtime()
+	if functionName != arwen.ResolveExpiredAsyncCallsFunctionName {
+		return false
+	}
+	if runtime.GetVMInput().CallType != vm.DirectCall {
+		return false
+	}
+	if !host.IsFeatureEnabled(arwen.AsyncCallExpiryFeature) {
+		return false
+	}
+
+	_, isExported := runtime.GetInstanceExports()[functionName]
+	return !isExported
+}
+
+/**
+ * resolveExpiredAsyncCalls is triggered by a transaction which calls the resolveExpiredAsyncCalls function of a
+ *  contract, passing the hash of the original transaction which created its async calls. Each pending async call
+ *  whose expiry was reached is marked as expired and its error callback is executed with the AsyncCallTimeout
+ *  code, as if a failed response had arrived. The async contexts left without pending async calls are finished.
+ *  A response arriving after the expiry no longer matches a pending async call and is rejected. Only the caller of
+ *  the original transaction and the contract itself may resolve the expired async calls. The gas which they have
+ *  locked for their callbacks is not refunded, since it was paid by the original transaction.
+ */
+func (host *vmHost) resolveExpiredAsyncCalls() error {
+	runtime := host.Runtime()
+	blockchain := host.Blockchain()
+
+	arguments := runtime.Arguments()
+	if len(arguments) != 1 {
+		return arwen.ErrInvalidExpiryResolutionArguments
+	}
+
+	storageKey := arwen.CustomStorageKey(arwen.AsyncDataPrefix, arguments[0])
+	buff := host.Storage().GetStorageUnmetered(storageKey)
+	if len(buff) == 0 {
+		return arwen.ErrNoExpiredAsyncCalls
+	}
+
+	asyncInfo := &arwen.AsyncContextInfo{}
+	err := json.Unmarshal(buff, &asyncInfo)
+	if err != nil {
+		return err
+	}
+
+	caller := runtime.GetVMInput().CallerAddr
+	if !bytes.Equal(caller, asyncInfo.CallerAddr) && !bytes.Equal(caller, runtime.GetSCAddress()) {
+		return arwen.ErrExpiryResolutionNotAllowed
+	}
+
+	nonce := blockchain.CurrentNonce()
+	timestamp := blockchain.CurrentTimeStamp()
+	expiredAsyncCalls := make([]*arwen.AsyncGeneratedCall, 0)
+	finishedContexts := make([]string, 0)
+	for _, contextIdentifier := range sortedAsyncContextIdentifiers(asyncInfo) {
+		asyncContext := asyncInfo.AsyncContextMap[contextIdentifier]
+		for _, asyncCall := range asyncContext.AsyncCalls {
+			if asyncCall.Status == arwen.AsyncCallPending && asyncCall.IsExpired(nonce, timestamp) {
+				asyncCall.Status = arwen.AsyncCallExpired
+				expiredAsyncCalls = append(expiredAsyncCalls, asyncCall)
+			}
+		}
+
+		if isAsyncContextComplete(asyncContext) {
+			finishedContexts = append(finishedContexts, contextIdentifier)
+		}
+	}
+
+	if len(expiredAsyncCalls) == 0 {
+		return arwen.ErrNoExpiredAsyncCalls
+	}
+
+	log.Trace("resolve expired async calls", "expired", len(expiredAsyncCalls), "finished contexts", len(finishedContexts))
+
+	// The expired async calls are stored before their callbacks are executed,
+	// so that a callback cannot resolve them again
+	err = host.saveAsyncContextInfo(storageKey, asyncInfo)
+	if err != nil {
+		return err
+	}
+
+	originalTxHash := arguments[0]
+	for _, asyncCall := range expiredAsyncCalls {
+		err = host.callbackExpiredAsyncCall(asyncCall, originalTxHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(finishedContexts) > 0 {
+		err = host.finishAsyncContexts(storageKey, asyncInfo, finishedContexts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+ * callbackExpiredAsyncCall executes the error callback of an expired async call, with the arguments of a failed
+ *  response: the AsyncCallTimeout code and the message of ErrAsyncCallExpired. The callback belongs to the original
+ *  transaction which created the async call, instead of the transaction resolving it.
+ */
+func (host *vmHost) callbackExpiredAsyncCall(asyncCall *arwen.AsyncGeneratedCall, originalTxHash []byte) error {
+	if len(asyncCall.ErrorCallback) == 0 {
+		return nil
+	}
+
+	runtime := host.Runtime()
+	metering := host.Metering()
+
+	callbackCallInput := &vmcommon.ContractCallInput{
+		VMInput: vmcommon.VMInput{
+			CallerAddr: asyncCall.Destination,
+			Arguments: [][]byte{
+				big.NewInt(int64(arwen.AsyncCallTimeout)).Bytes(),
+				[]byte(arwen.ErrAsyncCallExpired.Error()),
+			},
+			CallValue:            big.NewInt(0),
+			CallType:             vm.AsynchronousCallBack,
+			GasPrice:             runtime.GetVMInput().GasPrice,
+			GasProvided:          metering.GasLeft(),
+			CurrentTxHash:        runtime.GetCurrentTxHash(),
+			OriginalTxHash:       originalTxHash,
+			ReturnCallAfterError: true,
+		},
+		RecipientAddr: runtime.GetSCAddress(),
+		Function:      asyncCall.ErrorCallback,
+	}
+
+	log.Trace("expired async call callback", "dest", asyncCall.Destination, "func", asyncCall.ErrorCallback)
+
+	callbackVMOutput, _, callBackErr := host.executeCallbackOnDestContext(callbackCallInput, asyncCall.CallbackClosure)
+	return host.processCallbackVMOutput(callbackVMOutput, callBackErr)
+}
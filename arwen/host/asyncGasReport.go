@@ -0,0 +1,129 @@
+package host
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/math"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// asyncGasReporter collects the gas entries of the async calls processed
+// during an execution
+type asyncGasReporter struct {
+	enabled bool
+	report  *arwen.AsyncGasReport
+}
+
+func newAsyncGasReporter(enabled bool) *asyncGasReporter {
+	reporter := &asyncGasReporter{
+		enabled: enabled,
+	}
+	reporter.reset()
+
+	return reporter
+}
+
+// SetAsyncGasReportEnabled enables or disables the construction of the async
+// gas report for the executions which follow
+func (host *vmHost) SetAsyncGasReportEnabled(enabled bool) {
+	host.asyncGasReporter.enabled = enabled
+	host.asyncGasReporter.reset()
+}
+
+// GetAsyncGasReport returns the async gas report of the latest execution, or
+// nil if the async gas report is not enabled
+func (host *vmHost) GetAsyncGasReport() *arwen.AsyncGasReport {
+	return host.asyncGasReporter.report
+}
+
+func (reporter *asyncGasReporter) reset() {
+	reporter.report = nil
+	if reporter.enabled {
+		reporter.report = &arwen.AsyncGasReport{
+			Entries: make([]*arwen.AsyncCallGasEntry, 0),
+		}
+	}
+}
+
+// newEntry creates the entry of an async call, adding it to the report if the
+// report is enabled; the entry is returned even if it is not reported, so
+// that the callers need not check
+func (reporter *asyncGasReporter) newEntry(
+	contextIdentifier string,
+	asyncCallInfo arwen.AsyncCallInfoHandler,
+	crossShard bool,
+) *arwen.AsyncCallGasEntry {
+	entry := &arwen.AsyncCallGasEntry{
+		ContextIdentifier: contextIdentifier,
+		Destination:       asyncCallInfo.GetDestination(),
+		Data:              asyncCallInfo.GetData(),
+		CrossShard:        crossShard,
+		GasLocked:         asyncCallInfo.GetGasLocked(),
+	}
+	if crossShard {
+		entry.GasProvided = asyncCallInfo.GetGasLimit()
+	}
+
+	if reporter.report != nil {
+		reporter.report.Entries = append(reporter.report.Entries, entry)
+	}
+
+	return entry
+}
+
+// addReceivedCallback reports the execution of the callback of a cross-shard
+// async call, which arrives in a transaction of its own
+func (reporter *asyncGasReporter) addReceivedCallback(input *vmcommon.ContractCallInput, vmOutput *vmcommon.VMOutput) {
+	if reporter.report == nil || vmOutput == nil {
+		return
+	}
+
+	entry := &arwen.AsyncCallGasEntry{
+		Destination:       input.CallerAddr,
+		CrossShard:        true,
+		ReceivedCallback:  true,
+		GasProvided:       input.GasProvided,
+		GasLocked:         input.GasLocked,
+		GasUsedByCallback: math.SubUint64(math.AddUint64(input.GasProvided, input.GasLocked), vmOutput.GasRemaining),
+		GasRefunded:       vmOutput.GasRemaining,
+	}
+	reporter.report.Entries = append(reporter.report.Entries, entry)
+}
+
+// recordDestinationGas fills the entry with the gas used by the destination;
+// until the callback is recorded, all the remaining gas counts as refunded
+func recordDestinationGas(entry *arwen.AsyncCallGasEntry, input *vmcommon.ContractCallInput, vmOutput *vmcommon.VMOutput) {
+	if input == nil {
+		return
+	}
+
+	gasRemaining := uint64(0)
+	entry.DestinationReturnCode = vmcommon.ExecutionFailed
+	if vmOutput != nil {
+		gasRemaining = vmOutput.GasRemaining
+		entry.DestinationReturnCode = vmOutput.ReturnCode
+	}
+
+	entry.GasProvided = input.GasProvided
+	entry.GasUsedByDestination = math.SubUint64(input.GasProvided, gasRemaining)
+	entry.GasRefunded = gasRemaining
+}
+
+// recordCallbackGas fills the entry with the gas used by the callback, which
+// was given the gas remaining after the destination and the locked gas; the
+// cost of passing the results of the destination to the callback is included.
+// A callback which could not be executed has used the locked gas.
+func recordCallbackGas(entry *arwen.AsyncCallGasEntry, input *vmcommon.ContractCallInput, vmOutput *vmcommon.VMOutput) {
+	if input == nil {
+		entry.GasUsedByCallback = entry.GasLocked
+		return
+	}
+
+	gasRemaining := uint64(0)
+	if vmOutput != nil {
+		gasRemaining = vmOutput.GasRemaining
+	}
+
+	gasAvailable := math.AddUint64(entry.GasRefunded, entry.GasLocked)
+	entry.GasUsedByCallback = math.SubUint64(gasAvailable, gasRemaining)
+	entry.GasRefunded = gasRemaining
+}
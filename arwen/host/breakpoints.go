@@ -11,6 +11,11 @@ func (host *vmHost) handleBreakpointIfAny(executionErr error) error {
 
 	runtime := host.Runtime()
 	breakpointValue := runtime.GetRuntimeBreakpointValue()
+	if host.watchdog.isExpired() {
+		// A failing EEI function may have overwritten BreakpointTimeout after
+		// the watchdog had set it, but the timeout takes precedence.
+		breakpointValue = arwen.BreakpointTimeout
+	}
 	if breakpointValue != arwen.BreakpointNone {
 		err := host.handleBreakpoint(breakpointValue)
 		runtime.AddError(err)
@@ -34,6 +39,12 @@ func (host *vmHost) handleBreakpoint(breakpointValue arwen.BreakpointValue) erro
 	if breakpointValue == arwen.BreakpointOutOfGas {
 		return arwen.ErrNotEnoughGas
 	}
+	if breakpointValue == arwen.BreakpointTimeout {
+		return arwen.ErrExecutionTimeout
+	}
+	if breakpointValue == arwen.BreakpointMemoryLimit {
+		return arwen.ErrMemoryLimitExceeded
+	}
 
 	return arwen.ErrUnhandledRuntimeBreakpoint
 }
@@ -0,0 +1,30 @@
+package host
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+)
+
+// SetMaxCallDepth sets how many executions may be nested by
+// ExecuteOnDestContext() or ExecuteOnSameContext() on top of the one started
+// by the transaction; 0 leaves the depth unbounded
+func (host *vmHost) SetMaxCallDepth(depth int) {
+	host.maxCallDepth = depth
+}
+
+// checkCallDepth must be called after the runtime state of the caller has
+// been pushed, so that the new execution is counted. Unlike the limit of the
+// Wasmer instances, the depth counts every nested execution, including those
+// of the same contract and the delegated ones.
+func (host *vmHost) checkCallDepth() error {
+	if host.maxCallDepth <= 0 {
+		return nil
+	}
+
+	callDepth := host.Runtime().CallDepth()
+	if callDepth <= host.maxCallDepth {
+		return nil
+	}
+
+	log.Trace("call depth exceeded", "depth", callDepth, "max", host.maxCallDepth)
+	return arwen.ErrCallDepthExceeded
+}
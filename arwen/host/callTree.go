@@ -0,0 +1,223 @@
+package host
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// callTreeBuilder assembles the call tree of an execution out of the entry
+// and exit events fired by the host
+type callTreeBuilder struct {
+	enabled bool
+	root    *arwen.CallTreeNode
+	frames  []*callTreeFrame
+}
+
+// callTreeFrame holds the state of a node which has been entered, but not
+// exited yet; the child sets contain the logs and storage updates produced
+// by the completed subtrees of the node's children
+type callTreeFrame struct {
+	node          *arwen.CallTreeNode
+	logsBefore    int
+	storageBefore map[string]map[string]*vmcommon.StorageUpdate
+	childLogs     map[*vmcommon.LogEntry]struct{}
+	childStorage  map[string]map[string]*vmcommon.StorageUpdate
+}
+
+func newCallTreeBuilder(enabled bool) *callTreeBuilder {
+	return &callTreeBuilder{
+		enabled: enabled,
+		frames:  make([]*callTreeFrame, 0),
+	}
+}
+
+// SetCallTreeEnabled enables or disables the construction of the call tree
+// for the executions which follow
+func (host *vmHost) SetCallTreeEnabled(enabled bool) {
+	host.callTree.enabled = enabled
+	host.callTree.reset()
+}
+
+// IsCallTreeEnabled returns true if the call tree is built for the current
+// execution
+func (host *vmHost) IsCallTreeEnabled() bool {
+	return host.callTree.enabled
+}
+
+// GetCallTree returns the call tree of the latest execution, or nil if the
+// call tree is not enabled
+func (host *vmHost) GetCallTree() *arwen.CallTreeNode {
+	return host.callTree.root
+}
+
+func (builder *callTreeBuilder) reset() {
+	builder.root = nil
+	builder.frames = make([]*callTreeFrame, 0)
+}
+
+// enter opens a new node as the last child of the currently open node, or as
+// the root of the tree if there is no open node
+func (builder *callTreeBuilder) enter(event *arwen.ExecutionEvent, output arwen.OutputContext) {
+	node := &arwen.CallTreeNode{
+		Kind:           event.Kind,
+		Logs:           make([]*vmcommon.LogEntry, 0),
+		StorageUpdates: make(map[string]map[string]*vmcommon.StorageUpdate),
+		Children:       make([]*arwen.CallTreeNode, 0),
+	}
+
+	parent := builder.currentFrame()
+	if parent == nil {
+		builder.root = node
+	} else {
+		parent.node.Children = append(parent.node.Children, node)
+	}
+
+	frame := &callTreeFrame{
+		node:          node,
+		logsBefore:    len(output.GetLogs()),
+		storageBefore: snapshotStorageUpdates(output.GetOutputAccounts()),
+		childLogs:     make(map[*vmcommon.LogEntry]struct{}),
+		childStorage:  make(map[string]map[string]*vmcommon.StorageUpdate),
+	}
+	builder.frames = append(builder.frames, frame)
+}
+
+// exit closes the currently open node, keeping only the logs and the storage
+// updates produced by the node itself and passing its whole subtree on to
+// the parent node
+func (builder *callTreeBuilder) exit(
+	event *arwen.ExecutionEvent,
+	vmOutput *vmcommon.VMOutput,
+	output arwen.OutputContext,
+	storage arwen.StorageContext,
+) {
+	frame := builder.currentFrame()
+	if frame == nil {
+		return
+	}
+	builder.frames = builder.frames[:len(builder.frames)-1]
+
+	node := frame.node
+	node.CallerAddr = event.CallerAddr
+	node.ContractAddr = event.ContractAddr
+	node.Function = event.Function
+	node.GasProvided = event.GasProvided
+	node.GasUsed = event.GasUsed
+	node.ReturnCode = event.ReturnCode
+	node.ReturnMessage = event.ReturnMessage
+	node.ReturnData = event.ReturnData
+
+	// the error of the event may also hold the errors of the failed nested
+	// calls which the contract has survived, so only the return code tells
+	// whether the execution itself has failed
+	if vmOutput == nil || event.ReturnCode != vmcommon.Ok {
+		discardCallTreeEffects(node)
+		return
+	}
+
+	subtreeLogs := vmOutput.Logs
+	if node.Kind == arwen.ExecutionSameContext && frame.logsBefore <= len(subtreeLogs) {
+		subtreeLogs = subtreeLogs[frame.logsBefore:]
+	}
+
+	var subtreeStorage map[string]map[string]*vmcommon.StorageUpdate
+	if node.Kind == arwen.ExecutionBuiltinFunction {
+		subtreeStorage = snapshotStorageUpdates(vmOutput.OutputAccounts)
+	} else {
+		subtreeStorage = diffStorageUpdates(frame.storageBefore, output.GetOutputAccounts(), storage)
+	}
+
+	for _, logEntry := range subtreeLogs {
+		if _, ok := frame.childLogs[logEntry]; !ok {
+			node.Logs = append(node.Logs, logEntry)
+		}
+	}
+
+	for address, updates := range subtreeStorage {
+		for key, update := range updates {
+			if frame.childStorage[address][key] != update {
+				addStorageUpdate(node.StorageUpdates, address, key, update)
+			}
+		}
+	}
+
+	parent := builder.currentFrame()
+	if parent == nil {
+		return
+	}
+	for _, logEntry := range subtreeLogs {
+		parent.childLogs[logEntry] = struct{}{}
+	}
+	for address, updates := range subtreeStorage {
+		for key, update := range updates {
+			addStorageUpdate(parent.childStorage, address, key, update)
+		}
+	}
+}
+
+func (builder *callTreeBuilder) currentFrame() *callTreeFrame {
+	if len(builder.frames) == 0 {
+		return nil
+	}
+
+	return builder.frames[len(builder.frames)-1]
+}
+
+// diffStorageUpdates returns the storage updates which have been written
+// since the snapshot was taken, including the writes of unchanged values;
+// entries which only cache the values read from the blockchain are not writes
+func diffStorageUpdates(
+	before map[string]map[string]*vmcommon.StorageUpdate,
+	accounts map[string]*vmcommon.OutputAccount,
+	storage arwen.StorageContext,
+) map[string]map[string]*vmcommon.StorageUpdate {
+	diff := make(map[string]map[string]*vmcommon.StorageUpdate)
+	for address, account := range accounts {
+		for key, update := range account.StorageUpdates {
+			if before[address][key] == update || !storage.IsStorageUpdateWritten(update) {
+				continue
+			}
+
+			addStorageUpdate(diff, address, key, update)
+		}
+	}
+
+	return diff
+}
+
+func snapshotStorageUpdates(accounts map[string]*vmcommon.OutputAccount) map[string]map[string]*vmcommon.StorageUpdate {
+	snapshot := make(map[string]map[string]*vmcommon.StorageUpdate)
+	for address, account := range accounts {
+		for key, update := range account.StorageUpdates {
+			addStorageUpdate(snapshot, address, key, update)
+		}
+	}
+
+	return snapshot
+}
+
+func addStorageUpdate(
+	updates map[string]map[string]*vmcommon.StorageUpdate,
+	address string,
+	key string,
+	update *vmcommon.StorageUpdate,
+) {
+	accountUpdates, ok := updates[address]
+	if !ok {
+		accountUpdates = make(map[string]*vmcommon.StorageUpdate)
+		updates[address] = accountUpdates
+	}
+
+	accountUpdates[key] = update
+}
+
+// discardCallTreeEffects removes the logs and the storage updates from a
+// node and its whole subtree, because the effects of a failed execution are
+// reverted together with the effects of the executions it has caused
+func discardCallTreeEffects(node *arwen.CallTreeNode) {
+	node.Logs = make([]*vmcommon.LogEntry, 0)
+	node.StorageUpdates = make(map[string]map[string]*vmcommon.StorageUpdate)
+	for _, child := range node.Children {
+		discardCallTreeEffects(child)
+	}
+}
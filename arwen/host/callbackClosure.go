@@ -0,0 +1,141 @@
+package host
+
+import (
+	"encoding/binary"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// sequenceLength is the length of the encoded sequence numbers of the
+// cross-shard callback closures
+const sequenceLength = 8
+
+// executeCallbackOnDestContext executes the callback of an async call on the
+// destination context, handing it the callback closure of the async call
+func (host *vmHost) executeCallbackOnDestContext(
+	input *vmcommon.ContractCallInput,
+	callbackClosure []byte,
+) (*vmcommon.VMOutput, *arwen.AsyncContextInfo, error) {
+	host.callbackClosure = callbackClosure
+	defer func() {
+		host.callbackClosure = nil
+	}()
+
+	return host.ExecuteOnDestContext(input)
+}
+
+// passCallbackClosure sets the callback closure handed over by
+// executeCallbackOnDestContext on the freshly initialized runtime state of
+// the callback; the closure is consumed, so that the calls made by the
+// callback itself do not receive it
+func (host *vmHost) passCallbackClosure(input *vmcommon.ContractCallInput) {
+	callbackClosure := host.callbackClosure
+	host.callbackClosure = nil
+
+	if input.CallType == vm.AsynchronousCallBack {
+		host.Runtime().SetCallbackClosure(callbackClosure)
+	}
+}
+
+// saveCrossShardCallbackClosure stores the callback closure of an async call
+// sent to a different shard, because its callback will be executed by
+// another transaction, which no longer has the runtime state of the caller.
+// Callbacks carry no identifier of their own, so the async calls sent to the
+// same destination by the same original transaction are numbered, and their
+// callbacks consume the closures in the order in which they were stored, as
+// findPendingAsyncCall does for the async contexts; each async call takes a
+// number, even without a closure, to keep the callbacks in step.
+func (host *vmHost) saveCrossShardCallbackClosure(destination []byte, callbackClosure []byte) error {
+	if !host.IsFeatureEnabled(arwen.CallbackClosureFeature) {
+		return nil
+	}
+
+	storage := host.Storage()
+	sequencesKey := host.crossShardCallbackClosureKey(destination, nil)
+	nextSaved, nextLoaded := decodeCallbackClosureSequences(storage.GetStorageUnmetered(sequencesKey))
+
+	if len(callbackClosure) > 0 {
+		closureKey := host.crossShardCallbackClosureKey(destination, encodeSequence(nextSaved))
+		_, err := storage.SetProtectedStorage(closureKey, callbackClosure)
+		if err != nil {
+			return err
+		}
+	}
+
+	sequences := encodeCallbackClosureSequences(nextSaved+1, nextLoaded)
+	_, err := storage.SetProtectedStorage(sequencesKey, sequences)
+	return err
+}
+
+// loadCrossShardCallbackClosure sets the stored callback closure of the
+// earliest async call answered by the given address on the runtime state of
+// the callback; the stored closure is removed, since an async call has a
+// single callback
+func (host *vmHost) loadCrossShardCallbackClosure(asyncCallDestination []byte) error {
+	if !host.IsFeatureEnabled(arwen.CallbackClosureFeature) {
+		return nil
+	}
+
+	storage := host.Storage()
+	sequencesKey := host.crossShardCallbackClosureKey(asyncCallDestination, nil)
+	sequences := storage.GetStorageUnmetered(sequencesKey)
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	nextSaved, nextLoaded := decodeCallbackClosureSequences(sequences)
+	closureKey := host.crossShardCallbackClosureKey(asyncCallDestination, encodeSequence(nextLoaded))
+	callbackClosure := storage.GetStorageUnmetered(closureKey)
+	if len(callbackClosure) > 0 {
+		host.Runtime().SetCallbackClosure(callbackClosure)
+		_, err := storage.SetProtectedStorage(closureKey, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	nextLoaded++
+	if nextLoaded >= nextSaved {
+		sequences = nil
+	} else {
+		sequences = encodeCallbackClosureSequences(nextSaved, nextLoaded)
+	}
+
+	_, err := storage.SetProtectedStorage(sequencesKey, sequences)
+	return err
+}
+
+// crossShardCallbackClosureKey returns the storage key of the closure with the
+// given encoded sequence number, or the key of the sequence numbers of the
+// async calls sent to the given destination if the sequence is nil
+func (host *vmHost) crossShardCallbackClosureKey(asyncCallDestination []byte, sequence []byte) []byte {
+	originalTxHash := host.Runtime().GetOriginalTxHash()
+	associatedKey := make([]byte, 0, len(originalTxHash)+len(asyncCallDestination)+len(sequence))
+	associatedKey = append(associatedKey, originalTxHash...)
+	associatedKey = append(associatedKey, asyncCallDestination...)
+	associatedKey = append(associatedKey, sequence...)
+
+	return arwen.CustomStorageKey(arwen.CallbackClosurePrefix, associatedKey)
+}
+
+// encodeCallbackClosureSequences encodes the sequence number of the next
+// stored closure and the sequence number of the next loaded one
+func encodeCallbackClosureSequences(nextSaved uint64, nextLoaded uint64) []byte {
+	return append(encodeSequence(nextSaved), encodeSequence(nextLoaded)...)
+}
+
+func decodeCallbackClosureSequences(sequences []byte) (uint64, uint64) {
+	if len(sequences) != 2*sequenceLength {
+		return 0, 0
+	}
+
+	return binary.BigEndian.Uint64(sequences[:sequenceLength]), binary.BigEndian.Uint64(sequences[sequenceLength:])
+}
+
+func encodeSequence(sequence uint64) []byte {
+	encoded := make([]byte, sequenceLength)
+	binary.BigEndian.PutUint64(encoded, sequence)
+	return encoded
+}
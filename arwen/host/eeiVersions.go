@@ -0,0 +1,145 @@
+package host
+
+import (
+	"sync"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/cryptoapi"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/elrondapi"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
+)
+
+// Wasmer keeps a single, process-wide import object, which is bound to each
+// instance when it is created. Hosts which instantiate contracts of different
+// EEI versions must therefore hold eeiImportsMutex from the moment they set
+// the imports of a version until the instance has been created.
+var eeiImportsMutex sync.Mutex
+var activeEEIImports *wasmer.Imports
+
+// newEEIImportsV1_3 creates the imports of EEI v1.3, which lacks the managed
+// buffer API
+func newEEIImportsV1_3() (*wasmer.Imports, error) {
+	imports, err := elrondapi.ElrondEIImports()
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = elrondapi.BigIntImports(imports)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err = elrondapi.SmallIntImports(imports)
+	if err != nil {
+		return nil, err
+	}
+
+	return cryptoapi.CryptoImports(imports)
+}
+
+// newEEIImportsV1_4 creates the imports of EEI v1.4
+func newEEIImportsV1_4() (*wasmer.Imports, error) {
+	imports, err := newEEIImportsV1_3()
+	if err != nil {
+		return nil, err
+	}
+
+	return elrondapi.ManagedBufferImports(imports)
+}
+
+// defaultEEIImports holds the imports of the EEI versions supported by Arwen;
+// they are created once and shared by all the hosts, so that the hosts which
+// instantiate contracts of the same EEI version do not replace the imports of
+// Wasmer one after another
+var defaultEEIImports map[arwen.EEIVersion]*wasmer.Imports
+var defaultEEIImportsErr error
+var defaultEEIImportsOnce sync.Once
+
+func getDefaultEEIImports() (map[arwen.EEIVersion]*wasmer.Imports, error) {
+	defaultEEIImportsOnce.Do(func() {
+		importsV1_3, err := newEEIImportsV1_3()
+		if err != nil {
+			defaultEEIImportsErr = err
+			return
+		}
+
+		importsV1_4, err := newEEIImportsV1_4()
+		if err != nil {
+			defaultEEIImportsErr = err
+			return
+		}
+
+		defaultEEIImports = map[arwen.EEIVersion]*wasmer.Imports{
+			arwen.EEIVersion1_3: importsV1_3,
+			arwen.EEIVersion1_4: importsV1_4,
+		}
+	})
+
+	return defaultEEIImports, defaultEEIImportsErr
+}
+
+func (host *vmHost) registerDefaultEEIVersions() error {
+	eeiImports, err := getDefaultEEIImports()
+	if err != nil {
+		return err
+	}
+
+	for _, version := range []arwen.EEIVersion{arwen.EEIVersion1_3, arwen.EEIVersion1_4} {
+		err = host.RegisterEEIVersion(version, eeiImports[version])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterEEIVersion makes the given imports available to the contracts
+// bound to the given EEI version, replacing any imports previously
+// registered for it
+func (host *vmHost) RegisterEEIVersion(version arwen.EEIVersion, imports *wasmer.Imports) error {
+	if version == arwen.EEIVersionUnspecified || version > arwen.MaxEEIVersion {
+		return arwen.ErrInvalidEEIVersion
+	}
+	if imports == nil {
+		return arwen.ErrNilEEIImports
+	}
+
+	host.eeiVersions[version] = imports
+	if version == arwen.CurrentEEIVersion {
+		host.scAPIMethods = imports
+	}
+
+	log.Trace("registered EEI version", "version", version, "imports", imports.Count())
+	return nil
+}
+
+// LockEEIVersion sets the imports of the given EEI version as the ones to be
+// bound to the next Wasmer instances, until the returned function is called
+// to unlock them. The contracts which do not record their EEI version are
+// bound to the legacy version of the host.
+func (host *vmHost) LockEEIVersion(version arwen.EEIVersion) (func(), error) {
+	if version == arwen.EEIVersionUnspecified {
+		version = host.legacyEEIVersion
+	}
+
+	imports, ok := host.eeiVersions[version]
+	if !ok {
+		return nil, arwen.ErrInvalidEEIVersion
+	}
+
+	eeiImportsMutex.Lock()
+	if activeEEIImports == imports {
+		return eeiImportsMutex.Unlock, nil
+	}
+
+	err := wasmer.SetImports(imports)
+	if err != nil {
+		activeEEIImports = nil
+		eeiImportsMutex.Unlock()
+		return nil, err
+	}
+
+	activeEEIImports = imports
+	return eeiImportsMutex.Unlock, nil
+}
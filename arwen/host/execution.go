@@ -16,13 +16,17 @@ import (
 	"github.com/ElrondNetwork/elrond-vm-common/parsers"
 )
 
-func (host *vmHost) doRunSmartContractCreate(input *vmcommon.ContractCreateInput) *vmcommon.VMOutput {
+func (host *vmHost) doRunSmartContractCreate(input *vmcommon.ContractCreateInput) (vmOutput *vmcommon.VMOutput) {
 	host.InitState()
+	host.watchdog.start()
+	executionEvent := host.notifyContractEnterForCreate(input)
 	defer func() {
 		errs := host.GetRuntimeErrors()
 		if errs != nil {
 			log.Trace(fmt.Sprintf("doRunSmartContractCreate full error list"), "error", errs)
 		}
+		host.notifyContractExit(executionEvent, vmOutput, errs)
+		host.watchdog.stop()
 		host.Clean()
 	}()
 
@@ -32,6 +36,9 @@ func (host *vmHost) doRunSmartContractCreate(input *vmcommon.ContractCreateInput
 	if err != nil {
 		return output.CreateVMOutputInCaseOfError(err)
 	}
+	if executionEvent != nil {
+		executionEvent.ContractAddr = address
+	}
 
 	runtime.SetVMInput(&input.VMInput)
 	runtime.SetSCAddress(address)
@@ -47,7 +54,7 @@ func (host *vmHost) doRunSmartContractCreate(input *vmcommon.ContractCreateInput
 		CodeDeployerAddress:  input.CallerAddr,
 	}
 
-	vmOutput, err := host.performCodeDeployment(codeDeployInput)
+	vmOutput, err = host.performCodeDeployment(codeDeployInput)
 	if err != nil {
 		log.Trace("doRunSmartContractCreate", "error", err)
 		return output.CreateVMOutputInCaseOfError(err)
@@ -77,6 +84,9 @@ func (host *vmHost) performCodeDeployment(input arwen.CodeDeployInput) (*vmcommo
 	err = runtime.StartWasmerInstance(input.ContractCode, metering.GetGasForExecution(), true)
 	if err != nil {
 		log.Trace("performCodeDeployment/StartWasmerInstance", "err", err)
+		if errors.Is(err, arwen.ErrContractInvalid) {
+			return nil, err
+		}
 		return nil, arwen.ErrContractInvalid
 	}
 
@@ -92,13 +102,17 @@ func (host *vmHost) performCodeDeployment(input arwen.CodeDeployInput) (*vmcommo
 }
 
 // doRunSmartContractUpgrade upgrades a contract directly
-func (host *vmHost) doRunSmartContractUpgrade(input *vmcommon.ContractCallInput) *vmcommon.VMOutput {
+func (host *vmHost) doRunSmartContractUpgrade(input *vmcommon.ContractCallInput) (vmOutput *vmcommon.VMOutput) {
 	host.InitState()
+	host.watchdog.start()
+	executionEvent := host.notifyContractEnter(arwen.ExecutionUpgrade, input)
 	defer func() {
 		errs := host.GetRuntimeErrors()
 		if errs != nil {
 			log.Trace(fmt.Sprintf("doRunSmartContractUpgrade full error list"), "error", errs)
 		}
+		host.notifyContractExit(executionEvent, vmOutput, errs)
+		host.watchdog.stop()
 		host.Clean()
 	}()
 
@@ -121,7 +135,7 @@ func (host *vmHost) doRunSmartContractUpgrade(input *vmcommon.ContractCallInput)
 		CodeDeployerAddress:  input.CallerAddr,
 	}
 
-	vmOutput, err := host.performCodeDeployment(codeDeployInput)
+	vmOutput, err = host.performCodeDeployment(codeDeployInput)
 	if err != nil {
 		log.Trace("doRunSmartContractUpgrade", "error", err)
 		return output.CreateVMOutputInCaseOfError(err)
@@ -141,17 +155,28 @@ func (host *vmHost) checkGasForGetCode(input *vmcommon.ContractCallInput, meteri
 
 func (host *vmHost) doRunSmartContractCall(input *vmcommon.ContractCallInput) (vmOutput *vmcommon.VMOutput) {
 	host.InitState()
+	host.watchdog.start()
+	executionKind := executionKindFromCallType(input.CallType, arwen.ExecutionDirectCall)
+	executionEvent := host.notifyContractEnter(executionKind, input)
 	defer func() {
 		errs := host.GetRuntimeErrors()
 		if errs != nil {
 			log.Trace(fmt.Sprintf("doRunSmartContractCall full error list for %s", input.Function), "error", errs)
 		}
+		host.notifyContractExit(executionEvent, vmOutput, errs)
+		if input.CallType == vm.AsynchronousCallBack {
+			host.asyncGasReporter.addReceivedCallback(input, vmOutput)
+		}
+		host.watchdog.stop()
 		host.Clean()
 	}()
 
 	_, _, metering, output, runtime, storage := host.GetContexts()
 
 	runtime.InitStateFromContractCallInput(input)
+	if host.readOnlyQueries {
+		runtime.SetReadOnly(true)
+	}
 	metering.InitStateFromContractCallInput(&input.VMInput)
 	output.AddTxValueToAccount(input.RecipientAddr, input.CallValue)
 	storage.SetAddress(runtime.GetSCAddress())
@@ -231,7 +256,10 @@ func (host *vmHost) ExecuteOnDestContext(input *vmcommon.ContractCallInput) (vmO
 	}
 
 	if scExecutionInput != nil {
+		executionKind := executionKindFromCallType(scExecutionInput.CallType, arwen.ExecutionDestContext)
+		executionEvent := host.notifyContractEnter(executionKind, scExecutionInput)
 		vmOutput, asyncInfo, err = host.executeOnDestContextNoBuiltinFunction(scExecutionInput)
+		host.notifyContractExit(executionEvent, vmOutput, err)
 	}
 
 	if err != nil {
@@ -245,7 +273,9 @@ func (host *vmHost) ExecuteOnDestContext(input *vmcommon.ContractCallInput) (vmO
 
 func (host *vmHost) handleBuiltinFunctionCall(input *vmcommon.ContractCallInput) (*vmcommon.ContractCallInput, *vmcommon.VMOutput, error) {
 	output := host.Output()
+	executionEvent := host.notifyContractEnter(arwen.ExecutionBuiltinFunction, input)
 	postBuiltinInput, builtinOutput, err := host.callBuiltinFunction(input)
+	host.notifyContractExit(executionEvent, builtinOutput, err)
 	if err != nil {
 		log.Trace("ExecuteOnDestContext builtin function", "error", err)
 		return nil, nil, err
@@ -267,6 +297,7 @@ func (host *vmHost) executeOnDestContextNoBuiltinFunction(input *vmcommon.Contra
 	copyTxHashesFromContext(runtime, input)
 	runtime.PushState()
 	runtime.InitStateFromContractCallInput(input)
+	host.passCallbackClosure(input)
 
 	metering.PushState()
 	metering.InitStateFromContractCallInput(&input.VMInput)
@@ -282,6 +313,16 @@ func (host *vmHost) executeOnDestContextNoBuiltinFunction(input *vmcommon.Contra
 		}
 	}()
 
+	err = host.checkCallDepth()
+	if err != nil {
+		return
+	}
+
+	err = host.checkReentrancy(input)
+	if err != nil {
+		return
+	}
+
 	// Perform a value transfer to the called SC. If the execution fails, this
 	// transfer will not persist.
 	if input.CallType != vm.AsynchronousCallBack || input.CallValue.Cmp(arwen.Zero) == 0 {
@@ -353,6 +394,8 @@ func (host *vmHost) ExecuteOnSameContext(input *vmcommon.ContractCallInput) (asy
 
 	bigInt, blockchain, metering, output, runtime, _ := host.GetContexts()
 
+	executionEvent := host.notifyContractEnter(arwen.ExecutionSameContext, input)
+
 	// Back up the states of the contexts (except Storage, which isn't affected
 	// by ExecuteOnSameContext())
 	bigInt.PushState()
@@ -369,9 +412,20 @@ func (host *vmHost) ExecuteOnSameContext(input *vmcommon.ContractCallInput) (asy
 
 	defer func() {
 		runtime.AddError(err, input.Function)
+		host.notifySameContextExit(executionEvent, err)
 		host.finishExecuteOnSameContext(err)
 	}()
 
+	err = host.checkCallDepth()
+	if err != nil {
+		return
+	}
+
+	err = host.checkReentrancy(input)
+	if err != nil {
+		return
+	}
+
 	// Perform a value transfer to the called SC. If the execution fails, this
 	// transfer will not persist.
 	err = output.TransferValueOnly(input.RecipientAddr, input.CallerAddr, input.CallValue, false)
@@ -557,6 +611,9 @@ func (host *vmHost) executeUpgrade(input *vmcommon.ContractCallInput) error {
 	err = runtime.StartWasmerInstance(codeDeployInput.ContractCode, metering.GetGasForExecution(), true)
 	if err != nil {
 		log.Trace("performCodeDeployment/StartWasmerInstance", "err", err)
+		if errors.Is(err, arwen.ErrContractInvalid) {
+			return err
+		}
 		return arwen.ErrContractInvalid
 	}
 
@@ -634,7 +691,8 @@ func (host *vmHost) execute(input *vmcommon.ContractCallInput) error {
 }
 
 func (host *vmHost) callSCMethodIndirect() error {
-	function, err := host.Runtime().GetFunctionToCall()
+	runtime := host.Runtime()
+	function, err := runtime.GetFunctionToCall()
 	if err != nil {
 		if errors.Is(err, arwen.ErrNilCallbackFunction) {
 			return nil
@@ -642,7 +700,13 @@ func (host *vmHost) callSCMethodIndirect() error {
 		return err
 	}
 
-	_, err = function()
+	defer host.recordPanicReport()
+
+	pagesBefore := runtime.MemoryPages()
+	err = host.watchdog.callFunction(runtime.GetInstance(), function)
+	if err == nil {
+		err = runtime.ChargeMemoryGrowth(pagesBefore)
+	}
 	if err != nil {
 		err = host.handleBreakpointIfAny(err)
 	}
@@ -693,7 +757,9 @@ func (host *vmHost) ExecuteESDTTransfer(destination []byte, sender []byte, trans
 		}
 	}
 
+	executionEvent := host.notifyContractEnter(arwen.ExecutionBuiltinFunction, esdtTransferInput)
 	vmOutput, err := host.Blockchain().ProcessBuiltInFunction(esdtTransferInput)
+	host.notifyContractExit(executionEvent, vmOutput, err)
 	log.Trace("ESDT transfer", "sender", sender, "dest", destination)
 	for _, transfer := range transfers {
 		log.Trace("ESDT transfer", "token", transfer.ESDTTokenName, "nonce", transfer.ESDTTokenNonce, "value", transfer.ESDTValue)
@@ -822,7 +888,13 @@ func (host *vmHost) callInitFunction() error {
 		return nil
 	}
 
-	_, err := init()
+	defer host.recordPanicReport()
+
+	pagesBefore := runtime.MemoryPages()
+	err := host.watchdog.callFunction(runtime.GetInstance(), init)
+	if err == nil {
+		err = runtime.ChargeMemoryGrowth(pagesBefore)
+	}
 	if err != nil {
 		err = host.handleBreakpointIfAny(err)
 	}
@@ -843,12 +915,22 @@ func (host *vmHost) callSCMethod() error {
 	// function itself is changed by host.getFunctionByCallType(). Order must be
 	// reversed, and `getFunctionByCallType()` must be decomposed into smaller functions.
 
-	err := host.verifyAllowedFunctionCall()
+	functionName := runtime.Function()
+	err := host.verifyAllowedFunctionCall(functionName)
 	if err != nil {
 		log.Trace("call SC method failed", "error", err)
 		return err
 	}
 
+	if host.isExpiredAsyncCallsResolution(functionName) {
+		err = host.resolveExpiredAsyncCalls()
+		if err != nil {
+			log.Trace("call SC method failed", "error", err)
+		}
+
+		return err
+	}
+
 	callType := runtime.GetVMInput().CallType
 	function, err := host.getFunctionByCallType(callType)
 	if err != nil {
@@ -864,7 +946,13 @@ func (host *vmHost) callSCMethod() error {
 		return err
 	}
 
-	_, err = function()
+	defer host.recordPanicReport()
+
+	pagesBefore := runtime.MemoryPages()
+	err = host.watchdog.callFunction(runtime.GetInstance(), function)
+	if err == nil {
+		err = runtime.ChargeMemoryGrowth(pagesBefore)
+	}
 	if err != nil {
 		err = host.handleBreakpointIfAny(err)
 	}
@@ -899,10 +987,8 @@ func (host *vmHost) callSCMethod() error {
 	return err
 }
 
-func (host *vmHost) verifyAllowedFunctionCall() error {
+func (host *vmHost) verifyAllowedFunctionCall(functionName string) error {
 	runtime := host.Runtime()
-	functionName := runtime.Function()
-
 	isInit := functionName == arwen.InitFunctionName || functionName == arwen.InitFunctionNameEth
 	if isInit {
 		return arwen.ErrInitFuncCalledInRun
@@ -0,0 +1,129 @@
+package host
+
+import (
+	"math/big"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/math"
+	"github.com/ElrondNetwork/elrond-go-core/core/check"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// SetExecutionObserver sets the observer which will receive the execution
+// events fired by the host; a nil observer disables the events
+func (host *vmHost) SetExecutionObserver(observer arwen.ExecutionObserver) {
+	host.executionObserver = observer
+}
+
+func (host *vmHost) isExecutionObserved() bool {
+	return !check.IfNil(host.executionObserver)
+}
+
+// isExecutionTracked returns true if the entry and exit events must be
+// created, either for the observer or for the call tree
+func (host *vmHost) isExecutionTracked() bool {
+	return host.isExecutionObserved() || host.callTree.enabled
+}
+
+// executionKindFromCallType returns the kind of an execution based on the
+// call type of its input, falling back to the provided kind for synchronous calls
+func executionKindFromCallType(callType vm.CallType, syncKind arwen.ExecutionKind) arwen.ExecutionKind {
+	switch callType {
+	case vm.AsynchronousCall:
+		return arwen.ExecutionAsyncCall
+	case vm.AsynchronousCallBack:
+		return arwen.ExecutionAsyncCallback
+	}
+
+	return syncKind
+}
+
+// notifyContractEnter fires the entry event for the given input and returns
+// it, to be completed and fired again on exit
+func (host *vmHost) notifyContractEnter(kind arwen.ExecutionKind, input *vmcommon.ContractCallInput) *arwen.ExecutionEvent {
+	if !host.isExecutionTracked() {
+		return nil
+	}
+
+	host.executionDepth++
+	event := &arwen.ExecutionEvent{
+		Kind:         kind,
+		Depth:        host.executionDepth,
+		CallerAddr:   input.CallerAddr,
+		ContractAddr: input.RecipientAddr,
+		Function:     input.Function,
+		Arguments:    input.Arguments,
+		CallType:     input.CallType,
+		CallValue:    big.NewInt(0),
+		GasProvided:  input.GasProvided,
+		GasLocked:    input.GasLocked,
+	}
+	if input.CallValue != nil {
+		event.CallValue.Set(input.CallValue)
+	}
+
+	if host.callTree.enabled {
+		host.callTree.enter(event, host.Output())
+	}
+	if host.isExecutionObserved() {
+		host.executionObserver.OnContractEnter(event)
+	}
+	return event
+}
+
+// notifyContractEnterForCreate fires the entry event for a direct deployment
+func (host *vmHost) notifyContractEnterForCreate(input *vmcommon.ContractCreateInput) *arwen.ExecutionEvent {
+	return host.notifyContractEnter(arwen.ExecutionDeployment, &vmcommon.ContractCallInput{
+		VMInput:  input.VMInput,
+		Function: arwen.InitFunctionName,
+	})
+}
+
+// notifyContractExit completes the given entry event with the outcome of the
+// execution and fires it as an exit event
+func (host *vmHost) notifyContractExit(event *arwen.ExecutionEvent, vmOutput *vmcommon.VMOutput, err error) {
+	if event == nil || !host.isExecutionTracked() {
+		return
+	}
+
+	if vmOutput != nil {
+		event.GasRemaining = vmOutput.GasRemaining
+		event.ReturnCode = vmOutput.ReturnCode
+		event.ReturnMessage = vmOutput.ReturnMessage
+		event.ReturnData = vmOutput.ReturnData
+	} else if err != nil {
+		event.ReturnCode = vmcommon.ExecutionFailed
+		event.ReturnMessage = err.Error()
+	}
+	event.GasUsed = math.SubUint64(event.GasProvided, event.GasRemaining)
+	event.Err = err
+
+	host.executionDepth--
+	if host.callTree.enabled {
+		host.callTree.exit(event, vmOutput, host.Output(), host.Storage())
+	}
+	if host.isExecutionObserved() {
+		host.executionObserver.OnContractExit(event)
+	}
+}
+
+// notifySameContextExit fires the exit event of an ExecuteOnSameContext()
+// call; it must be called before the contexts are popped, because there is no
+// separate VMOutput describing the outcome of such a call
+func (host *vmHost) notifySameContextExit(event *arwen.ExecutionEvent, err error) {
+	if event == nil || !host.isExecutionTracked() {
+		return
+	}
+
+	output := host.Output()
+	sameContextOutput := &vmcommon.VMOutput{
+		GasRemaining:  host.Metering().GasLeft(),
+		ReturnCode:    output.ReturnCode(),
+		ReturnMessage: output.ReturnMessage(),
+		ReturnData:    output.ReturnData(),
+		Logs:          output.GetLogs(),
+	}
+
+	host.notifyContractExit(event, sameContextOutput, err)
+}
@@ -0,0 +1,62 @@
+package host
+
+import (
+	"sync"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+)
+
+// featureFlags tracks the current epoch, as confirmed by the EpochNotifier,
+// and decides which of the features gated by an activation epoch are enabled;
+// a feature without an activation epoch is never enabled. The notifier may
+// confirm epochs from another goroutine, hence the mutex.
+type featureFlags struct {
+	mutex            sync.RWMutex
+	activationEpochs map[arwen.FeatureFlag]uint32
+	currentEpoch     uint32
+}
+
+func newFeatureFlags(activationEpochs map[arwen.FeatureFlag]uint32) *featureFlags {
+	flags := &featureFlags{
+		activationEpochs: make(map[arwen.FeatureFlag]uint32, len(activationEpochs)),
+	}
+	for flag, epoch := range activationEpochs {
+		flags.activationEpochs[flag] = epoch
+	}
+
+	return flags
+}
+
+// EpochConfirmed is called by the EpochNotifier whenever a new epoch is confirmed
+func (flags *featureFlags) EpochConfirmed(epoch uint32, _ uint64) {
+	flags.mutex.Lock()
+	defer flags.mutex.Unlock()
+
+	flags.currentEpoch = epoch
+	for flag, activationEpoch := range flags.activationEpochs {
+		log.Debug("arwen feature flag", "flag", flag, "enabled", epoch >= activationEpoch, "epoch", epoch)
+	}
+}
+
+func (flags *featureFlags) isEnabled(flag arwen.FeatureFlag) bool {
+	flags.mutex.RLock()
+	defer flags.mutex.RUnlock()
+
+	activationEpoch, configured := flags.activationEpochs[flag]
+	if !configured {
+		return false
+	}
+
+	return flags.currentEpoch >= activationEpoch
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (flags *featureFlags) IsInterfaceNil() bool {
+	return flags == nil
+}
+
+// IsFeatureEnabled returns true if the given feature is active in the epoch
+// last confirmed by the EpochNotifier
+func (host *vmHost) IsFeatureEnabled(flag arwen.FeatureFlag) bool {
+	return host.featureFlags.isEnabled(flag)
+}
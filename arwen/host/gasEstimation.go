@@ -0,0 +1,272 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/math"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+type estimationRunFunction func(gasLimit uint64) (*vmcommon.VMOutput, error)
+
+// EstimateGas searches for the minimal gas limit with which the given
+// contract call succeeds, without exceeding input.GasProvided (or the block
+// gas limit, if input.GasProvided is 0). The input itself is not modified.
+func (host *vmHost) EstimateGas(input *vmcommon.ContractCallInput) (*arwen.GasEstimate, error) {
+	host.mutExecution.Lock()
+	defer host.mutExecution.Unlock()
+
+	run := func(gasLimit uint64) (vmOutput *vmcommon.VMOutput, err error) {
+		estimationInput := *input
+		estimationInput.GasProvided = gasLimit
+
+		try := func() {
+			if estimationInput.Function == arwen.UpgradeFunctionName {
+				vmOutput = host.doRunSmartContractUpgrade(&estimationInput)
+			} else {
+				vmOutput = host.doRunSmartContractCall(&estimationInput)
+			}
+		}
+
+		catch := func(caught error) {
+			err = caught
+		}
+
+		host.tryCatch(try, catch, "arwen.EstimateGas")
+		return
+	}
+
+	return host.estimateGas(input.GasProvided, run)
+}
+
+// EstimateGasForCreate searches for the minimal gas limit with which the
+// given contract deployment succeeds, in the same way as EstimateGas()
+func (host *vmHost) EstimateGasForCreate(input *vmcommon.ContractCreateInput) (*arwen.GasEstimate, error) {
+	host.mutExecution.Lock()
+	defer host.mutExecution.Unlock()
+
+	run := func(gasLimit uint64) (vmOutput *vmcommon.VMOutput, err error) {
+		estimationInput := *input
+		estimationInput.GasProvided = gasLimit
+
+		try := func() {
+			vmOutput = host.doRunSmartContractCreate(&estimationInput)
+		}
+
+		catch := func(caught error) {
+			err = caught
+		}
+
+		host.tryCatch(try, catch, "arwen.EstimateGasForCreate")
+		return
+	}
+
+	return host.estimateGas(input.GasProvided, run)
+}
+
+// estimateGas bisects the gas limit between the gas burned by a run with the
+// maximum gas limit and the maximum gas limit itself. The trial runs leave
+// no trace on the host: see isolateEstimation().
+func (host *vmHost) estimateGas(maxGasLimit uint64, run estimationRunFunction) (*arwen.GasEstimate, error) {
+	isolation := host.isolateEstimation()
+	defer isolation.restore()
+	run = isolation.wrap(run)
+
+	if maxGasLimit == 0 {
+		maxGasLimit = host.Metering().BlockGasLimit()
+	}
+
+	vmOutput, err := run(maxGasLimit)
+	err = checkEstimationRun(vmOutput, err)
+	if err != nil {
+		return nil, err
+	}
+
+	// The gas burned in this shard already contains the gas locked for the
+	// callbacks, because ComputeGasLockedForAsync() is deducted on the spot
+	lowGasLimit := gasBurnedInShard(maxGasLimit, vmOutput)
+
+	highGasLimit := maxGasLimit
+	highVMOutput := vmOutput
+	for lowGasLimit < highGasLimit {
+		gasLimit := lowGasLimit + (highGasLimit-lowGasLimit)/2
+		vmOutput, err = run(gasLimit)
+		if checkEstimationRun(vmOutput, err) != nil {
+			lowGasLimit = gasLimit + 1
+			continue
+		}
+
+		highGasLimit = gasLimit
+		highVMOutput = vmOutput
+	}
+
+	log.Trace("estimateGas", "gasLimit", highGasLimit, "maxGasLimit", maxGasLimit)
+
+	return &arwen.GasEstimate{
+		GasLimit:              highGasLimit,
+		GasUsed:               gasBurnedInShard(highGasLimit, highVMOutput),
+		GasLockedForCallbacks: gasLockedForCallbacks(highVMOutput),
+		HasCrossShardCalls:    hasCrossShardAsyncCalls(highVMOutput),
+	}, nil
+}
+
+// estimationIsolation holds the state of the host which the trial runs of an
+// estimation overwrite
+type estimationIsolation struct {
+	host              *vmHost
+	executionObserver arwen.ExecutionObserver
+	callTree          callTreeBuilder
+	asyncGasReport    *arwen.AsyncGasReport
+	ethInput          []byte
+	callbackClosure   []byte
+	executionDepth    int
+	panicked          bool
+
+	// the runtime state is not pushed onto the runtime state stack, because
+	// the depth of the stack determines the call depth of the trial runs
+	vmInput          *vmcommon.VMInput
+	scAddress        []byte
+	function         string
+	readOnly         bool
+	asyncCallInfo    *arwen.AsyncCallInfo
+	asyncContextInfo *arwen.AsyncContextInfo
+	runtimeClosure   []byte
+}
+
+// isolateEstimation saves the state of the host before an estimation. The
+// active state of each context except the runtime is pushed onto its state
+// stack, which the trial runs keep, and the execution observer and the call
+// tree are detached, so that the trial runs remain invisible outside the host.
+func (host *vmHost) isolateEstimation() *estimationIsolation {
+	runtime := host.Runtime()
+	isolation := &estimationIsolation{
+		host:              host,
+		executionObserver: host.executionObserver,
+		callTree:          *host.callTree,
+		asyncGasReport:    host.asyncGasReporter.report,
+		ethInput:          host.ethInput,
+		callbackClosure:   host.callbackClosure,
+		executionDepth:    host.executionDepth,
+		vmInput:           runtime.GetVMInput(),
+		scAddress:         runtime.GetSCAddress(),
+		function:          runtime.Function(),
+		readOnly:          runtime.ReadOnly(),
+		asyncCallInfo:     runtime.GetAsyncCallInfo(),
+		asyncContextInfo:  runtime.GetAsyncContextInfo(),
+		runtimeClosure:    runtime.GetCallbackClosure(),
+	}
+
+	host.executionObserver = nil
+	host.callTree.enabled = false
+	host.managedTypesContext.PushState()
+	host.outputContext.PushState()
+	host.meteringContext.PushState()
+	host.storageContext.PushState()
+	host.estimating = true
+
+	return isolation
+}
+
+// wrap makes each trial run start from the same blockchain state, reverting
+// the changes made through the blockchain hook, e.g. by built-in functions
+func (isolation *estimationIsolation) wrap(run estimationRunFunction) estimationRunFunction {
+	return func(gasLimit uint64) (*vmcommon.VMOutput, error) {
+		blockchain := isolation.host.Blockchain()
+		snapshot := blockchain.GetSnapshot()
+		defer blockchain.RevertToSnapshot(snapshot)
+
+		vmOutput, err := run(gasLimit)
+		if err != nil {
+			isolation.panicked = true
+		}
+
+		return vmOutput, err
+	}
+}
+
+// restore brings the host back to its state before the estimation. A trial
+// run which has panicked may have left the state stacks unbalanced, in which
+// case the contexts are reset instead, as after any panicking execution.
+func (isolation *estimationIsolation) restore() {
+	host := isolation.host
+	host.estimating = false
+	if isolation.panicked {
+		host.initContexts()
+	} else {
+		host.managedTypesContext.PopSetActiveState()
+		host.outputContext.PopSetActiveState()
+		host.meteringContext.PopSetActiveState()
+		host.storageContext.PopSetActiveState()
+
+		runtime := host.Runtime()
+		runtime.SetVMInput(isolation.vmInput)
+		runtime.SetSCAddress(isolation.scAddress)
+		runtime.SetCustomCallFunction(isolation.function)
+		runtime.SetReadOnly(isolation.readOnly)
+		runtime.SetAsyncCallInfo(isolation.asyncCallInfo)
+		runtime.SetAsyncContextInfo(isolation.asyncContextInfo)
+		runtime.SetCallbackClosure(isolation.runtimeClosure)
+	}
+
+	host.executionObserver = isolation.executionObserver
+	*host.callTree = isolation.callTree
+	host.asyncGasReporter.report = isolation.asyncGasReport
+	host.ethInput = isolation.ethInput
+	host.callbackClosure = isolation.callbackClosure
+	host.executionDepth = isolation.executionDepth
+}
+
+func checkEstimationRun(vmOutput *vmcommon.VMOutput, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w (%s)", arwen.ErrGasEstimationFailed, err.Error())
+	}
+	if vmOutput == nil {
+		return arwen.ErrGasEstimationFailed
+	}
+	if vmOutput.ReturnCode != vmcommon.Ok {
+		return fmt.Errorf("%w (%s: %s)", arwen.ErrGasEstimationFailed, vmOutput.ReturnCode, vmOutput.ReturnMessage)
+	}
+
+	return nil
+}
+
+// gasBurnedInShard returns the gas consumed by the execution itself, which
+// includes the gas locked for callbacks, but excludes the gas forwarded to
+// other shards by the output transfers
+func gasBurnedInShard(gasLimit uint64, vmOutput *vmcommon.VMOutput) uint64 {
+	gasBurned := math.SubUint64(gasLimit, vmOutput.GasRemaining)
+	for _, account := range vmOutput.OutputAccounts {
+		for _, transfer := range account.OutputTransfers {
+			gasBurned = math.SubUint64(gasBurned, transfer.GasLimit)
+		}
+	}
+
+	return gasBurned
+}
+
+// gasLockedForCallbacks returns the gas locked by ComputeGasLockedForAsync()
+// for the callbacks of the cross-shard async calls
+func gasLockedForCallbacks(vmOutput *vmcommon.VMOutput) uint64 {
+	gasLocked := uint64(0)
+	for _, account := range vmOutput.OutputAccounts {
+		for _, transfer := range account.OutputTransfers {
+			gasLocked = math.AddUint64(gasLocked, transfer.GasLocked)
+		}
+	}
+
+	return gasLocked
+}
+
+func hasCrossShardAsyncCalls(vmOutput *vmcommon.VMOutput) bool {
+	for _, account := range vmOutput.OutputAccounts {
+		for _, transfer := range account.OutputTransfers {
+			if transfer.CallType == vm.AsynchronousCall {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package host
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	"github.com/ElrondNetwork/elrond-go-core/core/check"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// HostPool owns several independent hosts, which run read-only queries
+// concurrently against the same BlockchainHook. Each host keeps its own
+// single-threaded contexts, while the gas schedule and the compiled-code
+// cache (kept by the BlockchainHook) are shared by all of them; therefore the
+// BlockchainHook, as well as any ExecutionObserver passed in the host
+// parameters, must be safe for concurrent use.
+type HostPool struct {
+	hosts     []*vmHost
+	available chan *vmHost
+}
+
+// NewHostPool creates a HostPool of numHosts hosts, all configured with the
+// given parameters
+func NewHostPool(
+	numHosts int,
+	blockChainHook vmcommon.BlockchainHook,
+	hostParameters *arwen.VMHostParameters,
+) (*HostPool, error) {
+	if numHosts <= 0 {
+		return nil, arwen.ErrInvalidNumberOfHosts
+	}
+	if check.IfNil(blockChainHook) {
+		return nil, arwen.ErrNilBlockChainHook
+	}
+
+	pool := &HostPool{
+		hosts:     make([]*vmHost, 0, numHosts),
+		available: make(chan *vmHost, numHosts),
+	}
+
+	for i := 0; i < numHosts; i++ {
+		host, err := newVMHost(blockChainHook, hostParameters)
+		if err != nil {
+			return nil, err
+		}
+
+		host.readOnlyQueries = true
+		pool.hosts = append(pool.hosts, host)
+		pool.available <- host
+	}
+
+	return pool, nil
+}
+
+// RunQuery executes the given contract call in read-only mode on the first
+// host available, waiting for one if all of them are busy
+func (pool *HostPool) RunQuery(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+	if input.Function == arwen.UpgradeFunctionName {
+		return nil, arwen.ErrUpgradeNotAllowedInQuery
+	}
+
+	host := <-pool.available
+	defer func() {
+		pool.available <- host
+	}()
+
+	return host.RunSmartContractCall(input)
+}
+
+// GasScheduleChange applies a new gas schedule to all the hosts of the pool,
+// each as soon as it has finished its current query
+func (pool *HostPool) GasScheduleChange(newGasSchedule config.GasScheduleMap) {
+	for _, host := range pool.hosts {
+		host.GasScheduleChange(newGasSchedule)
+	}
+}
+
+// GetHosts returns the hosts of the pool, for configuration purposes; they
+// must not be used to run executions outside the pool
+func (pool *HostPool) GetHosts() []arwen.VMHost {
+	hosts := make([]arwen.VMHost, len(pool.hosts))
+	for i, host := range pool.hosts {
+		hosts[i] = host
+	}
+
+	return hosts
+}
+
+// Len returns the number of hosts in the pool
+func (pool *HostPool) Len() int {
+	return len(pool.hosts)
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (pool *HostPool) IsInterfaceNil() bool {
+	return pool == nil
+}
@@ -0,0 +1,116 @@
+package host
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+)
+
+const panicReportFilePermissions = 0644
+
+// SetPanicReportDirectory sets the directory into which the reports of the
+// panics recovered during execution are written; an empty directory disables
+// writing the reports, which are still surfaced through GetRuntimeErrors()
+func (host *vmHost) SetPanicReportDirectory(directory string) {
+	host.panicReportDirectory = directory
+}
+
+// tryCatch behaves like TryCatch, except that it passes an
+// ExecutionPanicReport to the catch function and records it among the
+// runtime errors
+func (host *vmHost) tryCatch(try TryFunction, catch CatchFunction, operation string) {
+	host.panicReport = nil
+	defer func() {
+		if r := recover(); r != nil {
+			report := host.panicReport
+			host.panicReport = nil
+			if report == nil {
+				report = host.newExecutionPanicReport(r)
+			}
+			report.Operation = operation
+
+			host.writeExecutionPanicReport(report)
+			if host.runtimeContext != nil {
+				host.runtimeContext.AddError(report, operation)
+			}
+
+			catch(report)
+		}
+	}()
+
+	try()
+}
+
+// recordPanicReport must be deferred by the functions which call into
+// contracts: it captures the state of the runtime at the point of a panic,
+// before the deferred functions of the enclosing executions unwind it, then
+// resumes panicking
+func (host *vmHost) recordPanicReport() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if host.panicReport == nil {
+		host.panicReport = host.newExecutionPanicReport(r)
+	}
+	panic(r)
+}
+
+func (host *vmHost) newExecutionPanicReport(panicValue interface{}) *arwen.ExecutionPanicReport {
+	report := &arwen.ExecutionPanicReport{
+		PanicValue: panicValue,
+		Stack:      debug.Stack(),
+	}
+
+	host.fillExecutionPanicReport(report)
+	return report
+}
+
+// fillExecutionPanicReport reads the state of the runtime into the report;
+// the runtime may have been left inconsistent by the panic, so any further
+// panic is discarded, leaving the remaining fields empty
+func (host *vmHost) fillExecutionPanicReport(report *arwen.ExecutionPanicReport) {
+	defer func() {
+		_ = recover()
+	}()
+
+	runtime := host.runtimeContext
+	if runtime == nil {
+		return
+	}
+
+	report.ContractAddress = runtime.GetSCAddress()
+	report.Function = runtime.Function()
+	report.CallDepth = runtime.CallDepth()
+	if runtime.GetInstance() != nil {
+		report.Breakpoint = runtime.GetRuntimeBreakpointValue()
+	}
+}
+
+func (host *vmHost) writeExecutionPanicReport(report *arwen.ExecutionPanicReport) {
+	if len(host.panicReportDirectory) == 0 {
+		return
+	}
+
+	err := os.MkdirAll(host.panicReportDirectory, os.ModePerm)
+	if err != nil {
+		log.Error("cannot create panic report directory", "directory", host.panicReportDirectory, "error", err)
+		return
+	}
+
+	fileName := fmt.Sprintf("arwen-panic-%d.txt", time.Now().UnixNano())
+	filePath := filepath.Join(host.panicReportDirectory, fileName)
+	err = ioutil.WriteFile(filePath, []byte(report.String()), panicReportFilePermissions)
+	if err != nil {
+		log.Error("cannot write panic report", "file", filePath, "error", err)
+		return
+	}
+
+	log.Warn("panic report written", "file", filePath)
+}
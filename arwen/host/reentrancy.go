@@ -0,0 +1,46 @@
+package host
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+func newReentrancyProtectedSet(addresses [][]byte) map[string]struct{} {
+	protected := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		protected[string(address)] = struct{}{}
+	}
+
+	return protected
+}
+
+// SetReentrancyProtectedContracts replaces the allowlist of contracts which
+// cannot be reentered through ExecuteOnDestContext() or ExecuteOnSameContext()
+// while already executing further down the stack
+func (host *vmHost) SetReentrancyProtectedContracts(addresses [][]byte) {
+	host.reentrancyProtected = newReentrancyProtectedSet(addresses)
+}
+
+func (host *vmHost) isReentrancyProtected(address []byte) bool {
+	_, protected := host.reentrancyProtected[string(address)]
+	return protected
+}
+
+// checkReentrancy must be called after the runtime state of the caller has
+// been pushed, so that the caller itself is found on the stack. Callbacks are
+// exempt, because they always return into a contract which is on the stack.
+func (host *vmHost) checkReentrancy(input *vmcommon.ContractCallInput) error {
+	if input.CallType == vm.AsynchronousCallBack {
+		return nil
+	}
+	if !host.isReentrancyProtected(input.RecipientAddr) {
+		return nil
+	}
+	if !host.Runtime().IsContractOnTheStack(input.RecipientAddr) {
+		return nil
+	}
+
+	log.Trace("reentrancy rejected", "address", input.RecipientAddr, "function", input.Function)
+	return arwen.ErrReentrancyNotAllowed
+}
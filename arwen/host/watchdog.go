@@ -0,0 +1,145 @@
+package host
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
+)
+
+// executionWatchdog stops an execution which exceeds its wall-clock budget,
+// by setting BreakpointTimeout on the Wasmer instance currently running. The
+// timer fires on its own goroutine, therefore the running instance is
+// tracked under a mutex, instead of being read from the RuntimeContext.
+type executionWatchdog struct {
+	mutex    sync.Mutex
+	timeout  time.Duration
+	clock    arwen.ExecutionClock
+	timer    arwen.ExecutionTimer
+	expired  bool
+	instance wasmer.InstanceHandler
+}
+
+func newExecutionWatchdog(timeout time.Duration) *executionWatchdog {
+	return &executionWatchdog{
+		timeout: timeout,
+		clock:   &systemClock{},
+	}
+}
+
+// systemClock starts the timers of the watchdog on the system clock
+type systemClock struct {
+}
+
+// AfterFunc calls the given function on its own goroutine, once the given
+// duration has elapsed
+func (clock *systemClock) AfterFunc(duration time.Duration, expire func()) arwen.ExecutionTimer {
+	return time.AfterFunc(duration, expire)
+}
+
+// SetExecutionTimeout sets the wall-clock budget of each execution started
+// by the host; a timeout of 0 disables the watchdog
+func (host *vmHost) SetExecutionTimeout(timeout time.Duration) {
+	host.watchdog.mutex.Lock()
+	host.watchdog.timeout = timeout
+	host.watchdog.mutex.Unlock()
+}
+
+// SetExecutionClock replaces the clock which starts the timers of the
+// watchdog; a nil clock restores the system clock
+func (host *vmHost) SetExecutionClock(clock arwen.ExecutionClock) {
+	if clock == nil {
+		clock = &systemClock{}
+	}
+
+	host.watchdog.mutex.Lock()
+	host.watchdog.clock = clock
+	host.watchdog.mutex.Unlock()
+}
+
+func (watchdog *executionWatchdog) start() {
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	watchdog.expired = false
+	watchdog.instance = nil
+	if watchdog.timeout == 0 {
+		return
+	}
+
+	watchdog.timer = watchdog.clock.AfterFunc(watchdog.timeout, watchdog.expire)
+}
+
+func (watchdog *executionWatchdog) stop() {
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	if watchdog.timer != nil {
+		watchdog.timer.Stop()
+		watchdog.timer = nil
+	}
+	watchdog.instance = nil
+}
+
+func (watchdog *executionWatchdog) expire() {
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	if watchdog.timer == nil {
+		return
+	}
+
+	watchdog.expired = true
+	log.Debug("execution timeout reached", "timeout", watchdog.timeout)
+	watchdog.interruptInstance()
+}
+
+func (watchdog *executionWatchdog) isExpired() bool {
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	return watchdog.expired
+}
+
+// enterInstance marks the given instance as the one currently running and
+// returns the previously running instance, to be restored with
+// exitInstance() when the given instance returns
+func (watchdog *executionWatchdog) enterInstance(instance wasmer.InstanceHandler) wasmer.InstanceHandler {
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	previousInstance := watchdog.instance
+	watchdog.instance = instance
+	watchdog.interruptInstance()
+
+	return previousInstance
+}
+
+// exitInstance restores the previously running instance, which must also be
+// stopped if the budget was exceeded while it was waiting for the other one
+func (watchdog *executionWatchdog) exitInstance(previousInstance wasmer.InstanceHandler) {
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	watchdog.instance = previousInstance
+	watchdog.interruptInstance()
+}
+
+func (watchdog *executionWatchdog) interruptInstance() {
+	if !watchdog.expired || watchdog.instance == nil {
+		return
+	}
+
+	watchdog.instance.SetBreakpointValue(uint64(arwen.BreakpointTimeout))
+}
+
+// callFunction calls the given exported function of the given instance,
+// which is marked as the running instance until the function returns
+func (watchdog *executionWatchdog) callFunction(instance wasmer.InstanceHandler, function wasmer.ExportedFunctionCallback) error {
+	previousInstance := watchdog.enterInstance(instance)
+	defer watchdog.exitInstance(previousInstance)
+
+	_, err := function()
+	return err
+}
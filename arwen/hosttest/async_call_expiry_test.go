@@ -0,0 +1,173 @@
+package hosttest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+var asyncCallExpiryTxHash = []byte("escrowTxHash")
+
+const asyncCallExpiryNonce = uint64(10)
+
+const asyncCallExpiryGasLocked = uint64(1000)
+
+func createAsyncCallExpiryParentMock(t *testing.T, host arwen.VMHost, imb *mock.InstanceBuilderMock) {
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("lockFunds", func() *mock.InstanceMock {
+		runtime := parentInstance.Host.Runtime()
+		parentInstance.Host.Metering().UseGas(asyncCallExpiryGasLocked)
+		err := runtime.AddAsyncContextCall([]byte("escrow"), &arwen.AsyncGeneratedCall{
+			Destination:     asyncContextsRemoteAddressA,
+			Data:            []byte("remoteWork"),
+			SuccessCallback: "callOk",
+			ErrorCallback:   "callFailed",
+			ExpiryNonce:     asyncCallExpiryNonce,
+			GasLocked:       asyncCallExpiryGasLocked,
+		})
+		require.Nil(t, err)
+
+		asyncContext, err := runtime.GetAsyncContext([]byte("escrow"))
+		require.Nil(t, err)
+		asyncContext.Callback = "contextDone"
+
+		return parentInstance
+	})
+	addFinishingMockMethod(parentInstance, "callOk", false)
+	parentInstance.AddMockMethod("callFailed", func() *mock.InstanceMock {
+		host := parentInstance.Host
+		host.Output().Finish([]byte("callFailed"))
+		for _, argument := range host.Runtime().Arguments() {
+			host.Output().Finish(argument)
+		}
+		host.Output().Finish(host.Runtime().GetOriginalTxHash())
+		return parentInstance
+	})
+	addFinishingMockMethod(parentInstance, "contextDone", true)
+}
+
+func runAsyncCallExpiryResolution(host arwen.VMHost, world *worldmock.MockWorld, nonce uint64) (*vmcommon.VMOutput, error) {
+	return runAsyncCallExpiryResolutionBy(host, world, nonce, test.UserAddress)
+}
+
+func runAsyncCallExpiryResolutionBy(host arwen.VMHost, world *worldmock.MockWorld, nonce uint64, caller []byte) (*vmcommon.VMOutput, error) {
+	world.SetCurrentBlockInfo(&worldmock.BlockInfo{BlockNonce: nonce})
+	return host.RunSmartContractCall(test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(caller).
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.ResolveExpiredAsyncCallsFunctionName).
+		WithArguments(asyncCallExpiryTxHash).
+		Build())
+}
+
+func TestAsyncCallExpiry_ErrorCallbackOnTimeout(t *testing.T) {
+	host, world, imb := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncCallExpiryFeature)
+	createAsyncCallExpiryParentMock(t, host, imb)
+
+	vmOutput := runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("lockFunds").
+		WithOriginalTxHash(asyncCallExpiryTxHash).
+		Build())
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok()
+
+	remoteAccount := vmOutput.OutputAccounts[string(asyncContextsRemoteAddressA)]
+	require.NotNil(t, remoteAccount)
+	require.Len(t, remoteAccount.OutputTransfers, 1)
+	require.Equal(t, asyncCallExpiryGasLocked, remoteAccount.OutputTransfers[0].GasLocked)
+
+	// the expiry has not been reached yet
+	vmOutput, err := runAsyncCallExpiryResolution(host, world, asyncCallExpiryNonce-1)
+	require.Nil(t, err)
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.ReturnMessage(arwen.ErrNoExpiredAsyncCalls.Error())
+
+	// only the original caller or the contract may resolve the expired calls
+	vmOutput, err = runAsyncCallExpiryResolutionBy(host, world, asyncCallExpiryNonce, asyncContextsRemoteAddressA)
+	require.Nil(t, err)
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.ReturnMessage(arwen.ErrExpiryResolutionNotAllowed.Error())
+
+	// the error callback receives the timeout code, then the context is
+	// finished and the stored data is removed; the gas locked for the callback
+	// was paid by the original transaction, so it is not refunded
+	vmOutput, err = runAsyncCallExpiryResolution(host, world, asyncCallExpiryNonce)
+	require.Nil(t, err)
+	err = world.UpdateAccounts(vmOutput.OutputAccounts, vmOutput.DeletedAccounts)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(0), vmOutput.GasRefund)
+
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData(
+			[]byte("callFailed"),
+			big.NewInt(int64(arwen.AsyncCallTimeout)).Bytes(),
+			[]byte(arwen.ErrAsyncCallExpired.Error()),
+			asyncCallExpiryTxHash,
+			[]byte("contextDone"), []byte("escrow"),
+		)
+
+	storageKey := arwen.CustomStorageKey(arwen.AsyncDataPrefix, asyncCallExpiryTxHash)
+	storageUpdate := vmOutput.OutputAccounts[string(test.ParentAddress)].StorageUpdates[string(storageKey)]
+	require.NotNil(t, storageUpdate)
+	require.Len(t, storageUpdate.Data, 0)
+
+	// the expired async call cannot be resolved twice
+	vmOutput, err = runAsyncCallExpiryResolution(host, world, asyncCallExpiryNonce+1)
+	require.Nil(t, err)
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.ReturnMessage(arwen.ErrNoExpiredAsyncCalls.Error())
+
+	// a response arriving after the expiry no longer executes a callback
+	vmOutput = runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(asyncContextsRemoteAddressA).
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.CallbackFunctionName).
+		WithArguments([]byte(vmcommon.Ok.String())).
+		WithCallType(vm.AsynchronousCallBack).
+		WithOriginalTxHash(asyncCallExpiryTxHash).
+		Build())
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData()
+}
+
+func TestAsyncCallExpiry_ExportedResolutionFunctionIsCalled(t *testing.T) {
+	host, world, imb := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncCallExpiryFeature)
+
+	// a contract deployed before the activation of the feature keeps its own
+	// function with the reserved name
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	addFinishingMockMethod(parentInstance, arwen.ResolveExpiredAsyncCallsFunctionName, false)
+
+	vmOutput, err := runAsyncCallExpiryResolution(host, world, asyncCallExpiryNonce)
+	require.Nil(t, err)
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte(arwen.ResolveExpiredAsyncCallsFunctionName))
+}
+
+func TestAsyncCallExpiry_IsExpired(t *testing.T) {
+	asyncCall := &arwen.AsyncGeneratedCall{}
+	require.False(t, asyncCall.IsExpired(100, 100))
+
+	asyncCall.ExpiryNonce = 10
+	require.False(t, asyncCall.IsExpired(9, 100))
+	require.True(t, asyncCall.IsExpired(10, 0))
+
+	asyncCall.ExpiryNonce = 0
+	asyncCall.ExpiryTimestamp = 1000
+	require.False(t, asyncCall.IsExpired(100, 999))
+	require.True(t, asyncCall.IsExpired(0, 1000))
+}
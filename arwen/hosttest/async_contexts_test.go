@@ -0,0 +1,372 @@
+package hosttest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+var asyncContextsRemoteAddressA = test.MakeTestSCAddress("remoteSC_A")
+var asyncContextsRemoteAddressB = test.MakeTestSCAddress("remoteSC_B")
+
+const asyncContextsGasProvided = uint64(1000000)
+
+func addFinishingMockMethod(instance *mock.InstanceMock, name string, finishArguments bool) {
+	instance.AddMockMethod(name, func() *mock.InstanceMock {
+		host := instance.Host
+		host.Output().Finish([]byte(name))
+		if finishArguments {
+			for _, argument := range host.Runtime().Arguments() {
+				host.Output().Finish(argument)
+			}
+		}
+		return instance
+	})
+}
+
+func createAsyncContextsParentMock(t *testing.T, host arwen.VMHost, imb *mock.InstanceBuilderMock) {
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("fanOut", func() *mock.InstanceMock {
+		runtime := parentInstance.Host.Runtime()
+		metering := parentInstance.Host.Metering()
+		addAsyncCall := func(contextIdentifier string, destination []byte, data string) {
+			// lock the gas for the callbacks, as createAsyncCall() does
+			gasLocked := metering.ComputeGasLockedForAsync()
+			err := metering.UseGasBounded(gasLocked)
+			require.Nil(t, err)
+
+			err = runtime.AddAsyncContextCall([]byte(contextIdentifier), &arwen.AsyncGeneratedCall{
+				Destination:     destination,
+				Data:            []byte(data),
+				SuccessCallback: "callOk",
+				ErrorCallback:   "callFailed",
+				GasLocked:       gasLocked,
+			})
+			require.Nil(t, err)
+		}
+
+		addAsyncCall("bridge", test.ChildAddress, "localWork")
+		addAsyncCall("bridge", asyncContextsRemoteAddressA, "remoteWork")
+		addAsyncCall("bridge", asyncContextsRemoteAddressB, "remoteFail")
+		addAsyncCall("local", test.ChildAddress, "localWork")
+
+		for _, contextIdentifier := range []string{"bridge", "local"} {
+			asyncContext, err := runtime.GetAsyncContext([]byte(contextIdentifier))
+			require.Nil(t, err)
+			asyncContext.Callback = "contextDone"
+		}
+
+		return parentInstance
+	})
+	addFinishingMockMethod(parentInstance, "callOk", false)
+	addFinishingMockMethod(parentInstance, "callFailed", false)
+	addFinishingMockMethod(parentInstance, "contextDone", true)
+
+	childInstance := imb.CreateAndStoreInstanceMock(t, host, test.ChildAddress, 0, 0)
+	addFinishingMockMethod(childInstance, "localWork", false)
+}
+
+func createAsyncContextsRemoteMocks(t *testing.T, host arwen.VMHost, imb *mock.InstanceBuilderMock) {
+	for _, address := range [][]byte{asyncContextsRemoteAddressA, asyncContextsRemoteAddressB} {
+		remoteInstance := imb.CreateAndStoreInstanceMock(t, host, address, 1, 0)
+		addFinishingMockMethod(remoteInstance, "remoteWork", false)
+		remoteInstance.AddMockMethod("remoteFail", func() *mock.InstanceMock {
+			host.Runtime().SignalUserError("remote failed")
+			return mock.GetMockInstance(host)
+		})
+	}
+}
+
+func runAsyncContextsCall(t *testing.T, host arwen.VMHost, world *worldmock.MockWorld, input *vmcommon.ContractCallInput) *vmcommon.VMOutput {
+	vmOutput, err := host.RunSmartContractCall(input)
+	require.Nil(t, err)
+	require.NotNil(t, vmOutput)
+
+	err = world.UpdateAccounts(vmOutput.OutputAccounts, vmOutput.DeletedAccounts)
+	require.Nil(t, err)
+
+	return vmOutput
+}
+
+func getStoredAsyncContexts(t *testing.T, vmOutput *vmcommon.VMOutput) *arwen.AsyncContextInfo {
+	storageKey := arwen.CustomStorageKey(arwen.AsyncDataPrefix, nil)
+	parentAccount := vmOutput.OutputAccounts[string(test.ParentAddress)]
+	require.NotNil(t, parentAccount)
+	storageUpdate := parentAccount.StorageUpdates[string(storageKey)]
+	require.NotNil(t, storageUpdate)
+	if len(storageUpdate.Data) == 0 {
+		return nil
+	}
+
+	asyncInfo := &arwen.AsyncContextInfo{}
+	err := json.Unmarshal(storageUpdate.Data, asyncInfo)
+	require.Nil(t, err)
+	return asyncInfo
+}
+
+// getCallbackArguments returns the arguments of the callback which the
+// destination shard sends back for the given execution of an async call
+func getCallbackArguments(t *testing.T, vmOutput *vmcommon.VMOutput) [][]byte {
+	if vmOutput.ReturnCode != vmcommon.Ok {
+		return [][]byte{[]byte(vmOutput.ReturnCode.String()), []byte(vmOutput.ReturnMessage)}
+	}
+
+	parentAccount := vmOutput.OutputAccounts[string(test.ParentAddress)]
+	require.NotNil(t, parentAccount)
+	require.Len(t, parentAccount.OutputTransfers, 1)
+	callbackTransfer := parentAccount.OutputTransfers[0]
+	require.Equal(t, vm.AsynchronousCallBack, callbackTransfer.CallType)
+
+	arguments := make([][]byte, 0)
+	for _, encodedArgument := range strings.Split(string(callbackTransfer.Data), "@")[1:] {
+		argument, err := hex.DecodeString(encodedArgument)
+		require.Nil(t, err)
+		arguments = append(arguments, argument)
+	}
+	return arguments
+}
+
+func TestAsyncContexts_CrossShardLifecycle(t *testing.T) {
+	hostShard0, worldShard0, imbShard0 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncContextsFeature)
+	worldShard0.SelfShardID = 0
+	createAsyncContextsParentMock(t, hostShard0, imbShard0)
+
+	hostShard1, worldShard1, imbShard1 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncContextsFeature)
+	worldShard1.SelfShardID = 1
+	createAsyncContextsRemoteMocks(t, hostShard1, imbShard1)
+
+	// shard 0: the local calls are executed synchronously, completing the
+	// "local" context, while the remote calls are sent to shard 1
+	vmOutput := runAsyncContextsCall(t, hostShard0, worldShard0, test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("fanOut").
+		Build())
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData(
+			[]byte("localWork"), []byte("callOk"),
+			[]byte("localWork"), []byte("callOk"),
+			[]byte("contextDone"), []byte("local"),
+		)
+
+	remoteCallInputs := make([]*vmcommon.ContractCallInput, 0)
+	for _, address := range [][]byte{asyncContextsRemoteAddressA, asyncContextsRemoteAddressB} {
+		remoteAccount := vmOutput.OutputAccounts[string(address)]
+		require.NotNil(t, remoteAccount)
+		require.Len(t, remoteAccount.OutputTransfers, 1)
+		asyncTransfer := remoteAccount.OutputTransfers[0]
+		require.Equal(t, vm.AsynchronousCall, asyncTransfer.CallType)
+
+		remoteCallInputs = append(remoteCallInputs, test.CreateTestContractCallInputBuilder().
+			WithCallerAddr(test.ParentAddress).
+			WithRecipientAddr(address).
+			WithGasProvided(asyncContextsGasProvided).
+			WithFunction(string(asyncTransfer.Data)).
+			WithCallType(vm.AsynchronousCall).
+			Build())
+	}
+
+	asyncInfo := getStoredAsyncContexts(t, vmOutput)
+	require.NotNil(t, asyncInfo)
+	require.Len(t, asyncInfo.AsyncContextMap, 1)
+	bridgeContext := asyncInfo.AsyncContextMap["bridge"]
+	require.NotNil(t, bridgeContext)
+	require.Equal(t, "contextDone", bridgeContext.Callback)
+	require.Len(t, bridgeContext.AsyncCalls, 3)
+	require.Equal(t, arwen.AsyncCallResolved, bridgeContext.AsyncCalls[0].Status)
+	require.Equal(t, arwen.AsyncCallPending, bridgeContext.AsyncCalls[1].Status)
+	require.Equal(t, arwen.AsyncCallPending, bridgeContext.AsyncCalls[2].Status)
+
+	// shard 1: the remote calls are executed, one of them failing
+	remoteOutputA := runAsyncContextsCall(t, hostShard1, worldShard1, remoteCallInputs[0])
+	require.Equal(t, vmcommon.Ok, remoteOutputA.ReturnCode)
+	remoteOutputB := runAsyncContextsCall(t, hostShard1, worldShard1, remoteCallInputs[1])
+	require.Equal(t, vmcommon.UserError, remoteOutputB.ReturnCode)
+
+	// shard 0: the callback of the first remote call is received, but the
+	// "bridge" context still waits for the second one
+	vmOutput = runAsyncContextsCall(t, hostShard0, worldShard0, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(asyncContextsRemoteAddressA).
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.CallbackFunctionName).
+		WithArguments(getCallbackArguments(t, remoteOutputA)...).
+		WithCallType(vm.AsynchronousCallBack).
+		Build())
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callOk"))
+
+	asyncInfo = getStoredAsyncContexts(t, vmOutput)
+	require.NotNil(t, asyncInfo)
+	bridgeContext = asyncInfo.AsyncContextMap["bridge"]
+	require.Equal(t, arwen.AsyncCallResolved, bridgeContext.AsyncCalls[1].Status)
+	require.Equal(t, arwen.AsyncCallPending, bridgeContext.AsyncCalls[2].Status)
+
+	// shard 0: the callback of the failed remote call completes the "bridge"
+	// context, whose callback is executed, and the stored data is removed
+	vmOutput = runAsyncContextsCall(t, hostShard0, worldShard0, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(asyncContextsRemoteAddressB).
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.CallbackFunctionName).
+		WithArguments(getCallbackArguments(t, remoteOutputB)...).
+		WithCallType(vm.AsynchronousCallBack).
+		Build())
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callFailed"), []byte("contextDone"), []byte("bridge"))
+	require.Nil(t, getStoredAsyncContexts(t, vmOutput))
+}
+
+func TestAsyncContexts_CallbacksFollowCreationOrder(t *testing.T) {
+	host, world, imb := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncContextsFeature)
+	world.SelfShardID = 0
+
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("fanOut", func() *mock.InstanceMock {
+		runtime := parentInstance.Host.Runtime()
+		// the contexts are created in the reverse order of their identifiers
+		for _, contextIdentifier := range []string{"zeta", "alpha"} {
+			data := "remoteWork"
+			if contextIdentifier == "alpha" {
+				data = "remoteFail"
+			}
+			err := runtime.AddAsyncContextCall([]byte(contextIdentifier), &arwen.AsyncGeneratedCall{
+				Destination:     asyncContextsRemoteAddressA,
+				Data:            []byte(data),
+				SuccessCallback: "callOk",
+				ErrorCallback:   "callFailed",
+			})
+			require.Nil(t, err)
+
+			asyncContext, err := runtime.GetAsyncContext([]byte(contextIdentifier))
+			require.Nil(t, err)
+			asyncContext.Callback = "contextDone"
+		}
+
+		return parentInstance
+	})
+	addFinishingMockMethod(parentInstance, "callOk", false)
+	addFinishingMockMethod(parentInstance, "callFailed", false)
+	addFinishingMockMethod(parentInstance, "contextDone", true)
+
+	vmOutput := runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("fanOut").
+		Build())
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+
+	remoteAccount := vmOutput.OutputAccounts[string(asyncContextsRemoteAddressA)]
+	require.NotNil(t, remoteAccount)
+	require.Len(t, remoteAccount.OutputTransfers, 2)
+	require.Equal(t, []byte("remoteWork"), remoteAccount.OutputTransfers[0].Data)
+	require.Equal(t, []byte("remoteFail"), remoteAccount.OutputTransfers[1].Data)
+
+	asyncInfo := getStoredAsyncContexts(t, vmOutput)
+	require.NotNil(t, asyncInfo)
+	require.Equal(t, uint64(0), asyncInfo.AsyncContextMap["zeta"].AsyncCalls[0].Sequence)
+	require.Equal(t, uint64(1), asyncInfo.AsyncContextMap["alpha"].AsyncCalls[0].Sequence)
+
+	// the first callback belongs to the call created first, although its
+	// context comes last in the order of the identifiers
+	vmOutput = runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(asyncContextsRemoteAddressA).
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.CallbackFunctionName).
+		WithArguments([]byte(vmcommon.Ok.String()), []byte("remoteWork")).
+		WithCallType(vm.AsynchronousCallBack).
+		Build())
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callOk"), []byte("contextDone"), []byte("zeta"))
+
+	vmOutput = runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(asyncContextsRemoteAddressA).
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.CallbackFunctionName).
+		WithArguments([]byte(vmcommon.UserError.String()), []byte("remote failed")).
+		WithCallType(vm.AsynchronousCallBack).
+		Build())
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callFailed"), []byte("contextDone"), []byte("alpha"))
+	require.Nil(t, getStoredAsyncContexts(t, vmOutput))
+}
+
+func TestAsyncContexts_InShardCallerIsCalledBack(t *testing.T) {
+	host, world, imb := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncContextsFeature)
+	world.SelfShardID = 0
+
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	addFinishingMockMethod(parentInstance, arwen.CallbackFunctionName, true)
+
+	childInstance := imb.CreateAndStoreInstanceMock(t, host, test.ChildAddress, 0, 0)
+	childInstance.AddMockMethod("relay", func() *mock.InstanceMock {
+		runtime := childInstance.Host.Runtime()
+		childInstance.Host.Output().Finish([]byte("relaying"))
+		err := runtime.AddAsyncContextCall([]byte("relay"), &arwen.AsyncGeneratedCall{
+			Destination:     asyncContextsRemoteAddressA,
+			Data:            []byte("remoteWork"),
+			SuccessCallback: "callOk",
+			ErrorCallback:   "callFailed",
+		})
+		require.Nil(t, err)
+
+		asyncContext, err := runtime.GetAsyncContext([]byte("relay"))
+		require.Nil(t, err)
+		asyncContext.Callback = "contextDone"
+		return childInstance
+	})
+	addFinishingMockMethod(childInstance, "callOk", false)
+	addFinishingMockMethod(childInstance, "callFailed", false)
+	addFinishingMockMethod(childInstance, "contextDone", true)
+
+	// the parent, from the same shard, calls the child asynchronously, which
+	// relays the call to another shard before answering
+	vmOutput := runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(test.ParentAddress).
+		WithRecipientAddr(test.ChildAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("relay").
+		WithCallType(vm.AsynchronousCall).
+		Build())
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("relaying"))
+	parentAccount := vmOutput.OutputAccounts[string(test.ParentAddress)]
+	require.True(t, parentAccount == nil || len(parentAccount.OutputTransfers) == 0)
+
+	// the callback from the other shard finishes the context of the child,
+	// which then calls back the parent directly, with its own return data
+	vmOutput = runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithCallerAddr(asyncContextsRemoteAddressA).
+		WithRecipientAddr(test.ChildAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction(arwen.CallbackFunctionName).
+		WithArguments([]byte(vmcommon.Ok.String()), []byte("remoteWork")).
+		WithCallType(vm.AsynchronousCallBack).
+		Build())
+	verify = test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData(
+			[]byte("callOk"),
+			[]byte("contextDone"), []byte("relay"),
+			[]byte(arwen.CallbackFunctionName), []byte(vmcommon.Ok.String()), []byte("relaying"),
+		)
+	parentAccount = vmOutput.OutputAccounts[string(test.ParentAddress)]
+	require.True(t, parentAccount == nil || len(parentAccount.OutputTransfers) == 0)
+}
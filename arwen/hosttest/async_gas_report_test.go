@@ -0,0 +1,162 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/contracts"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func requireAsyncGasEntryBalanced(t *testing.T, entry *arwen.AsyncCallGasEntry) {
+	spent := entry.GasUsedByDestination + entry.GasUsedByCallback + entry.GasRefunded
+	require.Equal(t, entry.GasProvided+entry.GasLocked, spent)
+}
+
+func TestAsyncGasReport_Disabled(t *testing.T) {
+	host, _, _ := test.DefaultTestArwenForCallWithInstanceMocks(t)
+	require.Nil(t, host.GetAsyncGasReport())
+
+	host.SetAsyncGasReportEnabled(true)
+	require.NotNil(t, host.GetAsyncGasReport())
+	require.Len(t, host.GetAsyncGasReport().Entries, 0)
+
+	host.SetAsyncGasReportEnabled(false)
+	require.Nil(t, host.GetAsyncGasReport())
+}
+
+func TestAsyncGasReport_AsyncCall(t *testing.T) {
+	testConfig := asyncTestConfig
+	testConfig.GasProvided = 1000
+
+	var testHost arwen.VMHost
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			test.CreateMockContract(test.ParentAddress).
+				WithBalance(testConfig.ParentBalance).
+				WithConfig(testConfig).
+				WithMethods(contracts.PerformAsyncCallParentMock, contracts.CallBackParentMock),
+			test.CreateMockContract(test.ChildAddress).
+				WithBalance(testConfig.ChildBalance).
+				WithConfig(testConfig).
+				WithMethods(contracts.TransferToThirdPartyAsyncChildMock),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(testConfig.GasProvided).
+			WithFunction("performAsyncCall").
+			WithArguments([]byte{0}).
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			testHost = host
+			host.SetAsyncGasReportEnabled(true)
+			setZeroCodeCosts(host)
+			setAsyncCosts(host, testConfig.GasLockCost)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			report := testHost.GetAsyncGasReport()
+			require.NotNil(t, report)
+			require.Len(t, report.Entries, 1)
+
+			entry := report.Entries[0]
+			require.Equal(t, "", entry.ContextIdentifier)
+			require.Equal(t, test.ChildAddress, entry.Destination)
+			require.False(t, entry.CrossShard)
+			require.Equal(t, vmcommon.Ok, entry.DestinationReturnCode)
+			require.Equal(t, testConfig.GasLockCost, entry.GasLocked)
+			require.Equal(t, testConfig.GasUsedByChild, entry.GasUsedByDestination)
+			require.Equal(t, testConfig.GasUsedByCallback, entry.GasUsedByCallback)
+			requireAsyncGasEntryBalanced(t, entry)
+		})
+}
+
+func TestAsyncGasReport_AsyncCall_CrossShard(t *testing.T) {
+	testConfig := asyncTestConfig
+	testConfig.GasProvided = 1000
+
+	gasUsedByParent := testConfig.GasUsedByParent
+	gasUsedByChild := testConfig.GasUsedByChild
+	gasForAsyncCall := testConfig.GasProvided - gasUsedByParent - testConfig.GasLockCost
+
+	var testHost arwen.VMHost
+	parentContract := test.CreateMockContractOnShard(test.ParentAddress, 0).
+		WithBalance(testConfig.ParentBalance).
+		WithConfig(testConfig).
+		WithMethods(contracts.PerformAsyncCallParentMock, contracts.CallBackParentMock)
+
+	// the sending transaction only reports the gas provided and locked
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(parentContract).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithCallerAddr(test.UserAddress).
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(testConfig.GasProvided).
+			WithFunction("performAsyncCall").
+			WithArguments([]byte{0}).
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			testHost = host
+			host.SetAsyncGasReportEnabled(true)
+			world.SelfShardID = 0
+			world.CurrentBlockInfo.BlockRound = 0
+			setZeroCodeCosts(host)
+			setAsyncCosts(host, testConfig.GasLockCost)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			report := testHost.GetAsyncGasReport()
+			require.Len(t, report.Entries, 1)
+
+			entry := report.Entries[0]
+			require.Equal(t, test.ChildAddress, entry.Destination)
+			require.True(t, entry.CrossShard)
+			require.False(t, entry.ReceivedCallback)
+			require.Equal(t, gasForAsyncCall, entry.GasProvided)
+			require.Equal(t, testConfig.GasLockCost, entry.GasLocked)
+		})
+
+	// the transaction which receives the callback reports the gas it used
+	gasForCallback := gasForAsyncCall - gasUsedByChild + testConfig.GasLockCost
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(parentContract).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithCallerAddr(test.ChildAddress).
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(gasForCallback).
+			WithFunction("callBack").
+			WithArguments([]byte{}, []byte{0}, []byte("thirdparty"), []byte("vault")).
+			WithCallType(vm.AsynchronousCallBack).
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			testHost = host
+			host.SetAsyncGasReportEnabled(true)
+			world.SelfShardID = 0
+			world.CurrentBlockInfo.BlockRound = 2
+			accountHandler, _ := world.GetUserAccount(test.ParentAddress)
+			(accountHandler.(*worldmock.Account)).Storage[string(test.ParentKeyA)] = test.ParentDataA
+			(accountHandler.(*worldmock.Account)).Storage[string(test.ParentKeyB)] = test.ParentDataB
+			setZeroCodeCosts(host)
+			setAsyncCosts(host, testConfig.GasLockCost)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			report := testHost.GetAsyncGasReport()
+			require.Len(t, report.Entries, 1)
+
+			entry := report.Entries[0]
+			require.Equal(t, test.ChildAddress, entry.Destination)
+			require.True(t, entry.ReceivedCallback)
+			require.Equal(t, gasForCallback, entry.GasProvided)
+			require.Equal(t, testConfig.GasUsedByCallback, entry.GasUsedByCallback)
+			require.Equal(t, gasForCallback-testConfig.GasUsedByCallback, entry.GasRefunded)
+			requireAsyncGasEntryBalanced(t, entry)
+		})
+}
@@ -0,0 +1,101 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+// recursionSafetyDepth stops the recursion below the limit of Wasmer instances
+const recursionSafetyDepth = 8
+
+type recursionResult struct {
+	deepestCallDepth int
+	rejectedCallErr  error
+	rejectedCallCode vmcommon.ReturnCode
+}
+
+// recursiveCallerMock calls itself until the host rejects the nested call,
+// either on the destination context or on the same context
+func recursiveCallerMock(sameContext bool, result *recursionResult) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ParentAddress).
+		WithBalance(1000).
+		WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+			parentInstance.AddMockMethod("recurse", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				instance := mock.GetMockInstance(host)
+
+				callDepth := host.Runtime().CallDepth()
+				if callDepth > result.deepestCallDepth {
+					result.deepestCallDepth = callDepth
+				}
+				if callDepth >= recursionSafetyDepth {
+					return instance
+				}
+
+				input := test.DefaultTestContractCallInput()
+				input.CallerAddr = test.ParentAddress
+				input.RecipientAddr = test.ParentAddress
+				input.Function = "recurse"
+				input.GasProvided = host.Metering().GasLeft() / 2
+
+				if sameContext {
+					_, err := host.ExecuteOnSameContext(input)
+					if err != nil && result.rejectedCallErr == nil {
+						result.rejectedCallErr = err
+					}
+					return instance
+				}
+
+				vmOutput, _, err := host.ExecuteOnDestContext(input)
+				if err != nil && result.rejectedCallErr == nil {
+					result.rejectedCallErr = err
+					result.rejectedCallCode = vmOutput.ReturnCode
+				}
+				return instance
+			})
+		})
+}
+
+func runRecursiveCallerTest(t *testing.T, sameContext bool, maxCallDepth int) *recursionResult {
+	result := &recursionResult{}
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(recursiveCallerMock(sameContext, result)).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(1000000).
+			WithFunction("recurse").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetMaxCallDepth(maxCallDepth)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+		})
+
+	return result
+}
+
+func TestCallDepth_ExecuteOnDestContext(t *testing.T) {
+	result := runRecursiveCallerTest(t, false, 3)
+	require.Equal(t, 3, result.deepestCallDepth)
+	require.Equal(t, arwen.ErrCallDepthExceeded, result.rejectedCallErr)
+	require.Equal(t, arwen.CallDepthExceeded, result.rejectedCallCode)
+}
+
+func TestCallDepth_ExecuteOnSameContext(t *testing.T) {
+	result := runRecursiveCallerTest(t, true, 5)
+	require.Equal(t, 5, result.deepestCallDepth)
+	require.Equal(t, arwen.ErrCallDepthExceeded, result.rejectedCallErr)
+}
+
+func TestCallDepth_Unbounded(t *testing.T) {
+	result := runRecursiveCallerTest(t, false, 0)
+	require.Equal(t, recursionSafetyDepth, result.deepestCallDepth)
+	require.Nil(t, result.rejectedCallErr)
+}
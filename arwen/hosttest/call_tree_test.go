@@ -0,0 +1,200 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func callTreeParentMock(t *testing.T, childFunction string) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ParentAddress).
+		WithBalance(1000).
+		WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+			parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				_, err := host.Storage().SetStorage([]byte("parentKey"), []byte("parentValue"))
+				require.Nil(t, err)
+				host.Output().WriteLog(test.ParentAddress, [][]byte{[]byte("parentTopic")}, []byte("parentData"))
+
+				childInput := test.DefaultTestContractCallInput()
+				childInput.CallerAddr = test.ParentAddress
+				childInput.RecipientAddr = test.ChildAddress
+				childInput.Function = childFunction
+				childInput.GasProvided = 1000
+				_, _, _ = host.ExecuteOnDestContext(childInput)
+				return parentInstance
+			})
+		})
+}
+
+func callTreeChildMock() test.MockTestSmartContract {
+	return test.CreateMockContract(test.ChildAddress).
+		WithBalance(0).
+		WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+			childInstance.AddMockMethod("write", func() *mock.InstanceMock {
+				host := childInstance.Host
+				_, _ = host.Storage().SetStorage([]byte("childKey"), []byte("childValue"))
+				host.Output().WriteLog(test.ChildAddress, [][]byte{[]byte("childTopic")}, []byte("childData"))
+				host.Output().Finish([]byte("child returns this"))
+				return childInstance
+			})
+			childInstance.AddMockMethod("rewriteAndRead", func() *mock.InstanceMock {
+				host := childInstance.Host
+				_, _ = host.Storage().SetStorage([]byte("childKey"), []byte("childValue"))
+				_ = host.Storage().GetStorage([]byte("readKey"))
+				return childInstance
+			})
+			childInstance.AddMockMethod("writeAndFail", func() *mock.InstanceMock {
+				host := childInstance.Host
+				instance := mock.GetMockInstance(host)
+				_, _ = host.Storage().SetStorage([]byte("childKey"), []byte("childValue"))
+				host.Output().WriteLog(test.ChildAddress, [][]byte{[]byte("childTopic")}, []byte("childData"))
+				host.Runtime().SignalUserError("child failed")
+				return instance
+			})
+		})
+}
+
+func TestCallTree_ExecuteOnDestContext(t *testing.T) {
+	var vmHost arwen.VMHost
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			callTreeParentMock(t, "write"),
+			callTreeChildMock(),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+			host.SetCallTreeEnabled(true)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			root := vmHost.GetCallTree()
+			require.NotNil(t, root)
+			require.Equal(t, arwen.ExecutionDirectCall, root.Kind)
+			require.Equal(t, test.ParentAddress, root.ContractAddr)
+			require.Equal(t, "callChild", root.Function)
+			require.Equal(t, vmcommon.Ok, root.ReturnCode)
+			require.Len(t, root.Logs, 1)
+			require.Equal(t, []byte("parentData"), root.Logs[0].Data)
+			require.Len(t, root.StorageUpdates, 1)
+			require.Len(t, root.StorageUpdates[string(test.ParentAddress)], 1)
+			require.Equal(t, []byte("parentValue"), root.StorageUpdates[string(test.ParentAddress)]["parentKey"].Data)
+
+			require.Len(t, root.Children, 1)
+			child := root.Children[0]
+			require.Equal(t, arwen.ExecutionDestContext, child.Kind)
+			require.Equal(t, test.ParentAddress, child.CallerAddr)
+			require.Equal(t, test.ChildAddress, child.ContractAddr)
+			require.Equal(t, "write", child.Function)
+			require.Equal(t, uint64(1000), child.GasProvided)
+			require.Equal(t, [][]byte{[]byte("child returns this")}, child.ReturnData)
+			require.Len(t, child.Logs, 1)
+			require.Equal(t, []byte("childData"), child.Logs[0].Data)
+			require.Len(t, child.StorageUpdates, 1)
+			require.Equal(t, []byte("childValue"), child.StorageUpdates[string(test.ChildAddress)]["childKey"].Data)
+			require.Empty(t, child.Children)
+		})
+}
+
+func TestCallTree_UnchangedWritesAreReported(t *testing.T) {
+	var vmHost arwen.VMHost
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			callTreeParentMock(t, "rewriteAndRead"),
+			callTreeChildMock(),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+			host.SetCallTreeEnabled(true)
+			childAccount := world.AcctMap.GetAccount(test.ChildAddress)
+			childAccount.Storage["childKey"] = []byte("childValue")
+			childAccount.Storage["readKey"] = []byte("readValue")
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			root := vmHost.GetCallTree()
+			require.NotNil(t, root)
+			require.Nil(t, root.StorageUpdates[string(test.ChildAddress)])
+
+			require.Len(t, root.Children, 1)
+			child := root.Children[0]
+			require.Len(t, child.StorageUpdates[string(test.ChildAddress)], 1)
+			require.Equal(t, []byte("childValue"), child.StorageUpdates[string(test.ChildAddress)]["childKey"].Data)
+		})
+}
+
+func TestCallTree_FailedChildHasNoEffects(t *testing.T) {
+	var vmHost arwen.VMHost
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			callTreeParentMock(t, "writeAndFail"),
+			callTreeChildMock(),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+			host.SetCallTreeEnabled(true)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			root := vmHost.GetCallTree()
+			require.NotNil(t, root)
+			require.Len(t, root.Logs, 1)
+			require.Len(t, root.StorageUpdates[string(test.ParentAddress)], 1)
+			require.Nil(t, root.StorageUpdates[string(test.ChildAddress)])
+
+			require.Len(t, root.Children, 1)
+			child := root.Children[0]
+			require.Equal(t, vmcommon.UserError, child.ReturnCode)
+			require.Equal(t, "child failed", child.ReturnMessage)
+			require.Empty(t, child.Logs)
+			require.Empty(t, child.StorageUpdates)
+		})
+}
+
+func TestCallTree_Disabled(t *testing.T) {
+	var vmHost arwen.VMHost
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			callTreeParentMock(t, "write"),
+			callTreeChildMock(),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+			require.Nil(t, vmHost.GetCallTree())
+		})
+}
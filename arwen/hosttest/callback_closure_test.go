@@ -0,0 +1,212 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/elrondapi"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func addClosureFinishingMockMethod(instance *mock.InstanceMock, name string) {
+	instance.AddMockMethod(name, func() *mock.InstanceMock {
+		host := instance.Host
+		host.Output().Finish([]byte(name))
+		host.Output().Finish(host.Runtime().GetCallbackClosure())
+		return instance
+	})
+}
+
+func TestCallbackClosure_AsyncCall(t *testing.T) {
+	host, world, imb := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.CallbackClosureFeature)
+
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("swap", func() *mock.InstanceMock {
+		elrondapi.AsyncCallWithTypedArgs(parentInstance.Host, test.ChildAddress, nil, []byte("localWork"), []byte("pendingSwap"))
+		return mock.GetMockInstance(parentInstance.Host)
+	})
+	addClosureFinishingMockMethod(parentInstance, arwen.CallbackFunctionName)
+
+	childInstance := imb.CreateAndStoreInstanceMock(t, host, test.ChildAddress, 0, 0)
+	childInstance.AddMockMethod("localWork", func() *mock.InstanceMock {
+		// only the callback receives the closure, not the destination
+		require.Nil(t, childInstance.Host.Runtime().GetCallbackClosure())
+		childInstance.Host.Output().Finish([]byte("localWork"))
+		return childInstance
+	})
+
+	vmOutput := runAsyncContextsCall(t, host, world, test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(test.GasProvided).
+		WithFunction("swap").
+		Build())
+
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("localWork"), []byte(arwen.CallbackFunctionName), []byte("pendingSwap"))
+}
+
+func createCallbackClosureCrossShardWorld(t *testing.T) *worldmock.MultiShardWorld {
+	hostShard0, worldShard0, imbShard0 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.CallbackClosureFeature)
+	worldShard0.SelfShardID = 0
+
+	parentInstance := imbShard0.CreateAndStoreInstanceMock(t, hostShard0, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("swap", func() *mock.InstanceMock {
+		callbackClosure := parentInstance.Host.Runtime().Arguments()[0]
+		elrondapi.AsyncCallWithTypedArgs(parentInstance.Host, asyncContextsRemoteAddressA, nil, []byte("remoteWork"), callbackClosure)
+		return mock.GetMockInstance(parentInstance.Host)
+	})
+	addClosureFinishingMockMethod(parentInstance, arwen.CallbackFunctionName)
+
+	hostShard1, worldShard1, imbShard1 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.CallbackClosureFeature)
+	worldShard1.SelfShardID = 1
+	createAsyncContextsRemoteMocks(t, hostShard1, imbShard1)
+
+	multiShardWorld := worldmock.NewMultiShardWorld()
+	require.Nil(t, multiShardWorld.AddShard(worldShard0, hostShard0))
+	require.Nil(t, multiShardWorld.AddShard(worldShard1, hostShard1))
+
+	return multiShardWorld
+}
+
+func runCallbackClosureSwap(t *testing.T, multiShardWorld *worldmock.MultiShardWorld, callbackClosure string) *vmcommon.VMOutput {
+	vmOutput, err := multiShardWorld.RunSmartContractCall(test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("swap").
+		WithArguments([]byte(callbackClosure)).
+		WithOriginalTxHash([]byte("swapTxHash")).
+		Build())
+	require.Nil(t, err)
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+
+	return vmOutput
+}
+
+func callbackClosureStorageKey(sequence ...byte) []byte {
+	associatedKey := append([]byte("swapTxHash"), asyncContextsRemoteAddressA...)
+	return arwen.CustomStorageKey(arwen.CallbackClosurePrefix, append(associatedKey, sequence...))
+}
+
+func TestCallbackClosure_AsyncCall_CrossShard(t *testing.T) {
+	multiShardWorld := createCallbackClosureCrossShardWorld(t)
+
+	// the closure is stored, because the callback arrives in another transaction
+	vmOutput := runCallbackClosureSwap(t, multiShardWorld, "pendingSwap")
+
+	closureKey := callbackClosureStorageKey(0, 0, 0, 0, 0, 0, 0, 0)
+	closureUpdate := vmOutput.OutputAccounts[string(test.ParentAddress)].StorageUpdates[string(closureKey)]
+	require.NotNil(t, closureUpdate)
+	require.Equal(t, []byte("pendingSwap"), closureUpdate.Data)
+
+	// the callback received from shard 1 reloads the closure, then removes it
+	// together with the sequence numbers
+	executedResults, err := multiShardWorld.ProcessBlocksUntilIdle(10)
+	require.Nil(t, err)
+	require.Len(t, executedResults, 2)
+
+	callbackResult := executedResults[1]
+	require.Equal(t, vm.AsynchronousCallBack, callbackResult.Result.CallType)
+	verify := test.NewVMOutputVerifier(t, callbackResult.VMOutput, nil)
+	verify.Ok().
+		ReturnData([]byte(arwen.CallbackFunctionName), []byte("pendingSwap"))
+
+	storageUpdates := callbackResult.VMOutput.OutputAccounts[string(test.ParentAddress)].StorageUpdates
+	require.Len(t, storageUpdates[string(closureKey)].Data, 0)
+	require.Len(t, storageUpdates[string(callbackClosureStorageKey())].Data, 0)
+}
+
+func TestCallbackClosure_AsyncCall_CrossShard_SameDestination(t *testing.T) {
+	multiShardWorld := createCallbackClosureCrossShardWorld(t)
+
+	// two async calls of the same original transaction to the same
+	// destination keep their own closures, handed to their callbacks in order
+	runCallbackClosureSwap(t, multiShardWorld, "firstSwap")
+	runCallbackClosureSwap(t, multiShardWorld, "secondSwap")
+
+	executedResults, err := multiShardWorld.ProcessBlocksUntilIdle(10)
+	require.Nil(t, err)
+
+	callbackClosures := make([][]byte, 0)
+	for _, executedResult := range executedResults {
+		if executedResult.Result.CallType != vm.AsynchronousCallBack {
+			continue
+		}
+
+		verify := test.NewVMOutputVerifier(t, executedResult.VMOutput, nil)
+		verify.Ok()
+		callbackClosures = append(callbackClosures, executedResult.VMOutput.ReturnData[1])
+	}
+	require.Equal(t, [][]byte{[]byte("firstSwap"), []byte("secondSwap")}, callbackClosures)
+}
+
+func TestCallbackClosure_AsyncContexts_CrossShard(t *testing.T) {
+	hostShard0, worldShard0, imbShard0 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.CallbackClosureFeature)
+	worldShard0.SelfShardID = 0
+
+	parentInstance := imbShard0.CreateAndStoreInstanceMock(t, hostShard0, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("fanOut", func() *mock.InstanceMock {
+		runtime := parentInstance.Host.Runtime()
+		addAsyncCall := func(destination []byte, data string, callbackClosure string) {
+			err := runtime.AddAsyncContextCall([]byte("bridge"), &arwen.AsyncGeneratedCall{
+				Destination:     destination,
+				Data:            []byte(data),
+				SuccessCallback: "callOk",
+				ErrorCallback:   "callFailed",
+				CallbackClosure: []byte(callbackClosure),
+			})
+			require.Nil(t, err)
+		}
+
+		addAsyncCall(test.ChildAddress, "localWork", "localClosure")
+		addAsyncCall(asyncContextsRemoteAddressA, "remoteWork", "remoteClosure")
+		return parentInstance
+	})
+	addClosureFinishingMockMethod(parentInstance, "callOk")
+	addClosureFinishingMockMethod(parentInstance, "callFailed")
+
+	childInstance := imbShard0.CreateAndStoreInstanceMock(t, hostShard0, test.ChildAddress, 0, 0)
+	addFinishingMockMethod(childInstance, "localWork", false)
+
+	hostShard1, worldShard1, imbShard1 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.CallbackClosureFeature)
+	worldShard1.SelfShardID = 1
+	createAsyncContextsRemoteMocks(t, hostShard1, imbShard1)
+
+	multiShardWorld := worldmock.NewMultiShardWorld()
+	require.Nil(t, multiShardWorld.AddShard(worldShard0, hostShard0))
+	require.Nil(t, multiShardWorld.AddShard(worldShard1, hostShard1))
+
+	// the local call is called back synchronously, while the closure of the
+	// remote call is stored together with the pending async context
+	vmOutput, err := multiShardWorld.RunSmartContractCall(test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("fanOut").
+		Build())
+	require.Nil(t, err)
+	verify := test.NewVMOutputVerifier(t, vmOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("localWork"), []byte("callOk"), []byte("localClosure"))
+
+	asyncInfo := getStoredAsyncContexts(t, vmOutput)
+	require.NotNil(t, asyncInfo)
+	require.Equal(t, []byte("remoteClosure"), asyncInfo.AsyncContextMap["bridge"].AsyncCalls[1].CallbackClosure)
+
+	// the callback received from shard 1 reloads the closure from storage
+	executedResults, err := multiShardWorld.ProcessBlocksUntilIdle(10)
+	require.Nil(t, err)
+	require.Len(t, executedResults, 2)
+
+	callbackResult := executedResults[1]
+	require.Equal(t, vm.AsynchronousCallBack, callbackResult.Result.CallType)
+	require.Equal(t, vmcommon.Ok, callbackResult.VMOutput.ReturnCode)
+	verify = test.NewVMOutputVerifier(t, callbackResult.VMOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callOk"), []byte("remoteClosure"))
+	require.Nil(t, getStoredAsyncContexts(t, callbackResult.VMOutput))
+}
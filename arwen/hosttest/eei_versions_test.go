@@ -0,0 +1,58 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	arwenHost "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/host"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/wasmer"
+	"github.com/ElrondNetwork/elrond-vm-common/parsers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEEIVersions_RegisterAndLock(t *testing.T) {
+	world := worldmock.NewMockWorld()
+	err := world.InitBuiltinFunctions(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
+	host, err := arwenHost.NewArwenVM(world, &arwen.VMHostParameters{
+		VMType:                   []byte{5, 0},
+		BlockGasLimit:            uint64(1000),
+		GasSchedule:              config.MakeGasMapForTests(),
+		BuiltInFuncContainer:     world.BuiltinFuncs.Container,
+		ElrondProtectedKeyPrefix: []byte("ELROND"),
+		ESDTTransferParser:       esdtTransferParser,
+		LegacyEEIVersion:         arwen.EEIVersion1_3,
+	})
+	require.Nil(t, err)
+
+	err = host.RegisterEEIVersion(arwen.EEIVersionUnspecified, wasmer.NewImports())
+	require.Equal(t, arwen.ErrInvalidEEIVersion, err)
+
+	err = host.RegisterEEIVersion(arwen.MaxEEIVersion+1, wasmer.NewImports())
+	require.Equal(t, arwen.ErrInvalidEEIVersion, err)
+
+	err = host.RegisterEEIVersion(arwen.EEIVersion(7), nil)
+	require.Equal(t, arwen.ErrNilEEIImports, err)
+
+	unlockEEIVersion, err := host.LockEEIVersion(arwen.EEIVersion(7))
+	require.Equal(t, arwen.ErrInvalidEEIVersion, err)
+	require.Nil(t, unlockEEIVersion)
+
+	err = host.RegisterEEIVersion(arwen.EEIVersion(7), wasmer.NewImports())
+	require.Nil(t, err)
+
+	for _, version := range []arwen.EEIVersion{
+		arwen.EEIVersionUnspecified,
+		arwen.EEIVersion1_3,
+		arwen.EEIVersion1_4,
+		arwen.EEIVersion(7),
+	} {
+		unlockEEIVersion, err = host.LockEEIVersion(version)
+		require.Nil(t, err)
+		unlockEEIVersion()
+	}
+}
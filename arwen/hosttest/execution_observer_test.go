@@ -0,0 +1,139 @@
+package hosttest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionObserver_ExecuteOnDestContext(t *testing.T) {
+	observer := mock.NewExecutionObserverMock()
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			test.CreateMockContract(test.ParentAddress).
+				WithBalance(1000).
+				WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+					parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+						host := parentInstance.Host
+						host.Metering().UseGas(500)
+						childInput := test.DefaultTestContractCallInput()
+						childInput.CallerAddr = test.ParentAddress
+						childInput.RecipientAddr = test.ChildAddress
+						childInput.CallValue = big.NewInt(4)
+						childInput.Function = "doSomething"
+						childInput.GasProvided = 1000
+						_, _, err := host.ExecuteOnDestContext(childInput)
+						require.Nil(t, err)
+						return parentInstance
+					})
+				}),
+			test.CreateMockContract(test.ChildAddress).
+				WithBalance(0).
+				WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+					childInstance.AddMockMethod("doSomething", func() *mock.InstanceMock {
+						host := childInstance.Host
+						host.Output().Finish([]byte("child returns this"))
+						host.Metering().UseGas(100)
+						return childInstance
+					})
+				}),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetExecutionObserver(observer)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			require.Len(t, observer.Events, 4)
+			require.Equal(t, []bool{false, false, true, true}, observer.Exited)
+
+			parentEnter := observer.Events[0]
+			require.Equal(t, arwen.ExecutionDirectCall, parentEnter.Kind)
+			require.Equal(t, 1, parentEnter.Depth)
+			require.Equal(t, test.ParentAddress, parentEnter.ContractAddr)
+			require.Equal(t, "callChild", parentEnter.Function)
+			require.Equal(t, uint64(2000), parentEnter.GasProvided)
+
+			childEnter := observer.Events[1]
+			require.Equal(t, arwen.ExecutionDestContext, childEnter.Kind)
+			require.Equal(t, 2, childEnter.Depth)
+			require.Equal(t, test.ParentAddress, childEnter.CallerAddr)
+			require.Equal(t, test.ChildAddress, childEnter.ContractAddr)
+			require.Equal(t, big.NewInt(4), childEnter.CallValue)
+
+			childExit := observer.Events[2]
+			require.Equal(t, arwen.ExecutionDestContext, childExit.Kind)
+			require.Equal(t, vmcommon.Ok, childExit.ReturnCode)
+			require.Equal(t, [][]byte{[]byte("child returns this")}, childExit.ReturnData)
+			require.Equal(t, childExit.GasProvided-childExit.GasRemaining, childExit.GasUsed)
+
+			parentExit := observer.Events[3]
+			require.Equal(t, arwen.ExecutionDirectCall, parentExit.Kind)
+			require.Equal(t, 1, parentExit.Depth)
+			require.Equal(t, vmcommon.Ok, parentExit.ReturnCode)
+		})
+}
+
+func TestExecutionObserver_ExecuteOnSameContext_Fails(t *testing.T) {
+	observer := mock.NewExecutionObserverMock()
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			test.CreateMockContract(test.ParentAddress).
+				WithBalance(1000).
+				WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+					parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+						host := parentInstance.Host
+						childInput := test.DefaultTestContractCallInput()
+						childInput.CallerAddr = test.ParentAddress
+						childInput.RecipientAddr = test.ChildAddress
+						childInput.Function = "fail"
+						childInput.GasProvided = 1000
+						_, err := host.ExecuteOnSameContext(childInput)
+						require.NotNil(t, err)
+						return parentInstance
+					})
+				}),
+			test.CreateMockContract(test.ChildAddress).
+				WithBalance(0).
+				WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+					childInstance.AddMockMethod("fail", func() *mock.InstanceMock {
+						host := childInstance.Host
+						instance := mock.GetMockInstance(host)
+						host.Runtime().SignalUserError("child failed")
+						return instance
+					})
+				}),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetExecutionObserver(observer)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			require.Len(t, observer.Events, 4)
+
+			childExit := observer.Events[2]
+			require.True(t, observer.Exited[2])
+			require.Equal(t, arwen.ExecutionSameContext, childExit.Kind)
+			require.Equal(t, 2, childExit.Depth)
+			require.Equal(t, vmcommon.UserError, childExit.ReturnCode)
+			require.Equal(t, "child failed", childExit.ReturnMessage)
+			require.NotNil(t, childExit.Err)
+		})
+}
@@ -409,6 +409,24 @@ func TestExecution_Deploy_DisallowFloatingPoint(t *testing.T) {
 		})
 }
 
+func TestExecution_Deploy_RejectFloatingPoint(t *testing.T) {
+	test.BuildInstanceCreatorTest(t).
+		WithFeatures(arwen.FloatingPointRejectionFeature).
+		WithInput(test.CreateTestContractCreateInputBuilder().
+			WithGasProvided(1000).
+			WithCallValue(88).
+			WithArguments([]byte{2}).
+			WithContractCode(test.GetTestSCCode("num-with-fp", "../../")).
+			Build()).
+		WithAddress(newAddress).
+		AndAssertResults(func(blockchainHook *contextmock.BlockchainHookStub, verify *test.VMOutputVerifier) {
+			verify.
+				ReturnCode(vmcommon.ContractInvalid).
+				ReturnMessageContains(arwen.ErrFloatingPointOpcode.Error()).
+				ReturnMessageContains("F32Add")
+		})
+}
+
 func TestExecution_CallGetUserAccountErr(t *testing.T) {
 	errGetAccount := errors.New("get code error")
 	test.BuildInstanceCallTest(t).
@@ -2268,6 +2286,40 @@ func TestExecution_CreateNewContract_Success(t *testing.T) {
 		})
 }
 
+func TestExecution_CreateNewContract_RecordsEEIVersion(t *testing.T) {
+	childCode := test.GetTestSCCode("init-correct", "../../")
+	childAddress := []byte("newAddress")
+
+	test.BuildInstanceCallTest(t).
+		WithFeatures(arwen.EEIVersionMetadataFeature).
+		WithContracts(
+			test.CreateInstanceContract(test.ParentAddress).
+				WithCode(test.GetTestSCCode("deployer", "../../")).
+				WithBalance(1000),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithFunction("deployChildContract").
+			WithGasProvided(1_000_000).
+			WithArguments([]byte{'A'}, []byte{0}).
+			WithCurrentTxHash([]byte("txhash")).
+			Build()).
+		WithSetup(func(host arwen.VMHost, stubBlockchainHook *contextmock.BlockchainHookStub) {
+			stubBlockchainHook.GetStorageDataCalled = func(address []byte, key []byte) ([]byte, error) {
+				if bytes.Equal(address, test.ParentAddress) && bytes.Equal(key, []byte{'A'}) {
+					return childCode, nil
+				}
+				return nil, nil
+			}
+		}).
+		AndAssertResults(func(host arwen.VMHost, stubBlockchainHook *contextmock.BlockchainHookStub, verify *test.VMOutputVerifier) {
+			verify.
+				Ok().
+				Code(childAddress, childCode).
+				CodeMetadata(childAddress, arwen.CodeMetadataWithEEIVersion([]byte{1, 0}, arwen.CurrentEEIVersion))
+		})
+}
+
 func TestExecution_DeployNewContractFromExistingCode_Success(t *testing.T) {
 	sourceAddress := testcommon.MakeTestSCAddress("sourceAddress")
 	sourceCode := test.GetTestSCCode("init-correct", "../../")
@@ -0,0 +1,93 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	arwenHost "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/host"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/ElrondNetwork/elrond-vm-common/parsers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlags_EnabledFromActivationEpoch(t *testing.T) {
+	world := worldmock.NewMockWorld()
+	err := world.InitBuiltinFunctions(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
+	host, err := arwenHost.NewArwenVM(world, &arwen.VMHostParameters{
+		VMType:                   []byte{5, 0},
+		BlockGasLimit:            uint64(1000),
+		GasSchedule:              config.MakeGasMapForTests(),
+		BuiltInFuncContainer:     world.BuiltinFuncs.Container,
+		ElrondProtectedKeyPrefix: []byte("ELROND"),
+		ESDTTransferParser:       esdtTransferParser,
+		EpochNotifier:            world.EpochNotifier,
+		FeatureActivationEpochs: map[arwen.FeatureFlag]uint32{
+			arwen.SelfDestructFeature: 3,
+		},
+	})
+	require.Nil(t, err)
+
+	require.False(t, host.IsFeatureEnabled(arwen.SelfDestructFeature))
+	require.False(t, host.IsFeatureEnabled(arwen.FeatureFlag("NotConfigured")))
+
+	world.SetCurrentBlockInfo(&worldmock.BlockInfo{BlockEpoch: 3})
+	require.True(t, host.IsFeatureEnabled(arwen.SelfDestructFeature))
+
+	world.SetCurrentBlockInfo(&worldmock.BlockInfo{BlockEpoch: 2})
+	require.False(t, host.IsFeatureEnabled(arwen.SelfDestructFeature))
+}
+
+func TestFeatureFlags_GasBehaviourFollowsEpoch(t *testing.T) {
+	world := worldmock.NewMockWorld()
+	gasSchedule := config.MakeGasMapForTests()
+	gasSchedule["BaseOperationCost"]["MemoryGrowPerPage"] = 1000
+	err := world.InitBuiltinFunctions(gasSchedule)
+	require.Nil(t, err)
+
+	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
+	host, err := arwenHost.NewArwenVM(world, &arwen.VMHostParameters{
+		VMType:                   test.DefaultVMType,
+		BlockGasLimit:            uint64(1000),
+		GasSchedule:              gasSchedule,
+		BuiltInFuncContainer:     world.BuiltinFuncs.Container,
+		ElrondProtectedKeyPrefix: []byte("ELROND"),
+		ESDTTransferParser:       esdtTransferParser,
+		EpochNotifier:            world.EpochNotifier,
+		FeatureActivationEpochs:  worldmock.MakeFeatureActivationEpochsForTests(),
+	})
+	require.Nil(t, err)
+
+	instanceBuilder := mock.NewInstanceBuilderMock(world)
+	host.Runtime().ReplaceInstanceBuilder(instanceBuilder)
+	instance := instanceBuilder.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	instance.AddMockMethod("growMemory", func() *mock.InstanceMock {
+		running := mock.GetMockInstance(host)
+		err := running.GetInstanceCtxMemory().Grow(1)
+		require.Nil(t, err)
+		return running
+	})
+
+	input := test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(100_000).
+		WithFunction("growMemory").
+		Build()
+
+	vmOutput, err := host.RunSmartContractCall(input)
+	require.Nil(t, err)
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+	gasRemainingBeforeActivation := vmOutput.GasRemaining
+
+	world.SetCurrentBlockInfo(&worldmock.BlockInfo{BlockEpoch: worldmock.TestFeatureActivationEpoch})
+	vmOutput, err = host.RunSmartContractCall(input)
+	require.Nil(t, err)
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+	require.Equal(t, gasRemainingBeforeActivation-1000, vmOutput.GasRemaining)
+}
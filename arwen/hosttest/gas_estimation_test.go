@@ -0,0 +1,168 @@
+package hosttest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func gasEstimationParentMock(t *testing.T) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ParentAddress).
+		WithBalance(1000).
+		WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+			parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				instance := mock.GetMockInstance(host)
+				host.Metering().UseGas(500)
+
+				childInput := test.DefaultTestContractCallInput()
+				childInput.CallerAddr = test.ParentAddress
+				childInput.RecipientAddr = test.ChildAddress
+				childInput.Function = "doSomething"
+				childInput.GasProvided = host.Metering().GasLeft()
+				_, _, err := host.ExecuteOnDestContext(childInput)
+				if err != nil {
+					host.Runtime().SignalUserError(err.Error())
+				}
+				return instance
+			})
+			parentInstance.AddMockMethod("alwaysFail", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				instance := mock.GetMockInstance(host)
+				host.Runtime().SignalUserError("always fails")
+				return instance
+			})
+		})
+}
+
+func gasEstimationChildMock() test.MockTestSmartContract {
+	return test.CreateMockContract(test.ChildAddress).
+		WithBalance(0).
+		WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+			childInstance.AddMockMethod("doSomething", test.SimpleWasteGasMockMethod(childInstance, 300))
+		})
+}
+
+func TestGasEstimation_ExecuteOnDestContext(t *testing.T) {
+	var vmHost arwen.VMHost
+	input := test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(100_000).
+		WithFunction("callChild").
+		Build()
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			gasEstimationParentMock(t),
+			gasEstimationChildMock(),
+		).
+		WithInput(input).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			estimate, err := vmHost.EstimateGas(input)
+			require.Nil(t, err)
+			require.Equal(t, uint64(100_000), input.GasProvided)
+			require.False(t, estimate.HasCrossShardCalls)
+			require.Zero(t, estimate.GasLockedForCallbacks)
+			require.Equal(t, estimate.GasLimit, estimate.GasUsed)
+			require.Less(t, estimate.GasLimit, input.GasProvided)
+
+			sufficientInput := *input
+			sufficientInput.GasProvided = estimate.GasLimit
+			vmOutput, err := vmHost.RunSmartContractCall(&sufficientInput)
+			require.Nil(t, err)
+			require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+
+			insufficientInput := *input
+			insufficientInput.GasProvided = estimate.GasLimit - 1
+			vmOutput, err = vmHost.RunSmartContractCall(&insufficientInput)
+			require.Nil(t, err)
+			require.NotEqual(t, vmcommon.Ok, vmOutput.ReturnCode)
+		})
+}
+
+func TestGasEstimation_AlwaysFails(t *testing.T) {
+	var vmHost arwen.VMHost
+	input := test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(100_000).
+		WithFunction("alwaysFail").
+		Build()
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			gasEstimationParentMock(t),
+			gasEstimationChildMock(),
+		).
+		WithInput(input).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.ReturnCode(vmcommon.UserError)
+
+			estimate, err := vmHost.EstimateGas(input)
+			require.Nil(t, estimate)
+			require.True(t, errors.Is(err, arwen.ErrGasEstimationFailed))
+		})
+}
+
+func TestGasEstimation_HostStateIsUnchanged(t *testing.T) {
+	var vmHost arwen.VMHost
+	input := test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(100_000).
+		WithFunction("callChild").
+		Build()
+
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			gasEstimationParentMock(t),
+			gasEstimationChildMock(),
+		).
+		WithInput(input).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			vmHost = host
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+
+			runtime := vmHost.Runtime()
+			output := vmHost.Output()
+			metering := vmHost.Metering()
+			vmInput := runtime.GetVMInput()
+			function := runtime.Function()
+			gasProvided := metering.GetGasProvided()
+			outputAccountCount := len(output.GetOutputAccounts())
+			childGasUsed := output.GetOutputAccounts()[string(test.ChildAddress)].GasUsed
+			returnCode := output.ReturnCode()
+
+			estimationInput := *input
+			estimationInput.Function = "alwaysFail"
+			_, err := vmHost.EstimateGas(&estimationInput)
+			require.True(t, errors.Is(err, arwen.ErrGasEstimationFailed))
+
+			_, err = vmHost.EstimateGas(input)
+			require.Nil(t, err)
+
+			require.Equal(t, vmInput, runtime.GetVMInput())
+			require.Equal(t, uint64(100_000), runtime.GetVMInput().GasProvided)
+			require.Equal(t, function, runtime.Function())
+			require.Equal(t, test.ParentAddress, runtime.GetSCAddress())
+			require.Equal(t, gasProvided, metering.GetGasProvided())
+			require.Equal(t, outputAccountCount, len(output.GetOutputAccounts()))
+			require.Equal(t, childGasUsed, output.GetOutputAccounts()[string(test.ChildAddress)].GasUsed)
+			require.Equal(t, returnCode, output.ReturnCode())
+			require.Zero(t, runtime.CallDepth())
+		})
+}
@@ -0,0 +1,107 @@
+package hosttest
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	arwenHost "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/host"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/ElrondNetwork/elrond-vm-common/parsers"
+	"github.com/stretchr/testify/require"
+)
+
+func createHostPoolWithCounter(t *testing.T, numHosts int) *arwenHost.HostPool {
+	world := worldmock.NewMockWorld()
+	gasSchedule := config.MakeGasMapForTests()
+	err := world.InitBuiltinFunctions(gasSchedule)
+	require.Nil(t, err)
+
+	code := test.GetTestSCCode("counter", "../../")
+	account := world.AcctMap.CreateSmartContractAccount(test.UserAddress, test.ParentAddress, code, world)
+	account.Storage[string(counterKey)] = big.NewInt(42).Bytes()
+
+	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
+	pool, err := arwenHost.NewHostPool(numHosts, world, &arwen.VMHostParameters{
+		VMType:                   test.DefaultVMType,
+		BlockGasLimit:            uint64(1000),
+		GasSchedule:              gasSchedule,
+		BuiltInFuncContainer:     world.BuiltinFuncs.Container,
+		ElrondProtectedKeyPrefix: []byte("ELROND"),
+		ESDTTransferParser:       esdtTransferParser,
+	})
+	require.Nil(t, err)
+	require.Equal(t, numHosts, pool.Len())
+
+	return pool
+}
+
+func counterQueryInput(function string) *vmcommon.ContractCallInput {
+	input := test.DefaultTestContractCallInput()
+	input.RecipientAddr = test.ParentAddress
+	input.GasProvided = 1000000
+	input.Function = function
+	return input
+}
+
+func TestHostPool_InvalidNumberOfHosts(t *testing.T) {
+	world := worldmock.NewMockWorld()
+	pool, err := arwenHost.NewHostPool(0, world, &arwen.VMHostParameters{})
+	require.Nil(t, pool)
+	require.Equal(t, arwen.ErrInvalidNumberOfHosts, err)
+}
+
+func TestHostPool_NewArwenVMErrorReturnsNilInterface(t *testing.T) {
+	host, err := arwenHost.NewArwenVM(nil, &arwen.VMHostParameters{})
+	require.Equal(t, arwen.ErrNilBlockChainHook, err)
+	require.True(t, host == nil)
+}
+
+func TestHostPool_ConcurrentQueries(t *testing.T) {
+	pool := createHostPoolWithCounter(t, 4)
+
+	numQueries := 20
+	vmOutputs := make([]*vmcommon.VMOutput, numQueries)
+	errs := make([]error, numQueries)
+
+	var wg sync.WaitGroup
+	wg.Add(numQueries)
+	for i := 0; i < numQueries; i++ {
+		go func(index int) {
+			defer wg.Done()
+			vmOutputs[index], errs[index] = pool.RunQuery(counterQueryInput(get))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < numQueries; i++ {
+		verify := test.NewVMOutputVerifier(t, vmOutputs[i], errs[i])
+		verify.Ok().ReturnData(big.NewInt(42).Bytes())
+	}
+}
+
+func TestHostPool_QueriesAreReadOnly(t *testing.T) {
+	pool := createHostPoolWithCounter(t, 1)
+
+	vmOutput, err := pool.RunQuery(counterQueryInput(increment))
+	require.Nil(t, err)
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+
+	account := vmOutput.OutputAccounts[string(test.ParentAddress)]
+	require.NotNil(t, account)
+	storageUpdate, ok := account.StorageUpdates[string(counterKey)]
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(42).Bytes(), storageUpdate.Data)
+}
+
+func TestHostPool_UpgradeNotAllowed(t *testing.T) {
+	pool := createHostPoolWithCounter(t, 1)
+
+	vmOutput, err := pool.RunQuery(counterQueryInput(arwen.UpgradeFunctionName))
+	require.Nil(t, vmOutput)
+	require.Equal(t, arwen.ErrUpgradeNotAllowedInQuery, err)
+}
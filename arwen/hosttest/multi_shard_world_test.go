@@ -0,0 +1,89 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiShardWorld_AddShard(t *testing.T) {
+	host, world, _ := test.DefaultTestArwenForCallWithInstanceMocks(t)
+	multiShardWorld := worldmock.NewMultiShardWorld()
+
+	require.Equal(t, worldmock.ErrNilWorldMock, multiShardWorld.AddShard(nil, host))
+	require.Equal(t, worldmock.ErrNilVMExecutionHandler, multiShardWorld.AddShard(world, nil))
+	require.Nil(t, multiShardWorld.AddShard(world, host))
+	require.Equal(t, worldmock.ErrShardAlreadyAdded, multiShardWorld.AddShard(world, host))
+
+	_, err := multiShardWorld.RunSmartContractCall(test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ChildAddress).
+		Build())
+	require.Equal(t, worldmock.ErrUnknownShard, err)
+}
+
+func TestMultiShardWorld_CrossShardAsyncContexts(t *testing.T) {
+	hostShard0, worldShard0, imbShard0 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncContextsFeature)
+	worldShard0.SelfShardID = 0
+	createAsyncContextsParentMock(t, hostShard0, imbShard0)
+
+	hostShard1, worldShard1, imbShard1 := test.DefaultTestArwenForCallWithInstanceMocks(t, arwen.AsyncContextsFeature)
+	worldShard1.SelfShardID = 1
+	createAsyncContextsRemoteMocks(t, hostShard1, imbShard1)
+
+	multiShardWorld := worldmock.NewMultiShardWorld()
+	require.Nil(t, multiShardWorld.AddShard(worldShard0, hostShard0))
+	require.Nil(t, multiShardWorld.AddShard(worldShard1, hostShard1))
+
+	require.Equal(t, uint32(1), worldShard0.GetShardOfAddress(asyncContextsRemoteAddressA))
+	require.Equal(t, uint32(0), worldShard1.GetShardOfAddress(test.ParentAddress))
+
+	vmOutput, err := multiShardWorld.RunSmartContractCall(test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(asyncContextsGasProvided).
+		WithFunction("fanOut").
+		Build())
+	require.Nil(t, err)
+	require.Equal(t, vmcommon.Ok, vmOutput.ReturnCode)
+	require.Len(t, multiShardWorld.PendingResults, 2)
+
+	// block 1: the remote calls are executed on shard 1, one of them failing
+	executedResults, err := multiShardWorld.ProcessBlock()
+	require.Nil(t, err)
+	require.Len(t, executedResults, 2)
+	require.Equal(t, vm.AsynchronousCall, executedResults[0].Result.CallType)
+	require.Equal(t, uint32(1), executedResults[0].Result.RecipientShardID)
+	require.Equal(t, vmcommon.Ok, executedResults[0].VMOutput.ReturnCode)
+	require.Equal(t, vmcommon.UserError, executedResults[1].VMOutput.ReturnCode)
+	require.Len(t, multiShardWorld.PendingResults, 2)
+
+	// block 2: both callbacks are executed on shard 0, completing the context;
+	// the callback of the failed call runs on the gas locked for it
+	executedResults, err = multiShardWorld.ProcessBlock()
+	require.Nil(t, err)
+	require.Len(t, executedResults, 2)
+	for _, executedResult := range executedResults {
+		require.Equal(t, vm.AsynchronousCallBack, executedResult.Result.CallType)
+		require.Equal(t, uint32(0), executedResult.Result.RecipientShardID)
+	}
+	require.Equal(t, uint64(2), worldShard0.CurrentNonce())
+
+	verify := test.NewVMOutputVerifier(t, executedResults[0].VMOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callOk"))
+
+	require.NotZero(t, executedResults[1].Result.GasLocked)
+	verify = test.NewVMOutputVerifier(t, executedResults[1].VMOutput, nil)
+	verify.Ok().
+		ReturnData([]byte("callFailed"), []byte("contextDone"), []byte("bridge"))
+	require.Nil(t, getStoredAsyncContexts(t, executedResults[1].VMOutput))
+
+	executedResults, err = multiShardWorld.ProcessBlocksUntilIdle(10)
+	require.Nil(t, err)
+	require.Len(t, executedResults, 0)
+	require.Len(t, multiShardWorld.PendingResults, 0)
+}
@@ -0,0 +1,69 @@
+package hosttest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicReport_RecoveredFromContractCall(t *testing.T) {
+	host, _, imb := test.DefaultTestArwenForCallWithInstanceMocks(t)
+
+	parentInstance := imb.CreateAndStoreInstanceMock(t, host, test.ParentAddress, 0, 1000)
+	parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+		childInput := test.DefaultTestContractCallInput()
+		childInput.CallerAddr = test.ParentAddress
+		childInput.RecipientAddr = test.ChildAddress
+		childInput.Function = "panic"
+		childInput.GasProvided = 1000
+		_, _, _ = host.ExecuteOnDestContext(childInput)
+		return parentInstance
+	})
+
+	childInstance := imb.CreateAndStoreInstanceMock(t, host, test.ChildAddress, 0, 0)
+	childInstance.AddMockMethod("panic", func() *mock.InstanceMock {
+		panic("child panicked")
+	})
+
+	reportDirectory, err := ioutil.TempDir("", "arwen-panic-reports")
+	require.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(reportDirectory)
+	}()
+	host.SetPanicReportDirectory(reportDirectory)
+
+	input := test.CreateTestContractCallInputBuilder().
+		WithRecipientAddr(test.ParentAddress).
+		WithGasProvided(10000).
+		WithFunction("callChild").
+		Build()
+	vmOutput, err := host.RunSmartContractCall(input)
+	require.Nil(t, vmOutput)
+	require.Contains(t, err.Error(), "child panicked")
+
+	report, found := arwen.GetExecutionPanicReport(err)
+	require.True(t, found)
+	require.Equal(t, "arwen.RunSmartContractCall", report.Operation)
+	require.Equal(t, test.ChildAddress, report.ContractAddress)
+	require.Equal(t, "panic", report.Function)
+	require.Equal(t, 1, report.CallDepth)
+	require.NotEmpty(t, report.Stack)
+
+	runtimeReport, found := arwen.GetExecutionPanicReport(host.Runtime().GetAllErrors())
+	require.True(t, found)
+	require.Equal(t, report, runtimeReport)
+
+	reportFiles, err := filepath.Glob(filepath.Join(reportDirectory, "*"))
+	require.Nil(t, err)
+	require.Len(t, reportFiles, 1)
+
+	reportContent, err := ioutil.ReadFile(reportFiles[0])
+	require.Nil(t, err)
+	require.Equal(t, report.String(), string(reportContent))
+}
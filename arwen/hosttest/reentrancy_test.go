@@ -0,0 +1,100 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/stretchr/testify/require"
+)
+
+func reentrancyParentMock() test.MockTestSmartContract {
+	return test.CreateMockContract(test.ParentAddress).
+		WithBalance(1000).
+		WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+			parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				instance := mock.GetMockInstance(host)
+				childInput := test.DefaultTestContractCallInput()
+				childInput.CallerAddr = test.ParentAddress
+				childInput.RecipientAddr = test.ChildAddress
+				childInput.Function = "callParent"
+				childInput.GasProvided = 2000
+				_, _, err := host.ExecuteOnDestContext(childInput)
+				if err != nil {
+					host.Runtime().SignalUserError(err.Error())
+				}
+				return instance
+			})
+			parentInstance.AddMockMethod("reentered", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				host.Output().Finish([]byte("reentered"))
+				return parentInstance
+			})
+		})
+}
+
+func reentrancyChildMock(t *testing.T, expectedReturnCode vmcommon.ReturnCode) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ChildAddress).
+		WithBalance(0).
+		WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+			childInstance.AddMockMethod("callParent", func() *mock.InstanceMock {
+				host := childInstance.Host
+				parentInput := test.DefaultTestContractCallInput()
+				parentInput.CallerAddr = test.ChildAddress
+				parentInput.RecipientAddr = test.ParentAddress
+				parentInput.Function = "reentered"
+				parentInput.GasProvided = 1000
+				vmOutput, _, _ := host.ExecuteOnDestContext(parentInput)
+				require.Equal(t, expectedReturnCode, vmOutput.ReturnCode)
+
+				_, err := host.ExecuteOnSameContext(parentInput)
+				if expectedReturnCode == vmcommon.Ok {
+					require.Nil(t, err)
+				} else {
+					require.Equal(t, arwen.ErrReentrancyNotAllowed, err)
+				}
+				return childInstance
+			})
+		})
+}
+
+func TestReentrancy_ProtectedContractIsRejected(t *testing.T) {
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			reentrancyParentMock(),
+			reentrancyChildMock(t, arwen.ReentrancyNotAllowed),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(10000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetReentrancyProtectedContracts([][]byte{test.ParentAddress})
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok().
+				ReturnData()
+		})
+}
+
+func TestReentrancy_UnprotectedContractIsReentered(t *testing.T) {
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			reentrancyParentMock(),
+			reentrancyChildMock(t, vmcommon.Ok),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(10000).
+			WithFunction("callChild").
+			Build()).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok().
+				ReturnData([]byte("reentered"), []byte("reentered"))
+		})
+}
@@ -0,0 +1,108 @@
+package hosttest
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/stretchr/testify/require"
+)
+
+func selfDestructParentMock(t *testing.T) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ParentAddress).
+		WithBalance(1000).
+		WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+			parentInstance.AddMockMethod("destroy", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				err := host.Output().SelfDestruct(test.ParentAddress, test.UserAddress)
+				require.Nil(t, err)
+				return parentInstance
+			})
+			parentInstance.AddMockMethod("destroyInFavourOfSelf", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				err := host.Output().SelfDestruct(test.ParentAddress, test.ParentAddress)
+				require.Equal(t, arwen.ErrSelfDestructBeneficiaryIsSelf, err)
+				return parentInstance
+			})
+			parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				childInput := test.DefaultTestContractCallInput()
+				childInput.CallerAddr = test.ParentAddress
+				childInput.RecipientAddr = test.ChildAddress
+				childInput.Function = "destroyCaller"
+				childInput.GasProvided = 1000
+				_, _, err := host.ExecuteOnDestContext(childInput)
+				require.Nil(t, err)
+				return parentInstance
+			})
+		})
+}
+
+func selfDestructChildMock(t *testing.T) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ChildAddress).
+		WithBalance(0).
+		WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+			childInstance.AddMockMethod("destroyCaller", func() *mock.InstanceMock {
+				host := childInstance.Host
+				err := host.Output().SelfDestruct(test.ParentAddress, test.UserAddress)
+				require.Equal(t, arwen.ErrSelfDestructContractOnStack, err)
+				return childInstance
+			})
+		})
+}
+
+func TestSelfDestruct_TransfersBalanceAndDeletesAccount(t *testing.T) {
+	test.BuildMockInstanceCallTest(t).
+		WithFeatures(arwen.SelfDestructFeature).
+		WithContracts(selfDestructParentMock(t)).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(1000).
+			WithFunction("destroy").
+			Build()).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok().
+				BalanceDelta(test.ParentAddress, -1000).
+				BalanceDelta(test.UserAddress, 1000).
+				DeletedAccounts(test.ParentAddress)
+
+			err := world.UpdateAccounts(verify.VmOutput.OutputAccounts, verify.VmOutput.DeletedAccounts)
+			require.Nil(t, err)
+			require.Nil(t, world.AcctMap.GetAccount(test.ParentAddress))
+		})
+}
+
+func TestSelfDestruct_BeneficiaryIsSelf(t *testing.T) {
+	test.BuildMockInstanceCallTest(t).
+		WithFeatures(arwen.SelfDestructFeature).
+		WithContracts(selfDestructParentMock(t)).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(1000).
+			WithFunction("destroyInFavourOfSelf").
+			Build()).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok().
+				DeletedAccounts()
+		})
+}
+
+func TestSelfDestruct_ContractOnTheStack(t *testing.T) {
+	test.BuildMockInstanceCallTest(t).
+		WithFeatures(arwen.SelfDestructFeature).
+		WithContracts(
+			selfDestructParentMock(t),
+			selfDestructChildMock(t),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok().
+				DeletedAccounts()
+		})
+}
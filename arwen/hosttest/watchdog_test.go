@@ -0,0 +1,182 @@
+package hosttest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	mock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/context"
+	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
+	test "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/testcommon"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock expires the timers of the watchdog when the tests decide,
+// instead of after their duration
+type manualClock struct {
+	mutex  sync.Mutex
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock   *manualClock
+	expire  func()
+	stopped bool
+}
+
+func (clock *manualClock) AfterFunc(_ time.Duration, expire func()) arwen.ExecutionTimer {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	timer := &manualTimer{
+		clock:  clock,
+		expire: expire,
+	}
+	clock.timers = append(clock.timers, timer)
+	return timer
+}
+
+func (timer *manualTimer) Stop() bool {
+	timer.clock.mutex.Lock()
+	defer timer.clock.mutex.Unlock()
+
+	wasRunning := !timer.stopped
+	timer.stopped = true
+	return wasRunning
+}
+
+// expireAll expires the timers which are still running; the watchdog is
+// called outside the lock, because it stops its timers under its own lock
+func (clock *manualClock) expireAll() {
+	clock.mutex.Lock()
+	running := make([]*manualTimer, 0)
+	for _, timer := range clock.timers {
+		if !timer.stopped {
+			timer.stopped = true
+			running = append(running, timer)
+		}
+	}
+	clock.mutex.Unlock()
+
+	for _, timer := range running {
+		timer.expire()
+	}
+}
+
+func (clock *manualClock) runningTimers() int {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	running := 0
+	for _, timer := range clock.timers {
+		if !timer.stopped {
+			running++
+		}
+	}
+	return running
+}
+
+func watchdogChildMock(clock *manualClock, expire bool) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ChildAddress).
+		WithBalance(0).
+		WithMethods(func(childInstance *mock.InstanceMock, config interface{}) {
+			childInstance.AddMockMethod("stall", func() *mock.InstanceMock {
+				host := childInstance.Host
+				instance := mock.GetMockInstance(host)
+				if expire {
+					// expire the timer on another goroutine, as the system
+					// clock does, but before the method returns
+					expired := make(chan struct{})
+					go func() {
+						clock.expireAll()
+						close(expired)
+					}()
+					<-expired
+				}
+				return instance
+			})
+		})
+}
+
+func watchdogParentMock(t *testing.T) test.MockTestSmartContract {
+	return test.CreateMockContract(test.ParentAddress).
+		WithBalance(1000).
+		WithMethods(func(parentInstance *mock.InstanceMock, config interface{}) {
+			parentInstance.AddMockMethod("callChild", func() *mock.InstanceMock {
+				host := parentInstance.Host
+				instance := mock.GetMockInstance(host)
+				childInput := test.DefaultTestContractCallInput()
+				childInput.CallerAddr = test.ParentAddress
+				childInput.RecipientAddr = test.ChildAddress
+				childInput.Function = "stall"
+				childInput.GasProvided = 1000
+				_, _, err := host.ExecuteOnDestContext(childInput)
+				require.ErrorIs(t, err, arwen.ErrExecutionTimeout)
+				return instance
+			})
+		})
+}
+
+func TestExecutionWatchdog_Timeout(t *testing.T) {
+	clock := &manualClock{}
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			watchdogChildMock(clock, true),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ChildAddress).
+			WithGasProvided(2000).
+			WithFunction("stall").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetExecutionTimeout(time.Second)
+			host.SetExecutionClock(clock)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.ReturnCode(arwen.ExecutionTimeout)
+		})
+}
+
+func TestExecutionWatchdog_TimeoutInChildStopsParent(t *testing.T) {
+	clock := &manualClock{}
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			watchdogParentMock(t),
+			watchdogChildMock(clock, true),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ParentAddress).
+			WithGasProvided(2000).
+			WithFunction("callChild").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetExecutionTimeout(time.Second)
+			host.SetExecutionClock(clock)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.ReturnCode(arwen.ExecutionTimeout)
+		})
+}
+
+func TestExecutionWatchdog_WithinBudget(t *testing.T) {
+	clock := &manualClock{}
+	test.BuildMockInstanceCallTest(t).
+		WithContracts(
+			watchdogChildMock(clock, false),
+		).
+		WithInput(test.CreateTestContractCallInputBuilder().
+			WithRecipientAddr(test.ChildAddress).
+			WithGasProvided(2000).
+			WithFunction("stall").
+			Build()).
+		WithSetup(func(host arwen.VMHost, world *worldmock.MockWorld) {
+			host.SetExecutionTimeout(time.Second)
+			host.SetExecutionClock(clock)
+		}).
+		AndAssertResults(func(world *worldmock.MockWorld, verify *test.VMOutputVerifier) {
+			verify.Ok()
+			require.Len(t, clock.timers, 1)
+			require.Zero(t, clock.runningTimers())
+		})
+}
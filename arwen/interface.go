@@ -3,6 +3,7 @@ package arwen
 import (
 	"crypto/elliptic"
 	"math/big"
+	"time"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/crypto"
@@ -51,9 +52,46 @@ type VMHost interface {
 	SetRuntimeContext(runtime RuntimeContext)
 
 	SetBuiltInFunctionsContainer(builtInFuncs vmcommon.BuiltInFunctionContainer)
+	SetExecutionObserver(observer ExecutionObserver)
+	SetCallTreeEnabled(enabled bool)
+	IsCallTreeEnabled() bool
+	GetCallTree() *CallTreeNode
+	SetAsyncGasReportEnabled(enabled bool)
+	GetAsyncGasReport() *AsyncGasReport
+	SetExecutionTimeout(timeout time.Duration)
+	SetExecutionClock(clock ExecutionClock)
+	SetReentrancyProtectedContracts(addresses [][]byte)
+	SetMaxCallDepth(depth int)
+	IsFeatureEnabled(flag FeatureFlag) bool
+	RegisterEEIVersion(version EEIVersion, imports *wasmer.Imports) error
+	LockEEIVersion(version EEIVersion) (func(), error)
+	SetPanicReportDirectory(directory string)
+	EstimateGas(input *vmcommon.ContractCallInput) (*GasEstimate, error)
+	EstimateGasForCreate(input *vmcommon.ContractCreateInput) (*GasEstimate, error)
 	InitState()
 }
 
+// ExecutionClock defines the functionality for starting the timers of the
+// execution watchdog, which stop the executions exceeding their wall-clock
+// budget
+type ExecutionClock interface {
+	AfterFunc(duration time.Duration, expire func()) ExecutionTimer
+}
+
+// ExecutionTimer defines the functionality for stopping a timer started by
+// an ExecutionClock; Stop returns false if the timer has already expired
+type ExecutionTimer interface {
+	Stop() bool
+}
+
+// ExecutionObserver defines the functionality for receiving structured events
+// about the contracts entered and exited by the VM host
+type ExecutionObserver interface {
+	OnContractEnter(event *ExecutionEvent)
+	OnContractExit(event *ExecutionEvent)
+	IsInterfaceNil() bool
+}
+
 // BlockchainContext defines the functionality needed for interacting with the blockchain context
 type BlockchainContext interface {
 	StateStack
@@ -85,6 +123,7 @@ type BlockchainContext interface {
 	IsPayable(address []byte) (bool, error)
 	SaveCompiledCode(codeHash []byte, code []byte)
 	GetCompiledCode(codeHash []byte) (bool, []byte)
+	UpdateCompiledCodeVersion(opcodeCosts *config.WASMOpcodeCost)
 	GetESDTToken(address []byte, tokenID []byte, nonce uint64) (*esdt.ESDigitalToken, error)
 	GetUserAccount(address []byte) (vmcommon.UserAccountHandler, error)
 	ProcessBuiltInFunction(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error)
@@ -116,11 +155,15 @@ type RuntimeContext interface {
 	SetRuntimeBreakpointValue(value BreakpointValue)
 	GetRuntimeBreakpointValue() BreakpointValue
 	IsContractOnTheStack(address []byte) bool
+	CallDepth() int
 	GetAsyncCallInfo() *AsyncCallInfo
 	SetAsyncCallInfo(asyncCallInfo *AsyncCallInfo)
 	AddAsyncContextCall(contextIdentifier []byte, asyncCall *AsyncGeneratedCall) error
 	GetAsyncContextInfo() *AsyncContextInfo
+	SetAsyncContextInfo(asyncContextInfo *AsyncContextInfo)
 	GetAsyncContext(contextIdentifier []byte) (*AsyncContext, error)
+	SetCallbackClosure(callbackClosure []byte)
+	GetCallbackClosure() []byte
 	RunningInstancesCount() uint64
 	IsFunctionImported(name string) bool
 	ReadOnly() bool
@@ -128,6 +171,10 @@ type RuntimeContext interface {
 	StartWasmerInstance(contract []byte, gasLimit uint64, newCode bool) error
 	CleanWasmerInstance()
 	SetMaxInstanceCount(uint64)
+	SetContractComplexityLimits(limits ContractComplexityLimits)
+	SetMaxMemoryPages(pages uint32)
+	MemoryPages() uint32
+	ChargeMemoryGrowth(pagesBefore uint32) error
 	VerifyContractCode() error
 	GetInstance() wasmer.InstanceHandler
 	GetInstanceExports() wasmer.ExportsMap
@@ -196,7 +243,7 @@ type OutputContext interface {
 	TransferValueOnly(destination []byte, sender []byte, value *big.Int, checkPayable bool) error
 	Transfer(destination []byte, sender []byte, gasLimit uint64, gasLocked uint64, value *big.Int, input []byte, callType vm.CallType) error
 	TransferESDT(destination []byte, sender []byte, transfers []*vmcommon.ESDTTransfer, callInput *vmcommon.ContractCallInput) (uint64, error)
-	SelfDestruct(address []byte, beneficiary []byte)
+	SelfDestruct(address []byte, beneficiary []byte) error
 	GetRefund() uint64
 	SetRefund(refund uint64)
 	ReturnCode() vmcommon.ReturnCode
@@ -205,6 +252,7 @@ type OutputContext interface {
 	SetReturnMessage(message string)
 	ReturnData() [][]byte
 	ClearReturnData()
+	GetLogs() []*vmcommon.LogEntry
 	Finish(data []byte)
 	PrependFinish(data []byte)
 	GetVMOutput() *vmcommon.VMOutput
@@ -272,6 +320,7 @@ type StorageContext interface {
 	GetStorageUnmetered(key []byte) []byte
 	SetStorage(key []byte, value []byte) (StorageStatus, error)
 	SetProtectedStorage(key []byte, value []byte) (StorageStatus, error)
+	IsStorageUpdateWritten(update *vmcommon.StorageUpdate) bool
 }
 
 // AsyncCallInfoHandler defines the functionality for working with AsyncCallInfo
@@ -281,6 +330,7 @@ type AsyncCallInfoHandler interface {
 	GetGasLimit() uint64
 	GetGasLocked() uint64
 	GetValueBytes() []byte
+	GetCallbackClosure() []byte
 }
 
 // InstanceBuilder defines the functionality needed to create Wasmer instances
@@ -288,3 +338,13 @@ type InstanceBuilder interface {
 	NewInstanceWithOptions(contractCode []byte, options wasmer.CompilationOptions) (wasmer.InstanceHandler, error)
 	NewInstanceFromCompiledCodeWithOptions(compiledCode []byte, options wasmer.CompilationOptions) (wasmer.InstanceHandler, error)
 }
+
+// CompiledCodeCache keeps the compiled code of contracts, keyed by their code
+// hash and by the version of the compilation, so that hosts need not compile
+// the same contracts again
+type CompiledCodeCache interface {
+	Get(codeHash []byte, version string) ([]byte, bool)
+	Put(codeHash []byte, version string, compiledCode []byte)
+	Statistics() CompiledCodeCacheStatistics
+	IsInterfaceNil() bool
+}
@@ -0,0 +1,69 @@
+package arwen
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ExecutionPanicReport describes a panic recovered by the host while executing
+// a contract, together with the state of the runtime at the time of the panic
+type ExecutionPanicReport struct {
+	Operation       string
+	PanicValue      interface{}
+	Stack           []byte
+	ContractAddress []byte
+	Function        string
+	CallDepth       int
+	Breakpoint      BreakpointValue
+}
+
+// Error returns the message of the recovered error, if the panic was caused by
+// one, or a message built from the recovered value otherwise
+func (report *ExecutionPanicReport) Error() string {
+	err, ok := report.PanicValue.(error)
+	if ok {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%s, panic: %v", report.Operation, report.PanicValue)
+}
+
+// Unwrap returns the recovered error, if the panic was caused by one
+func (report *ExecutionPanicReport) Unwrap() error {
+	err, _ := report.PanicValue.(error)
+	return err
+}
+
+// String formats the report for inclusion in a bug ticket
+func (report *ExecutionPanicReport) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "operation: %s\n", report.Operation)
+	fmt.Fprintf(builder, "panic: %v\n", report.PanicValue)
+	fmt.Fprintf(builder, "contract: %s\n", hex.EncodeToString(report.ContractAddress))
+	fmt.Fprintf(builder, "function: %s\n", report.Function)
+	fmt.Fprintf(builder, "call depth: %d\n", report.CallDepth)
+	fmt.Fprintf(builder, "breakpoint: %d\n", report.Breakpoint)
+	fmt.Fprintf(builder, "stack:\n%s", report.Stack)
+	return builder.String()
+}
+
+// GetExecutionPanicReport finds the ExecutionPanicReport among the given
+// errors, which may be wrapped in a WrappableError
+func GetExecutionPanicReport(err error) (*ExecutionPanicReport, bool) {
+	wrappable, ok := err.(WrappableError)
+	if ok {
+		for _, wrappedErr := range wrappable.GetAllErrors() {
+			report, found := GetExecutionPanicReport(wrappedErr)
+			if found {
+				return report, true
+			}
+		}
+		return nil, false
+	}
+
+	var report *ExecutionPanicReport
+	found := errors.As(err, &report)
+	return report, found
+}
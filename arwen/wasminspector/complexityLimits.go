@@ -0,0 +1,67 @@
+package wasminspector
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+)
+
+// VerifyComplexityLimits returns an error if the module exceeds any of the
+// given limits; the limits set to 0 are not enforced
+func VerifyComplexityLimits(module *wasmparser.Module, limits arwen.ContractComplexityLimits) error {
+	if limits.MaxFunctions > 0 && uint32(len(module.Functions)) > limits.MaxFunctions {
+		return fmt.Errorf("%w: %d functions", arwen.ErrTooManyFunctions, len(module.Functions))
+	}
+
+	if limits.MaxLocalsPerFunction > 0 {
+		for i, function := range module.Functions {
+			if function.LocalsCount > limits.MaxLocalsPerFunction {
+				return fmt.Errorf("%w: %d locals in function %d", arwen.ErrTooManyLocals, function.LocalsCount, i)
+			}
+		}
+	}
+
+	for _, memory := range module.Memories {
+		if limits.MaxInitialMemoryPages > 0 && memory.Min > limits.MaxInitialMemoryPages {
+			return fmt.Errorf("%w: %d pages", arwen.ErrInitialMemoryTooLarge, memory.Min)
+		}
+		if limits.MaxMemoryPages > 0 && memory.MaximumPages() > limits.MaxMemoryPages {
+			return fmt.Errorf("%w: %d pages", arwen.ErrMaximumMemoryTooLarge, memory.MaximumPages())
+		}
+	}
+
+	if limits.MaxTableSize > 0 {
+		for _, table := range module.Tables {
+			tableSize := table.Min
+			if table.HasMax && table.Max > tableSize {
+				tableSize = table.Max
+			}
+			if tableSize > limits.MaxTableSize {
+				return fmt.Errorf("%w: %d elements", arwen.ErrTableTooLarge, tableSize)
+			}
+		}
+	}
+
+	if limits.MaxDataSegmentSize > 0 {
+		for _, segment := range module.DataSegments {
+			if segment.Size > limits.MaxDataSegmentSize {
+				return fmt.Errorf("%w: %d bytes", arwen.ErrDataSegmentTooLarge, segment.Size)
+			}
+		}
+	}
+
+	if limits.MaxExportedEndpoints > 0 {
+		endpoints := uint32(0)
+		for _, export := range module.Exports {
+			if export.Kind == wasmparser.ExternalFunction {
+				endpoints++
+			}
+		}
+		if endpoints > limits.MaxExportedEndpoints {
+			return fmt.Errorf("%w: %d endpoints", arwen.ErrTooManyEndpoints, endpoints)
+		}
+	}
+
+	return nil
+}
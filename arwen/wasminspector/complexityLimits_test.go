@@ -0,0 +1,91 @@
+package wasminspector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyComplexityLimits_NotEnforced(t *testing.T) {
+	module, err := ParseModule(getTestContractCode("erc20"))
+	require.Nil(t, err)
+
+	limits := arwen.ContractComplexityLimits{}
+	require.False(t, limits.IsEnforced())
+	require.Nil(t, VerifyComplexityLimits(module, limits))
+}
+
+func TestVerifyComplexityLimits_EachLimit(t *testing.T) {
+	module := &wasmparser.Module{
+		Functions: []*wasmparser.Function{
+			{Signature: &wasmparser.FunctionSignature{}, LocalsCount: 2},
+			{Signature: &wasmparser.FunctionSignature{}, LocalsCount: 10},
+		},
+		Memories:     []*wasmparser.Memory{{Limits: wasmparser.Limits{Min: 2, Max: 16, HasMax: true}}},
+		Tables:       []*wasmparser.Table{{Limits: wasmparser.Limits{Min: 4, Max: 8, HasMax: true}, ElementType: wasmparser.ValueTypeFuncRef}},
+		DataSegments: []*wasmparser.DataSegment{{Size: 100}},
+		Exports: []*wasmparser.Export{
+			{Name: "memory", Kind: wasmparser.ExternalMemory},
+			{Name: "init", Kind: wasmparser.ExternalFunction},
+			{Name: "main", Kind: wasmparser.ExternalFunction},
+		},
+	}
+
+	require.Nil(t, VerifyComplexityLimits(module, arwen.ContractComplexityLimits{
+		MaxFunctions:          2,
+		MaxLocalsPerFunction:  10,
+		MaxInitialMemoryPages: 2,
+		MaxMemoryPages:        16,
+		MaxTableSize:          8,
+		MaxDataSegmentSize:    100,
+		MaxExportedEndpoints:  2,
+	}))
+
+	testCases := []struct {
+		limits      arwen.ContractComplexityLimits
+		expectedErr error
+	}{
+		{arwen.ContractComplexityLimits{MaxFunctions: 1}, arwen.ErrTooManyFunctions},
+		{arwen.ContractComplexityLimits{MaxLocalsPerFunction: 9}, arwen.ErrTooManyLocals},
+		{arwen.ContractComplexityLimits{MaxInitialMemoryPages: 1}, arwen.ErrInitialMemoryTooLarge},
+		{arwen.ContractComplexityLimits{MaxMemoryPages: 15}, arwen.ErrMaximumMemoryTooLarge},
+		{arwen.ContractComplexityLimits{MaxTableSize: 7}, arwen.ErrTableTooLarge},
+		{arwen.ContractComplexityLimits{MaxDataSegmentSize: 99}, arwen.ErrDataSegmentTooLarge},
+		{arwen.ContractComplexityLimits{MaxExportedEndpoints: 1}, arwen.ErrTooManyEndpoints},
+	}
+
+	for _, testCase := range testCases {
+		require.True(t, testCase.limits.IsEnforced())
+		err := VerifyComplexityLimits(module, testCase.limits)
+		require.True(t, errors.Is(err, testCase.expectedErr), testCase.expectedErr.Error())
+		require.True(t, errors.Is(err, arwen.ErrContractInvalid))
+	}
+}
+
+func TestVerifyComplexityLimits_MemoryWithoutMaximum(t *testing.T) {
+	module, err := ParseModule(getTestContractCode("counter"))
+	require.Nil(t, err)
+	require.False(t, module.Memories[0].HasMax)
+
+	err = VerifyComplexityLimits(module, arwen.ContractComplexityLimits{MaxMemoryPages: 1})
+	require.True(t, errors.Is(err, arwen.ErrMaximumMemoryTooLarge))
+
+	err = VerifyComplexityLimits(module, arwen.ContractComplexityLimits{MaxMemoryPages: module.Memories[0].MaximumPages()})
+	require.Nil(t, err)
+}
+
+func TestModuleInspector_ComplexityLimits(t *testing.T) {
+	inspector := createTestInspector(t, true)
+	code := getTestContractCode("counter")
+
+	_, err := inspector.Inspect(code)
+	require.Nil(t, err)
+
+	inspector.SetComplexityLimits(arwen.ContractComplexityLimits{MaxExportedEndpoints: 3})
+	report, err := inspector.Inspect(code)
+	require.True(t, errors.Is(err, arwen.ErrTooManyEndpoints))
+	require.False(t, report.Accepted)
+}
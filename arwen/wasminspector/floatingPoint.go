@@ -0,0 +1,19 @@
+package wasminspector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+)
+
+// VerifyNoFloatOpcodes returns an error naming the floating point
+// instructions found in the code of the module, if there are any
+func VerifyNoFloatOpcodes(module *wasmparser.Module) error {
+	if !module.UsesFloatOpcodes() {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", arwen.ErrFloatingPointOpcode, strings.Join(module.FloatOpcodeNames(), ", "))
+}
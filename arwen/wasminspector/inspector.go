@@ -0,0 +1,252 @@
+package wasminspector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// importsModuleName is the module from which the contracts import the EEI
+const importsModuleName = "env"
+
+const maxLengthOfFunctionName = 256
+
+// FeatureChecker tells whether a feature of the VM is enabled, like the VMHost
+type FeatureChecker interface {
+	IsFeatureEnabled(flag arwen.FeatureFlag) bool
+}
+
+// Report holds the description of an inspected contract, together with the
+// verdict of the checks done by the VM when deploying it and the warnings
+// which the VM would only log; the description is missing if the contract is
+// not a well-formed WASM module
+type Report struct {
+	Module          *wasmparser.Module
+	UnknownImports  []*wasmparser.Import
+	Accepted        bool
+	RejectionReason string
+	Warnings        []string
+}
+
+// moduleInspector inspects contracts without instantiating them, applying
+// the same checks as the Wasmer instantiation and the wasmValidator
+type moduleInspector struct {
+	scAPINames       vmcommon.FunctionNames
+	reservedNames    vmcommon.FunctionNames
+	featureChecker   FeatureChecker
+	complexityLimits arwen.ContractComplexityLimits
+}
+
+// NewModuleInspector creates a new inspector, which accepts the contracts
+// importing only the given EEI functions; without a FeatureChecker, all the
+// features are considered enabled
+func NewModuleInspector(
+	scAPINames vmcommon.FunctionNames,
+	builtInFuncContainer vmcommon.BuiltInFunctionContainer,
+	featureChecker FeatureChecker,
+) (*moduleInspector, error) {
+	if len(scAPINames) == 0 {
+		return nil, arwen.ErrNilEEIImports
+	}
+	if arwen.IfNil(builtInFuncContainer) {
+		return nil, arwen.ErrNilBuiltInFunctionsContainer
+	}
+
+	return &moduleInspector{
+		scAPINames:     scAPINames,
+		reservedNames:  makeReservedNames(scAPINames, builtInFuncContainer),
+		featureChecker: featureChecker,
+	}, nil
+}
+
+// NewModuleInspectorForHost creates a new inspector which applies the checks
+// of the given host, for its current EEI version and epoch
+func NewModuleInspectorForHost(host arwen.VMHost, builtInFuncContainer vmcommon.BuiltInFunctionContainer) (*moduleInspector, error) {
+	if arwen.IfNil(host) {
+		return nil, arwen.ErrNilVMHost
+	}
+
+	return NewModuleInspector(host.GetAPIMethods().Names(), builtInFuncContainer, host)
+}
+
+// makeReservedNames gathers the names which contracts may not export, like
+// the reservedFunctions of the runtime
+func makeReservedNames(scAPINames vmcommon.FunctionNames, builtInFuncContainer vmcommon.BuiltInFunctionContainer) vmcommon.FunctionNames {
+	reservedNames := make(vmcommon.FunctionNames)
+	for name := range builtInFuncContainer.Keys() {
+		function, err := builtInFuncContainer.Get(name)
+		if err != nil || !function.IsActive() {
+			continue
+		}
+		reservedNames[name] = struct{}{}
+	}
+
+	for name := range scAPINames {
+		reservedNames[name] = struct{}{}
+	}
+	reservedNames[arwen.UpgradeFunctionName] = struct{}{}
+
+	return reservedNames
+}
+
+// SetComplexityLimits sets the limits of the contract complexity, which
+// should match those of the host
+func (inspector *moduleInspector) SetComplexityLimits(limits arwen.ContractComplexityLimits) {
+	inspector.complexityLimits = limits
+}
+
+// Inspect describes the contract and returns nil if the VM would accept to
+// deploy it, or the reason for which it would be rejected otherwise
+func (inspector *moduleInspector) Inspect(code []byte) (*Report, error) {
+	report := &Report{
+		UnknownImports: make([]*wasmparser.Import, 0),
+		Warnings:       make([]string, 0),
+	}
+
+	module, err := ParseModule(code)
+	if err == nil {
+		report.Module = module
+		report.UnknownImports = inspector.findUnknownImports(module)
+		err = inspector.verify(report)
+	}
+
+	report.Accepted = err == nil
+	if err != nil {
+		report.RejectionReason = err.Error()
+	}
+
+	return report, err
+}
+
+func (inspector *moduleInspector) findUnknownImports(module *wasmparser.Module) []*wasmparser.Import {
+	unknownImports := make([]*wasmparser.Import, 0)
+	for _, imported := range module.Imports {
+		_, isEEIFunction := inspector.scAPINames[imported.Name]
+		isKnown := imported.Module == importsModuleName &&
+			imported.Kind == wasmparser.ExternalFunction &&
+			isEEIFunction
+		if !isKnown {
+			unknownImports = append(unknownImports, imported)
+		}
+	}
+
+	return unknownImports
+}
+
+// verify applies the checks in the order of the deployment: complexity
+// limits, compilation, instantiation, then the wasmValidator
+func (inspector *moduleInspector) verify(report *Report) error {
+	module := report.Module
+	err := VerifyComplexityLimits(module, inspector.complexityLimits)
+	if err != nil {
+		return err
+	}
+
+	err = inspector.verifyFloatingPoint(report)
+	if err != nil {
+		return err
+	}
+
+	if len(report.UnknownImports) > 0 {
+		unknownImport := report.UnknownImports[0]
+		return fmt.Errorf("%w: %s.%s", arwen.ErrUnknownImport, unknownImport.Module, unknownImport.Name)
+	}
+
+	if !module.HasMemory() {
+		return arwen.ErrMemoryDeclarationMissing
+	}
+
+	err = inspector.verifyFunctions(module)
+	if err != nil {
+		return err
+	}
+
+	return inspector.verifyFeatureGatedImports(module)
+}
+
+// verifyFloatingPoint rejects the contracts containing floating point
+// instructions like the wasmValidator, or only reports a warning about them
+// before the activation of the FloatingPointRejectionFeature
+func (inspector *moduleInspector) verifyFloatingPoint(report *Report) error {
+	err := VerifyNoFloatOpcodes(report.Module)
+	if err == nil || inspector.isFeatureEnabled(arwen.FloatingPointRejectionFeature) {
+		return err
+	}
+
+	report.Warnings = append(report.Warnings, err.Error())
+	return nil
+}
+
+func (inspector *moduleInspector) verifyFunctions(module *wasmparser.Module) error {
+	for _, export := range module.Exports {
+		if export.Kind != wasmparser.ExternalFunction {
+			continue
+		}
+
+		err := inspector.verifyValidFunctionName(export.Name)
+		if err != nil {
+			return err
+		}
+
+		if !export.Signature.IsVoid() {
+			return fmt.Errorf("%w: %s", arwen.ErrFunctionNonvoidSignature, export.Name)
+		}
+	}
+
+	return nil
+}
+
+func (inspector *moduleInspector) verifyValidFunctionName(functionName string) error {
+	errInvalidName := fmt.Errorf("%w: %s", arwen.ErrInvalidFunctionName, functionName)
+
+	if len(functionName) == 0 || len(functionName) >= maxLengthOfFunctionName {
+		return errInvalidName
+	}
+	for i := 0; i < len(functionName); i++ {
+		if functionName[i] > 127 {
+			return errInvalidName
+		}
+	}
+	if _, isReserved := inspector.reservedNames[functionName]; isReserved {
+		return errInvalidName
+	}
+	flag, isFeatureReserved := arwen.FeatureReservedFunctionNames[functionName]
+	if isFeatureReserved && inspector.isFeatureEnabled(flag) {
+		return errInvalidName
+	}
+
+	return nil
+}
+
+func (inspector *moduleInspector) isFeatureEnabled(flag arwen.FeatureFlag) bool {
+	return inspector.featureChecker == nil || inspector.featureChecker.IsFeatureEnabled(flag)
+}
+
+func (inspector *moduleInspector) verifyFeatureGatedImports(module *wasmparser.Module) error {
+	if inspector.featureChecker == nil {
+		return nil
+	}
+
+	functionNames := make([]string, 0, len(arwen.FeatureGatedEEIFunctions))
+	for functionName := range arwen.FeatureGatedEEIFunctions {
+		functionNames = append(functionNames, functionName)
+	}
+	sort.Strings(functionNames)
+
+	for _, functionName := range functionNames {
+		flag := arwen.FeatureGatedEEIFunctions[functionName]
+		if module.IsFunctionImported(functionName) && !inspector.featureChecker.IsFeatureEnabled(flag) {
+			return fmt.Errorf("%w: %s", arwen.ErrFunctionNotEnabled, functionName)
+		}
+	}
+
+	return nil
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (inspector *moduleInspector) IsInterfaceNil() bool {
+	return inspector == nil
+}
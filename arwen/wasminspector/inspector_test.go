@@ -0,0 +1,188 @@
+package wasminspector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/cryptoapi"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/elrondapi"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/ElrondNetwork/elrond-vm-common/builtInFunctions"
+	"github.com/stretchr/testify/require"
+)
+
+type featureCheckerStub struct {
+	enabled bool
+}
+
+func (checker *featureCheckerStub) IsFeatureEnabled(_ arwen.FeatureFlag) bool {
+	return checker.enabled
+}
+
+func makeAPINames(t *testing.T) vmcommon.FunctionNames {
+	imports, err := elrondapi.ElrondEIImports()
+	require.Nil(t, err)
+	imports, err = elrondapi.BigIntImports(imports)
+	require.Nil(t, err)
+	imports, err = elrondapi.SmallIntImports(imports)
+	require.Nil(t, err)
+	imports, err = elrondapi.ManagedBufferImports(imports)
+	require.Nil(t, err)
+	imports, err = cryptoapi.CryptoImports(imports)
+	require.Nil(t, err)
+
+	return imports.Names()
+}
+
+func createTestInspector(t *testing.T, featuresEnabled bool) *moduleInspector {
+	inspector, err := NewModuleInspector(
+		makeAPINames(t),
+		builtInFunctions.NewBuiltInFunctionContainer(),
+		&featureCheckerStub{enabled: featuresEnabled},
+	)
+	require.Nil(t, err)
+
+	return inspector
+}
+
+// makeTestModule assembles a module which imports the given function from
+// the "env" module and exports a memory and a void function
+func makeTestModule(importedFunction string, exportedFunction string) []byte {
+	section := func(id byte, contents ...byte) []byte {
+		return append([]byte{id, byte(len(contents))}, contents...)
+	}
+	name := func(value string) []byte {
+		return append([]byte{byte(len(value))}, value...)
+	}
+
+	code := []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+	code = append(code, section(0x01, 0x01, 0x60, 0x00, 0x00)...) // types
+
+	importEntry := append(name("env"), name(importedFunction)...)
+	importEntry = append(importEntry, byte(wasmparser.ExternalFunction), 0x00)
+	code = append(code, section(0x02, append([]byte{0x01}, importEntry...)...)...) // imports
+
+	code = append(code, section(0x03, 0x01, 0x00)...)       // functions
+	code = append(code, section(0x05, 0x01, 0x00, 0x02)...) // memories
+
+	exports := append([]byte{0x02}, name("memory")...)
+	exports = append(exports, byte(wasmparser.ExternalMemory), 0x00)
+	exports = append(exports, name(exportedFunction)...)
+	exports = append(exports, byte(wasmparser.ExternalFunction), 0x01)
+	code = append(code, section(0x07, exports...)...) // exports
+
+	code = append(code, section(0x0A, 0x01, 0x02, 0x00, 0x0B)...) // code
+	return code
+}
+
+func TestNewModuleInspector(t *testing.T) {
+	inspector, err := NewModuleInspector(nil, builtInFunctions.NewBuiltInFunctionContainer(), nil)
+	require.Equal(t, arwen.ErrNilEEIImports, err)
+	require.True(t, inspector.IsInterfaceNil())
+
+	inspector, err = NewModuleInspector(makeAPINames(t), nil, nil)
+	require.Equal(t, arwen.ErrNilBuiltInFunctionsContainer, err)
+	require.True(t, inspector.IsInterfaceNil())
+
+	inspector, err = NewModuleInspector(makeAPINames(t), builtInFunctions.NewBuiltInFunctionContainer(), nil)
+	require.Nil(t, err)
+	require.False(t, inspector.IsInterfaceNil())
+}
+
+func TestModuleInspector_AcceptsValidContracts(t *testing.T) {
+	inspector := createTestInspector(t, true)
+
+	for _, name := range []string{"counter", "erc20", "managed-buffers", "exec-dest-ctx-parent"} {
+		report, err := inspector.Inspect(getTestContractCode(name))
+		require.Nil(t, err, name)
+		require.True(t, report.Accepted)
+		require.Empty(t, report.RejectionReason)
+		require.Len(t, report.UnknownImports, 0)
+		require.Len(t, report.Warnings, 0)
+	}
+}
+
+func TestModuleInspector_RejectsLikeTheValidator(t *testing.T) {
+	inspector := createTestInspector(t, true)
+
+	testCases := []struct {
+		contract    string
+		expectedErr error
+	}{
+		{"memoryless", arwen.ErrMemoryDeclarationMissing},
+		{"num-with-fp", arwen.ErrFloatingPointOpcode},
+		{"signatures", arwen.ErrFunctionNonvoidSignature},
+		{"init-wrong", arwen.ErrFunctionNonvoidSignature},
+	}
+
+	for _, testCase := range testCases {
+		report, err := inspector.Inspect(getTestContractCode(testCase.contract))
+		require.True(t, errors.Is(err, testCase.expectedErr), testCase.contract)
+		require.False(t, report.Accepted)
+		require.Equal(t, err.Error(), report.RejectionReason)
+		require.NotNil(t, report.Module)
+	}
+
+	_, err := inspector.Inspect(getTestContractCode("num-with-fp"))
+	require.Contains(t, err.Error(), "F32ConvertI64U")
+
+	// before the activation of the rejection, the validator only warns
+	report, err := createTestInspector(t, false).Inspect(getTestContractCode("num-with-fp"))
+	require.Nil(t, err)
+	require.True(t, report.Accepted)
+	require.Len(t, report.Warnings, 1)
+	require.Contains(t, report.Warnings[0], "F32ConvertI64U")
+
+	report, err = inspector.Inspect([]byte("not a WASM module"))
+	require.True(t, errors.Is(err, arwen.ErrMalformedWASMModule))
+	require.False(t, report.Accepted)
+	require.Nil(t, report.Module)
+}
+
+func TestModuleInspector_Imports(t *testing.T) {
+	inspector := createTestInspector(t, true)
+
+	report, err := inspector.Inspect(makeTestModule("getNumArguments", "main"))
+	require.Nil(t, err)
+	require.True(t, report.Accepted)
+
+	report, err = inspector.Inspect(makeTestModule("notAnEEIFunction", "main"))
+	require.True(t, errors.Is(err, arwen.ErrUnknownImport))
+	require.Len(t, report.UnknownImports, 1)
+	require.Equal(t, "notAnEEIFunction", report.UnknownImports[0].Name)
+}
+
+func TestModuleInspector_ExportNames(t *testing.T) {
+	inspector := createTestInspector(t, true)
+
+	_, err := inspector.Inspect(makeTestModule("getNumArguments", "getNumArguments"))
+	require.True(t, errors.Is(err, arwen.ErrInvalidFunctionName))
+
+	_, err = inspector.Inspect(makeTestModule("getNumArguments", arwen.UpgradeFunctionName))
+	require.True(t, errors.Is(err, arwen.ErrInvalidFunctionName))
+
+	_, err = inspector.Inspect(makeTestModule("getNumArguments", "mäin"))
+	require.True(t, errors.Is(err, arwen.ErrInvalidFunctionName))
+}
+
+func TestModuleInspector_FeatureGatedImports(t *testing.T) {
+	code := makeTestModule("getCallbackClosureLength", "main")
+
+	_, err := createTestInspector(t, true).Inspect(code)
+	require.Nil(t, err)
+
+	_, err = createTestInspector(t, false).Inspect(code)
+	require.True(t, errors.Is(err, arwen.ErrFunctionNotEnabled))
+}
+
+func TestModuleInspector_FeatureReservedExportNames(t *testing.T) {
+	code := makeTestModule("getNumArguments", arwen.ResolveExpiredAsyncCallsFunctionName)
+
+	_, err := createTestInspector(t, true).Inspect(code)
+	require.True(t, errors.Is(err, arwen.ErrInvalidFunctionName))
+
+	_, err = createTestInspector(t, false).Inspect(code)
+	require.Nil(t, err)
+}
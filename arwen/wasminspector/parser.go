@@ -0,0 +1,19 @@
+package wasminspector
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+)
+
+// ParseModule describes the WASM module without compiling it, rejecting it
+// as a malformed contract if it cannot be parsed
+func ParseModule(code []byte) (*wasmparser.Module, error) {
+	module, err := wasmparser.ParseModule(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", arwen.ErrMalformedWASMModule, err.Error())
+	}
+
+	return module, nil
+}
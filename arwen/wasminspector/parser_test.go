@@ -0,0 +1,25 @@
+package wasminspector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/stretchr/testify/require"
+)
+
+const contractsPath = "./../../test/contracts/"
+
+func getTestContractCode(name string) []byte {
+	return arwen.GetSCCode(contractsPath + name + "/output/" + name + ".wasm")
+}
+
+func TestParseModule_Malformed(t *testing.T) {
+	_, err := ParseModule([]byte("not a WASM module"))
+	require.True(t, errors.Is(err, arwen.ErrMalformedWASMModule))
+	require.True(t, errors.Is(err, arwen.ErrContractInvalid))
+
+	module, err := ParseModule(getTestContractCode("counter"))
+	require.Nil(t, err)
+	require.True(t, module.HasMemory())
+}
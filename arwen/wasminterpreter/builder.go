@@ -0,0 +1,98 @@
+package wasminterpreter
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+var _ executor.InstanceHandler = (*instance)(nil)
+
+// instanceBuilder creates interpreted instances instead of Wasmer ones. It
+// can replace the Wasmer builder of a RuntimeContext through
+// ReplaceInstanceBuilder(), in order to run contracts without compiling
+// them, or to compare the executions of the two implementations.
+//
+// The interpreter meters each executed instruction with the opcode costs of
+// Wasmer, while Wasmer charges whole blocks of instructions ahead of their
+// execution; therefore the gas consumed by the successful executions is the
+// same, but a failing execution may run out of gas at a different point.
+// Like the Wasmer instances used by Arwen, the interpreted instances reject
+// the floating point instructions. The opcode trace option is ignored;
+// the executed opcodes can be counted by a Profiler instead.
+//
+// The interpreter only depends on the executor and wasmparser packages,
+// which do not bind the Wasmer library; the VM host itself still needs it,
+// even if no Wasmer instance is created.
+type instanceBuilder struct {
+	imports     *executor.Imports
+	opcodeCosts *[executor.OPCODE_COUNT]uint32
+	profiler    *Profiler
+}
+
+// NewInstanceBuilder creates a builder of interpreted instances bound to
+// the imports last given to wasmer.SetImports and charging the opcode costs
+// last given to wasmer.SetOpcodeCosts, as recorded in the executor package,
+// exactly like the Wasmer instances
+func NewInstanceBuilder() *instanceBuilder {
+	return &instanceBuilder{}
+}
+
+// NewInstanceBuilderWithImports creates a builder of interpreted instances
+// bound to the given imports and charging the given opcode costs,
+// regardless of the settings recorded in the executor package
+func NewInstanceBuilderWithImports(imports *executor.Imports, opcodeCosts *[executor.OPCODE_COUNT]uint32) *instanceBuilder {
+	return &instanceBuilder{
+		imports:     imports,
+		opcodeCosts: opcodeCosts,
+	}
+}
+
+// SetProfiler makes the given profiler count the opcodes executed by the
+// instances created afterwards; a nil profiler disables the profiling
+func (builder *instanceBuilder) SetProfiler(profiler *Profiler) {
+	builder.profiler = profiler
+}
+
+// NewInstanceWithOptions creates a new interpreted instance from WASM
+// bytecode, respecting the provided options
+func (builder *instanceBuilder) NewInstanceWithOptions(
+	contractCode []byte,
+	options executor.CompilationOptions,
+) (executor.InstanceHandler, error) {
+	if len(contractCode) == 0 {
+		return nil, executor.ErrInvalidBytecode
+	}
+
+	imports := builder.imports
+	if imports == nil {
+		imports = executor.GetImports()
+	}
+
+	opcodeCosts := builder.opcodeCosts
+	if opcodeCosts == nil {
+		opcodeCosts = executor.GetOpcodeCosts()
+	}
+
+	newInstance, err := newInstance(contractCode, imports, opcodeCosts, options, builder.profiler)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", executor.ErrFailedInstantiation, err.Error())
+	}
+
+	return newInstance, nil
+}
+
+// NewInstanceFromCompiledCodeWithOptions creates a new interpreted instance
+// from the code returned by the Cache() method of an interpreted instance;
+// the code cached by Wasmer is rejected, so that the RuntimeContext falls
+// back to the bytecode of the contract
+func (builder *instanceBuilder) NewInstanceFromCompiledCodeWithOptions(
+	compiledCode []byte,
+	options executor.CompilationOptions,
+) (executor.InstanceHandler, error) {
+	if !isInterpreterCache(compiledCode) {
+		return nil, executor.ErrInvalidBytecode
+	}
+
+	return builder.NewInstanceWithOptions(compiledCode[len(cacheHeader):], options)
+}
@@ -0,0 +1,302 @@
+package wasminterpreter
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+const (
+	opcodeEnd        = 0x0B
+	opcodeI32Const   = 0x41
+	opcodeI64Const   = 0x42
+	opcodeMiscPrefix = 0xFC
+)
+
+// instruction is a decoded instruction, identified by its index in the
+// opcode cost table of Wasmer; the blocks know the position of their else
+// and end instructions, so that no scanning is needed while executing
+type instruction struct {
+	opcode         int
+	value          uint64
+	params         uint32
+	results        uint32
+	elsePosition   int
+	endPosition    int
+	depths         []uint32
+	accessesMemory bool
+}
+
+type openBlock struct {
+	position     int
+	elsePosition int
+}
+
+// functionCompiler turns the code of a function body into instructions
+type functionCompiler struct {
+	reader *wasmparser.Reader
+	module *module
+}
+
+func (compiler *functionCompiler) compile() ([]instruction, error) {
+	reader := compiler.reader
+	instructions := make([]instruction, 0)
+	openBlocks := make([]openBlock, 0)
+	functionEnded := false
+
+	for !reader.IsAtEnd() {
+		if functionEnded {
+			return nil, fmt.Errorf("instructions after the end of the function")
+		}
+
+		current, err := compiler.readInstruction()
+		if err != nil {
+			return nil, err
+		}
+
+		position := len(instructions)
+		switch current.opcode {
+		case executor.OpcodeBlock, executor.OpcodeLoop, executor.OpcodeIf:
+			openBlocks = append(openBlocks, openBlock{position: position})
+		case executor.OpcodeElse:
+			last := len(openBlocks) - 1
+			if last < 0 || instructions[openBlocks[last].position].opcode != executor.OpcodeIf || openBlocks[last].elsePosition != 0 {
+				return nil, fmt.Errorf("else without if")
+			}
+			openBlocks[last].elsePosition = position
+			instructions[openBlocks[last].position].elsePosition = position
+		case executor.OpcodeEnd:
+			last := len(openBlocks) - 1
+			if last < 0 {
+				functionEnded = true
+				break
+			}
+			instructions[openBlocks[last].position].endPosition = position
+			if openBlocks[last].elsePosition != 0 {
+				instructions[openBlocks[last].elsePosition].endPosition = position
+			}
+			openBlocks = openBlocks[:last]
+		case executor.OpcodeBr, executor.OpcodeBrIf:
+			if current.value > uint64(len(openBlocks)) {
+				return nil, fmt.Errorf("invalid branch depth %d", current.value)
+			}
+		case executor.OpcodeBrTable:
+			for _, depth := range current.depths {
+				if depth > uint32(len(openBlocks)) {
+					return nil, fmt.Errorf("invalid branch depth %d", depth)
+				}
+			}
+		}
+
+		instructions = append(instructions, current)
+	}
+
+	if !functionEnded {
+		return nil, fmt.Errorf("missing end of the function")
+	}
+
+	return instructions, nil
+}
+
+func (compiler *functionCompiler) readInstruction() (instruction, error) {
+	reader := compiler.reader
+	current := instruction{}
+
+	code, err := reader.ReadByte()
+	if err != nil {
+		return current, err
+	}
+
+	switch {
+	case code <= 0x01:
+		// unreachable, nop
+		current.opcode = executor.OpcodeUnreachable + int(code)
+	case code >= 0x02 && code <= 0x04:
+		current.opcode = executor.OpcodeBlock + int(code-0x02)
+		current.params, current.results, err = compiler.readBlockType()
+	case code == 0x05:
+		current.opcode = executor.OpcodeElse
+	case code == opcodeEnd:
+		current.opcode = executor.OpcodeEnd
+	case code == 0x0C || code == 0x0D:
+		current.opcode = executor.OpcodeBr + int(code-0x0C)
+		current.value, err = compiler.readIndex()
+	case code == 0x0E:
+		current.opcode = executor.OpcodeBrTable
+		current.depths, err = reader.ReadU32Vector()
+		if err == nil {
+			var defaultDepth uint32
+			defaultDepth, err = reader.ReadU32()
+			current.depths = append(current.depths, defaultDepth)
+		}
+	case code == 0x0F:
+		current.opcode = executor.OpcodeReturn
+	case code == 0x10:
+		current.opcode = executor.OpcodeCall
+		current.value, err = compiler.readIndex()
+	case code == 0x11:
+		current.opcode = executor.OpcodeCallIndirect
+		current.value, err = compiler.readIndex()
+		if err == nil && current.value >= uint64(len(compiler.module.types)) {
+			err = fmt.Errorf("invalid type index %d", current.value)
+		}
+		if err == nil {
+			err = expectByte(reader, 0x00, "table index")
+		}
+	case code == 0x1A:
+		current.opcode = executor.OpcodeDrop
+	case code == 0x1B:
+		current.opcode = executor.OpcodeSelect
+	case code == 0x1C:
+		current.opcode = executor.OpcodeTypedSelect
+		_, err = reader.ReadValueTypes()
+	case code >= 0x20 && code <= 0x24:
+		current.opcode = executor.OpcodeLocalGet + int(code-0x20)
+		current.value, err = compiler.readIndex()
+	case code >= 0x28 && code <= 0x3E:
+		if isFloatMemoryOpcode(code) {
+			return current, fmt.Errorf("floating point instruction 0x%02x", code)
+		}
+		current.opcode = executor.OpcodeI32Load + int(code-0x28)
+		current.accessesMemory = true
+		_, err = reader.ReadU32()
+		if err == nil {
+			current.value, err = compiler.readIndex()
+		}
+	case code == 0x3F || code == 0x40:
+		current.opcode = executor.OpcodeMemorySize + int(code-0x3F)
+		current.accessesMemory = true
+		err = expectByte(reader, 0x00, "memory index")
+	case code == opcodeI32Const:
+		var value int64
+		value, err = reader.ReadS64()
+		current.opcode = executor.OpcodeI32Const
+		current.value = uint64(uint32(value))
+	case code == opcodeI64Const:
+		var value int64
+		value, err = reader.ReadS64()
+		current.opcode = executor.OpcodeI64Const
+		current.value = uint64(value)
+	case code >= 0x45 && code <= 0xC4:
+		if isFloatNumericOpcode(code) {
+			return current, fmt.Errorf("floating point instruction 0x%02x", code)
+		}
+		current.opcode = executor.OpcodeI32Eqz + int(code-0x45)
+	case code == opcodeMiscPrefix:
+		return compiler.readMiscInstruction()
+	default:
+		return current, fmt.Errorf("unsupported instruction 0x%02x", code)
+	}
+
+	return current, err
+}
+
+func (compiler *functionCompiler) readMiscInstruction() (instruction, error) {
+	reader := compiler.reader
+	current := instruction{accessesMemory: true}
+
+	subOpcode, err := reader.ReadU32()
+	if err != nil {
+		return current, err
+	}
+
+	switch subOpcode {
+	case 8:
+		current.opcode = executor.OpcodeMemoryInit
+		current.value, err = compiler.readIndex()
+		if err == nil {
+			err = expectByte(reader, 0x00, "memory index")
+		}
+	case 9:
+		current.opcode = executor.OpcodeDataDrop
+		current.accessesMemory = false
+		current.value, err = compiler.readIndex()
+	case 10:
+		current.opcode = executor.OpcodeMemoryCopy
+		err = expectByte(reader, 0x00, "memory index")
+		if err == nil {
+			err = expectByte(reader, 0x00, "memory index")
+		}
+	case 11:
+		current.opcode = executor.OpcodeMemoryFill
+		err = expectByte(reader, 0x00, "memory index")
+	default:
+		if subOpcode <= 7 {
+			return current, fmt.Errorf("floating point instruction 0xFC %d", subOpcode)
+		}
+		return current, fmt.Errorf("unsupported instruction 0xFC %d", subOpcode)
+	}
+
+	return current, err
+}
+
+func (compiler *functionCompiler) readIndex() (uint64, error) {
+	index, err := compiler.reader.ReadU32()
+	return uint64(index), err
+}
+
+// readBlockType returns the number of parameters and results of a block,
+// whose type is either empty, a single value type or a type index
+func (compiler *functionCompiler) readBlockType() (uint32, uint32, error) {
+	reader := compiler.reader
+	next, err := reader.PeekByte()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch valueType(next) {
+	case 0x40:
+		_, err = reader.ReadByte()
+		return 0, 0, err
+	case valueTypeI32, valueTypeI64, valueTypeF32, valueTypeF64:
+		_, err = reader.ReadByte()
+		return 0, 1, err
+	}
+
+	typeIndex, err := reader.ReadS64()
+	if err != nil {
+		return 0, 0, err
+	}
+	if typeIndex < 0 || typeIndex >= int64(len(compiler.module.types)) {
+		return 0, 0, fmt.Errorf("invalid block type %d", typeIndex)
+	}
+
+	blockType := compiler.module.types[typeIndex]
+	return uint32(len(blockType.params)), uint32(len(blockType.results)), nil
+}
+
+// expectByte reads a byte which the interpreter supports with a single
+// value, such as the index of the memory
+func expectByte(reader *wasmparser.Reader, expected byte, description string) error {
+	value, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	if value != expected {
+		return fmt.Errorf("unsupported %s 0x%02x", description, value)
+	}
+
+	return nil
+}
+
+func isFloatMemoryOpcode(code byte) bool {
+	return code == 0x2A || code == 0x2B || code == 0x38 || code == 0x39
+}
+
+// isFloatNumericOpcode tells the floating point comparisons, arithmetic,
+// conversions and reinterpretations apart from the integer ones
+func isFloatNumericOpcode(code byte) bool {
+	switch {
+	case code >= 0x5B && code <= 0x66:
+		return true
+	case code >= 0x8B && code <= 0xA6:
+		return true
+	case code >= 0xA8 && code <= 0xAB:
+		return true
+	case code >= 0xAE && code <= 0xBF:
+		return true
+	}
+
+	return false
+}
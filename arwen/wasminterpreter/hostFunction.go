@@ -0,0 +1,82 @@
+package wasminterpreter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+// hostFunction is an imported function, implemented in Go like the EEI
+// functions: its first argument is the instance context
+type hostFunction struct {
+	implementation reflect.Value
+	params         []valueType
+	results        []valueType
+}
+
+func resolveHostFunctions(module *module, imports *executor.Imports) ([]*hostFunction, error) {
+	hostFunctions := make([]*hostFunction, 0, len(module.imports))
+	for _, imported := range module.imports {
+		if imports == nil {
+			return nil, fmt.Errorf("unknown import %s.%s", imported.module, imported.name)
+		}
+
+		implementation, ok := imports.Implementation(imported.module, imported.name)
+		if !ok {
+			return nil, fmt.Errorf("unknown import %s.%s", imported.module, imported.name)
+		}
+
+		host, err := newHostFunction(implementation, module.types[imported.typeIndex])
+		if err != nil {
+			return nil, fmt.Errorf("import %s.%s: %w", imported.module, imported.name, err)
+		}
+
+		hostFunctions = append(hostFunctions, host)
+	}
+
+	return hostFunctions, nil
+}
+
+func newHostFunction(implementation interface{}, signature *functionType) (*hostFunction, error) {
+	implementationValue := reflect.ValueOf(implementation)
+	implementationType := implementationValue.Type()
+	if implementationType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("implementation is not a function")
+	}
+
+	if implementationType.NumIn() != len(signature.params)+1 || implementationType.NumOut() != len(signature.results) {
+		return nil, fmt.Errorf("implementation does not match the signature")
+	}
+	if implementationType.In(0).Kind() != reflect.UnsafePointer {
+		return nil, fmt.Errorf("implementation does not take the instance context")
+	}
+
+	for i, param := range signature.params {
+		if !isKindOfValueType(implementationType.In(i+1).Kind(), param) {
+			return nil, fmt.Errorf("implementation does not match the signature")
+		}
+	}
+	for i, result := range signature.results {
+		if !isKindOfValueType(implementationType.Out(i).Kind(), result) {
+			return nil, fmt.Errorf("implementation does not match the signature")
+		}
+	}
+
+	return &hostFunction{
+		implementation: implementationValue,
+		params:         signature.params,
+		results:        signature.results,
+	}, nil
+}
+
+func isKindOfValueType(kind reflect.Kind, valueType valueType) bool {
+	switch valueType {
+	case valueTypeI32:
+		return kind == reflect.Int32
+	case valueTypeI64:
+		return kind == reflect.Int64
+	}
+
+	return false
+}
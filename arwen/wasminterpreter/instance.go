@@ -0,0 +1,364 @@
+package wasminterpreter
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+// cacheHeader prefixes the "compiled code" of the interpreted instances,
+// which is their bytecode, telling it apart from the code cached by Wasmer
+var cacheHeader = []byte("\x00arwen-interpreter\x01")
+
+// instance is a WASM instance executed by the interpreter, implementing
+// executor.InstanceHandler; the imported functions receive a context pointer
+// created by the executor package, through which they reach the context
+// data and the memory of the instance
+type instance struct {
+	bytecode       []byte
+	module         *module
+	machine        *machine
+	memory         *memory
+	hostFunctions  []*hostFunction
+	exports        executor.ExportsMap
+	signatures     executor.ExportSignaturesMap
+	data           uintptr
+	contextPointer unsafe.Pointer
+	pointsUsed     uint64
+	gasLimit       uint64
+	breakpoint     uint64
+}
+
+func newInstance(
+	bytecode []byte,
+	imports *executor.Imports,
+	opcodeCosts *[executor.OPCODE_COUNT]uint32,
+	options executor.CompilationOptions,
+	profiler *Profiler,
+) (*instance, error) {
+	module, err := loadModule(bytecode)
+	if err != nil {
+		return nil, err
+	}
+
+	hostFunctions, err := resolveHostFunctions(module, imports)
+	if err != nil {
+		return nil, err
+	}
+
+	newInstance := &instance{
+		bytecode:      bytecode,
+		module:        module,
+		hostFunctions: hostFunctions,
+		gasLimit:      options.GasLimit,
+	}
+	newInstance.machine = &machine{
+		instance:        newInstance,
+		module:          module,
+		metering:        options.Metering,
+		breakpoints:     options.RuntimeBreakpoints,
+		unmeteredLocals: options.UnmeteredLocals,
+		opcodeCosts:     opcodeCosts,
+	}
+	if profiler != nil {
+		newInstance.machine.profiler = profiler
+		newInstance.machine.functionNames = module.functionNames()
+	}
+
+	err = newInstance.initialize()
+	if err != nil {
+		return nil, err
+	}
+
+	newInstance.createExports()
+	newInstance.contextPointer = executor.NewInstanceContextPointer(newInstance)
+
+	if module.hasStart {
+		_, err = newInstance.machine.invoke(module.startFunction, nil)
+		if err != nil {
+			newInstance.Clean()
+			return nil, fmt.Errorf("start function: %w", err)
+		}
+	}
+
+	return newInstance, nil
+}
+
+// initialize sets up the memory, the globals and the table, then applies
+// the active data and element segments
+func (instance *instance) initialize() error {
+	module := instance.module
+	machine := instance.machine
+
+	if module.memory != nil {
+		instance.memory = newMemory(module.memory)
+		machine.memory = instance.memory
+	}
+
+	machine.globals = make([]uint64, len(module.globals))
+	for i, global := range module.globals {
+		machine.globals[i] = global.initialValue
+	}
+
+	if module.table != nil {
+		machine.table = make([]uint32, module.table.Min)
+		for i := range machine.table {
+			machine.table[i] = nullFunction
+		}
+	}
+
+	for i, segment := range module.elements {
+		if !segment.active {
+			continue
+		}
+
+		offset := uint64(segment.offset)
+		if offset+uint64(len(segment.functions)) > uint64(len(machine.table)) {
+			return fmt.Errorf("element segment %d does not fit in the table", i)
+		}
+		copy(machine.table[offset:], segment.functions)
+	}
+
+	machine.droppedData = make([]bool, len(module.data))
+	for i, segment := range module.data {
+		if !segment.active {
+			continue
+		}
+
+		offset := uint64(segment.offset)
+		if offset+uint64(len(segment.data)) > uint64(len(instance.memory.data)) {
+			return fmt.Errorf("data segment %d does not fit in the memory", i)
+		}
+		copy(instance.memory.data[offset:], segment.data)
+		machine.droppedData[i] = true
+	}
+
+	return nil
+}
+
+func (instance *instance) createExports() {
+	instance.exports = make(executor.ExportsMap)
+	instance.signatures = make(executor.ExportSignaturesMap)
+
+	for _, name := range instance.module.exportNames {
+		exported := instance.module.exports[name]
+		if exported.kind != wasmparser.ExternalFunction {
+			continue
+		}
+
+		signature := instance.module.typeOfFunction(exported.index)
+		instance.exports[name] = instance.createExportedFunction(name, exported.index, signature)
+		instance.signatures[name] = &executor.ExportedFunctionSignature{
+			InputArity:  len(signature.params),
+			OutputArity: len(signature.results),
+		}
+	}
+}
+
+func (instance *instance) createExportedFunction(
+	name string,
+	functionIndex uint32,
+	signature *functionType,
+) executor.ExportedFunctionCallback {
+	return func(arguments ...interface{}) (executor.Value, error) {
+		if len(arguments) != len(signature.params) {
+			return executor.Void(), executor.NewExportedFunctionError(name, fmt.Sprintf("Expected %d argument(s) when calling the `%%s` exported function, given %d.", len(signature.params), len(arguments)))
+		}
+
+		wasmArguments := make([]uint64, len(arguments))
+		for i, argument := range arguments {
+			value, ok := convertArgument(argument, signature.params[i])
+			if !ok {
+				return executor.Void(), executor.NewExportedFunctionError(name, fmt.Sprintf("Argument #%d of the `%%s` exported function cannot be converted to %s.", i+1, signature.params[i].String()))
+			}
+			wasmArguments[i] = value
+		}
+
+		results, err := instance.machine.invoke(functionIndex, wasmArguments)
+		if err != nil {
+			return executor.Void(), fmt.Errorf("Failed to call the `%s` exported function: %w", name, err)
+		}
+
+		if len(signature.results) == 0 {
+			return executor.Void(), nil
+		}
+		if signature.results[0] == valueTypeI32 {
+			return executor.I32(int32(results[0])), nil
+		}
+
+		return executor.I64(int64(results[0])), nil
+	}
+}
+
+func (valueType valueType) String() string {
+	switch valueType {
+	case valueTypeI32:
+		return "i32"
+	case valueTypeI64:
+		return "i64"
+	case valueTypeF32:
+		return "f32"
+	case valueTypeF64:
+		return "f64"
+	}
+
+	return fmt.Sprintf("unknown(0x%02x)", byte(valueType))
+}
+
+// convertArgument converts an argument of an exported function, accepting
+// the same Go types as the Wasmer instances
+func convertArgument(argument interface{}, parameterType valueType) (uint64, bool) {
+	var value int64
+	switch typed := argument.(type) {
+	case int8:
+		value = int64(typed)
+	case uint8:
+		value = int64(typed)
+	case int16:
+		value = int64(typed)
+	case uint16:
+		value = int64(typed)
+	case int32:
+		value = int64(typed)
+	case uint32:
+		value = int64(typed)
+	case int64:
+		value = typed
+	case int:
+		value = int64(typed)
+	case uint:
+		value = int64(typed)
+	case executor.Value:
+		if parameterType == valueTypeI32 && typed.GetType() == executor.TypeI32 {
+			return uint64(uint32(typed.ToI32())), true
+		}
+		if parameterType == valueTypeI64 && typed.GetType() == executor.TypeI64 {
+			return uint64(typed.ToI64()), true
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+
+	switch parameterType {
+	case valueTypeI32:
+		return uint64(uint32(value)), true
+	case valueTypeI64:
+		return uint64(value), true
+	}
+
+	return 0, false
+}
+
+// HasMemory returns true if the instance exports its memory
+func (instance *instance) HasMemory() bool {
+	return instance.memory != nil && instance.module.hasExportedMemory()
+}
+
+// SetContextData sets the data passed to the imported functions
+func (instance *instance) SetContextData(data uintptr) {
+	instance.data = data
+}
+
+// GetContextDataPointer returns the pointer to the data passed to the
+// imported functions, like wasmer.InstanceContext.Data()
+func (instance *instance) GetContextDataPointer() unsafe.Pointer {
+	return unsafe.Pointer(&instance.data)
+}
+
+// GetPointsUsed returns the points consumed by the metering
+func (instance *instance) GetPointsUsed() uint64 {
+	return instance.pointsUsed
+}
+
+// SetPointsUsed sets the points consumed by the metering
+func (instance *instance) SetPointsUsed(points uint64) {
+	instance.pointsUsed = points
+}
+
+// SetGasLimit sets the points above which the metering stops the execution
+func (instance *instance) SetGasLimit(gasLimit uint64) {
+	instance.gasLimit = gasLimit
+}
+
+// SetBreakpointValue sets the breakpoint value; it may be called while the
+// instance is running, from another goroutine
+func (instance *instance) SetBreakpointValue(value uint64) {
+	atomic.StoreUint64(&instance.breakpoint, value)
+}
+
+// GetBreakpointValue returns the breakpoint value
+func (instance *instance) GetBreakpointValue() uint64 {
+	return atomic.LoadUint64(&instance.breakpoint)
+}
+
+// Cache returns the code from which the instance can be created again by
+// NewInstanceFromCompiledCodeWithOptions; the interpreter compiles nothing
+// ahead of time, therefore this code is the bytecode itself
+func (instance *instance) Cache() ([]byte, error) {
+	compiledCode := make([]byte, 0, len(cacheHeader)+len(instance.bytecode))
+	compiledCode = append(compiledCode, cacheHeader...)
+	compiledCode = append(compiledCode, instance.bytecode...)
+	return compiledCode, nil
+}
+
+// Clean releases the memory of the instance and its context pointer
+func (instance *instance) Clean() {
+	instance.contextPointer = nil
+	if instance.memory != nil {
+		instance.memory.Destroy()
+	}
+}
+
+// GetExports returns the exported functions
+func (instance *instance) GetExports() executor.ExportsMap {
+	return instance.exports
+}
+
+// GetSignature returns the signature of the given exported function
+func (instance *instance) GetSignature(functionName string) (*executor.ExportedFunctionSignature, bool) {
+	signature, ok := instance.signatures[functionName]
+	return signature, ok
+}
+
+// GetData returns the data passed to the imported functions
+func (instance *instance) GetData() uintptr {
+	return instance.data
+}
+
+// GetInstanceCtxMemory returns the memory seen by the imported functions
+func (instance *instance) GetInstanceCtxMemory() executor.MemoryHandler {
+	if instance.memory == nil {
+		return nil
+	}
+
+	return instance.memory
+}
+
+// GetMemory returns the exported memory of the instance
+func (instance *instance) GetMemory() executor.MemoryHandler {
+	if !instance.HasMemory() {
+		return nil
+	}
+
+	return instance.memory
+}
+
+// IsFunctionImported returns true if the instance imports the function
+func (instance *instance) IsFunctionImported(name string) bool {
+	for _, imported := range instance.module.imports {
+		if imported.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isInterpreterCache(compiledCode []byte) bool {
+	return bytes.HasPrefix(compiledCode, cacheHeader)
+}
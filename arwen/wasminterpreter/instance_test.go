@@ -0,0 +1,299 @@
+package wasminterpreter
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+	"github.com/stretchr/testify/require"
+)
+
+// testModule exports the functions add(i32, i32) i32, sum(i32) i32, which
+// adds 1..n in a loop, load(i32) i32, which reads the memory, and fail(),
+// which is unreachable; its memory holds the i32 42 at offset 8
+// breakpointSignalError has the value of arwen.BreakpointSignalError
+const breakpointSignalError = 3
+
+var testModule = assembleModule(
+	section(1, 0x03,
+		0x60, 0x02, 0x7F, 0x7F, 0x01, 0x7F,
+		0x60, 0x01, 0x7F, 0x01, 0x7F,
+		0x60, 0x00, 0x00,
+	),
+	section(3, 0x04, 0x00, 0x01, 0x01, 0x02),
+	section(5, 0x01, 0x00, 0x01),
+	section(7, concat([]byte{0x05},
+		exportEntry("add", 0x00, 0),
+		exportEntry("sum", 0x00, 1),
+		exportEntry("load", 0x00, 2),
+		exportEntry("fail", 0x00, 3),
+		exportEntry("memory", 0x02, 0),
+	)...),
+	section(10, concat([]byte{0x04},
+		functionBody(0x00, 0x20, 0x00, 0x20, 0x01, 0x6A, 0x0B),
+		functionBody(0x01, 0x01, 0x7F,
+			0x02, 0x40,
+			0x03, 0x40,
+			0x20, 0x00, 0x45, 0x0D, 0x01,
+			0x20, 0x01, 0x20, 0x00, 0x6A, 0x21, 0x01,
+			0x20, 0x00, 0x41, 0x01, 0x6B, 0x21, 0x00,
+			0x0C, 0x00,
+			0x0B,
+			0x0B,
+			0x20, 0x01, 0x0B,
+		),
+		functionBody(0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x0B),
+		functionBody(0x00, 0x00, 0x0B),
+	)...),
+	section(11, 0x01, 0x00, 0x41, 0x08, 0x0B, 0x04, 0x2A, 0x00, 0x00, 0x00),
+)
+
+// hostModule imports env.double(i32) i32 and exports it as callDouble,
+// together with its memory
+var hostModule = assembleModule(
+	section(1, 0x01, 0x60, 0x01, 0x7F, 0x01, 0x7F),
+	section(2, concat([]byte{0x01}, name("env"), name("double"), []byte{0x00, 0x00})...),
+	section(3, 0x01, 0x00),
+	section(5, 0x01, 0x00, 0x01),
+	section(7, concat([]byte{0x02},
+		exportEntry("callDouble", 0x00, 1),
+		exportEntry("memory", 0x02, 0),
+	)...),
+	section(10, concat([]byte{0x01},
+		functionBody(0x00, 0x20, 0x00, 0x10, 0x00, 0x0B),
+	)...),
+)
+
+// floatModule drops an f32 constant
+var floatModule = assembleModule(
+	section(1, 0x01, 0x60, 0x00, 0x00),
+	section(3, 0x01, 0x00),
+	section(10, concat([]byte{0x01},
+		functionBody(0x00, 0x43, 0x00, 0x00, 0x00, 0x00, 0x1A, 0x0B),
+	)...),
+)
+
+func TestInstance_Arithmetic(t *testing.T) {
+	instance := newTestInstance(t, testModule, defaultTestOptions())
+	defer instance.Clean()
+
+	result, err := instance.GetExports()["add"](2, 3)
+	require.Nil(t, err)
+	require.Equal(t, int32(5), result.ToI32())
+
+	result, err = instance.GetExports()["add"](int32(-7), uint32(4))
+	require.Nil(t, err)
+	require.Equal(t, int32(-3), result.ToI32())
+
+	_, err = instance.GetExports()["add"](1)
+	require.NotNil(t, err)
+
+	signature, ok := instance.GetSignature("add")
+	require.True(t, ok)
+	require.Equal(t, 2, signature.InputArity)
+	require.Equal(t, 1, signature.OutputArity)
+}
+
+func TestInstance_LoopMetering(t *testing.T) {
+	instance := newTestInstance(t, testModule, defaultTestOptions())
+	defer instance.Clean()
+
+	result, err := instance.GetExports()["sum"](10)
+	require.Nil(t, err)
+	require.Equal(t, int32(55), result.ToI32())
+
+	pointsForTen := instance.GetPointsUsed()
+	require.True(t, pointsForTen > 0)
+
+	instance.SetPointsUsed(0)
+	_, err = instance.GetExports()["sum"](20)
+	require.Nil(t, err)
+	require.True(t, instance.GetPointsUsed() > pointsForTen)
+
+	instance.SetPointsUsed(0)
+	instance.SetGasLimit(pointsForTen)
+	_, err = instance.GetExports()["sum"](20)
+	require.NotNil(t, err)
+	require.Equal(t, uint64(executor.BreakpointOutOfGas), instance.GetBreakpointValue())
+}
+
+func TestInstance_Breakpoint(t *testing.T) {
+	instance := newTestInstance(t, testModule, defaultTestOptions())
+	defer instance.Clean()
+
+	instance.SetBreakpointValue(breakpointSignalError)
+	_, err := instance.GetExports()["sum"](10)
+	require.NotNil(t, err)
+
+	instance.SetBreakpointValue(uint64(executor.BreakpointNone))
+	result, err := instance.GetExports()["sum"](10)
+	require.Nil(t, err)
+	require.Equal(t, int32(55), result.ToI32())
+}
+
+func TestInstance_MemoryAndTraps(t *testing.T) {
+	instance := newTestInstance(t, testModule, defaultTestOptions())
+	defer instance.Clean()
+
+	require.True(t, instance.HasMemory())
+	require.Equal(t, uint32(wasmPageSize), instance.GetMemory().Length())
+
+	result, err := instance.GetExports()["load"](8)
+	require.Nil(t, err)
+	require.Equal(t, int32(42), result.ToI32())
+
+	instance.GetMemory().Data()[100] = 7
+	result, err = instance.GetExports()["load"](100)
+	require.Nil(t, err)
+	require.Equal(t, int32(7), result.ToI32())
+
+	_, err = instance.GetExports()["load"](wasmPageSize - 2)
+	require.NotNil(t, err)
+
+	_, err = instance.GetExports()["fail"]()
+	require.NotNil(t, err)
+
+	err = instance.GetMemory().Grow(2)
+	require.Nil(t, err)
+	require.Equal(t, uint32(3*wasmPageSize), instance.GetMemory().Length())
+
+	result, err = instance.GetExports()["load"](8)
+	require.Nil(t, err)
+	require.Equal(t, int32(42), result.ToI32())
+}
+
+func TestInstance_HostFunction(t *testing.T) {
+	var seenData uintptr
+	var seenMemoryLength uint32
+	double := func(context unsafe.Pointer, value int32) int32 {
+		require.True(t, executor.IsInstanceContextPointer(context))
+		provider := executor.IntoInstanceContextProvider(context)
+		seenData = *(*uintptr)(provider.GetContextDataPointer())
+		seenMemoryLength = provider.GetInstanceCtxMemory().Length()
+		return value * 2
+	}
+
+	imports, err := executor.NewImports().Namespace("env").Append("double", double, nil)
+	require.Nil(t, err)
+
+	builder := NewInstanceBuilderWithImports(imports, newTestOpcodeCosts())
+	instance, err := builder.NewInstanceWithOptions(hostModule, defaultTestOptions())
+	require.Nil(t, err)
+	defer instance.Clean()
+
+	require.True(t, instance.IsFunctionImported("double"))
+	require.False(t, instance.IsFunctionImported("triple"))
+
+	instance.SetContextData(1234)
+	result, err := instance.GetExports()["callDouble"](21)
+	require.Nil(t, err)
+	require.Equal(t, int32(42), result.ToI32())
+	require.Equal(t, uintptr(1234), seenData)
+	require.Equal(t, uint32(wasmPageSize), seenMemoryLength)
+
+	builder = NewInstanceBuilderWithImports(executor.NewImports(), newTestOpcodeCosts())
+	_, err = builder.NewInstanceWithOptions(hostModule, defaultTestOptions())
+	require.True(t, errors.Is(err, executor.ErrFailedInstantiation))
+}
+
+func TestInstanceBuilder_Cache(t *testing.T) {
+	builder := NewInstanceBuilderWithImports(executor.NewImports(), newTestOpcodeCosts())
+	instance, err := builder.NewInstanceWithOptions(testModule, defaultTestOptions())
+	require.Nil(t, err)
+	defer instance.Clean()
+
+	compiledCode, err := instance.Cache()
+	require.Nil(t, err)
+
+	cachedInstance, err := builder.NewInstanceFromCompiledCodeWithOptions(compiledCode, defaultTestOptions())
+	require.Nil(t, err)
+	defer cachedInstance.Clean()
+
+	result, err := cachedInstance.GetExports()["add"](2, 3)
+	require.Nil(t, err)
+	require.Equal(t, int32(5), result.ToI32())
+
+	_, err = builder.NewInstanceFromCompiledCodeWithOptions(testModule, defaultTestOptions())
+	require.True(t, errors.Is(err, executor.ErrInvalidBytecode))
+}
+
+func TestInstanceBuilder_InvalidModules(t *testing.T) {
+	builder := NewInstanceBuilderWithImports(executor.NewImports(), newTestOpcodeCosts())
+
+	_, err := builder.NewInstanceWithOptions(nil, defaultTestOptions())
+	require.True(t, errors.Is(err, executor.ErrInvalidBytecode))
+
+	_, err = builder.NewInstanceWithOptions([]byte("not a module"), defaultTestOptions())
+	require.True(t, errors.Is(err, executor.ErrFailedInstantiation))
+
+	_, err = builder.NewInstanceWithOptions(testModule[:len(testModule)-3], defaultTestOptions())
+	require.True(t, errors.Is(err, executor.ErrFailedInstantiation))
+
+	_, err = builder.NewInstanceWithOptions(floatModule, defaultTestOptions())
+	require.True(t, errors.Is(err, executor.ErrFailedInstantiation))
+}
+
+func newTestInstance(t *testing.T, code []byte, options executor.CompilationOptions) executor.InstanceHandler {
+	builder := NewInstanceBuilderWithImports(executor.NewImports(), newTestOpcodeCosts())
+	instance, err := builder.NewInstanceWithOptions(code, options)
+	require.Nil(t, err)
+	return instance
+}
+
+func defaultTestOptions() executor.CompilationOptions {
+	return executor.CompilationOptions{
+		GasLimit:           1000000,
+		Metering:           true,
+		RuntimeBreakpoints: true,
+	}
+}
+
+func newTestOpcodeCosts() *[executor.OPCODE_COUNT]uint32 {
+	opcodeCosts := &[executor.OPCODE_COUNT]uint32{}
+	for i := range opcodeCosts {
+		opcodeCosts[i] = 1
+	}
+	return opcodeCosts
+}
+
+func assembleModule(sections ...[]byte) []byte {
+	header := []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
+	return concat(append([][]byte{header}, sections...)...)
+}
+
+func section(id byte, contents ...byte) []byte {
+	return concat([]byte{id}, encodeU32(uint32(len(contents))), contents)
+}
+
+func functionBody(contents ...byte) []byte {
+	return concat(encodeU32(uint32(len(contents))), contents)
+}
+
+func exportEntry(exportName string, kind byte, index byte) []byte {
+	return concat(name(exportName), []byte{kind, index})
+}
+
+func name(value string) []byte {
+	return concat(encodeU32(uint32(len(value))), []byte(value))
+}
+
+func encodeU32(value uint32) []byte {
+	encoded := make([]byte, 0, 5)
+	for {
+		next := byte(value & 0x7F)
+		value >>= 7
+		if value == 0 {
+			return append(encoded, next)
+		}
+		encoded = append(encoded, next|0x80)
+	}
+}
+
+func concat(parts ...[]byte) []byte {
+	result := make([]byte, 0)
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
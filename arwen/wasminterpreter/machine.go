@@ -0,0 +1,744 @@
+package wasminterpreter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+// maxCallDepth bounds the nesting of the calls between the functions of a
+// contract, standing for the stack limit of Wasmer
+const maxCallDepth = 10000
+
+// nullFunction marks the uninitialized elements of the table
+const nullFunction = math.MaxUint32
+
+// trap stops the execution of an instance, like a trap raised by the code
+// compiled by Wasmer
+type trap struct {
+	message string
+}
+
+func (t *trap) Error() string {
+	return t.message
+}
+
+func trapf(format string, arguments ...interface{}) {
+	panic(&trap{message: fmt.Sprintf(format, arguments...)})
+}
+
+// label is the target of the branches out of a block, or back to the
+// beginning of a loop
+type label struct {
+	height   int
+	arity    int
+	position int
+	isLoop   bool
+}
+
+// machine executes the instructions of an instance, keeping the locals
+// and the operands of all the active calls on the same stack
+type machine struct {
+	instance    *instance
+	module      *module
+	memory      *memory
+	globals     []uint64
+	table       []uint32
+	droppedData []bool
+	stack       []uint64
+	labels      []label
+	depth       int
+	running     bool
+
+	metering        bool
+	breakpoints     bool
+	unmeteredLocals uint64
+	opcodeCosts     *[executor.OPCODE_COUNT]uint32
+
+	profiler      *Profiler
+	functionNames []string
+	callerStack   string
+	profileRoots  map[uint32]*profileFrame
+	profileFrames []*profileFrame
+	activeFrames  []*profileFrame
+}
+
+// invoke calls the function with the given arguments and returns its
+// results, turning the traps into errors
+func (machine *machine) invoke(functionIndex uint32, arguments []uint64) (results []uint64, err error) {
+	if machine.running {
+		return nil, fmt.Errorf("reentrant call into the instance")
+	}
+
+	machine.running = true
+	machine.stack = append(machine.stack[:0], arguments...)
+	machine.labels = machine.labels[:0]
+	machine.depth = 0
+	if machine.profiler != nil {
+		machine.startProfiling()
+	}
+
+	defer func() {
+		machine.running = false
+		if machine.profiler != nil {
+			machine.stopProfiling()
+		}
+
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		switch typed := recovered.(type) {
+		case *trap:
+			err = typed
+		case runtime.Error:
+			err = &trap{message: typed.Error()}
+		default:
+			panic(recovered)
+		}
+	}()
+
+	machine.call(functionIndex)
+
+	results = make([]uint64, len(machine.stack))
+	copy(results, machine.stack)
+	return results, nil
+}
+
+func (machine *machine) push(value uint64) {
+	machine.stack = append(machine.stack, value)
+}
+
+func (machine *machine) pushBool(value bool) {
+	if value {
+		machine.stack = append(machine.stack, 1)
+		return
+	}
+	machine.stack = append(machine.stack, 0)
+}
+
+func (machine *machine) pop() uint64 {
+	last := len(machine.stack) - 1
+	value := machine.stack[last]
+	machine.stack = machine.stack[:last]
+	return value
+}
+
+func (machine *machine) popI32() uint32 {
+	return uint32(machine.pop())
+}
+
+// charge adds the cost to the points used by the instance, stopping the
+// execution with BreakpointOutOfGas when they exceed the gas limit, as the
+// metering of Wasmer does
+func (machine *machine) charge(cost uint64) {
+	instance := machine.instance
+	instance.pointsUsed += cost
+	if instance.pointsUsed > instance.gasLimit {
+		instance.SetBreakpointValue(executor.BreakpointOutOfGas)
+		trapf("out of gas")
+	}
+}
+
+func (machine *machine) checkBreakpoint() {
+	if machine.breakpoints && atomic.LoadUint64(&machine.instance.breakpoint) != executor.BreakpointNone {
+		trapf("breakpoint %d reached", atomic.LoadUint64(&machine.instance.breakpoint))
+	}
+}
+
+func (machine *machine) call(functionIndex uint32) {
+	importsCount := uint32(len(machine.module.imports))
+	if functionIndex < importsCount {
+		machine.callHostFunction(functionIndex)
+		return
+	}
+
+	if machine.depth >= maxCallDepth {
+		trapf("call stack exhausted")
+	}
+	machine.depth++
+
+	function := machine.module.functions[functionIndex-importsCount]
+	signature := machine.module.types[function.typeIndex]
+	localsBase := len(machine.stack) - len(signature.params)
+	if localsBase < 0 {
+		trapf("operand stack underflow")
+	}
+
+	localsCount := uint64(function.localsCount)
+	if machine.profiler != nil {
+		frame := machine.pushFrame(functionIndex)
+		if localsCount > machine.unmeteredLocals {
+			frame.record(executor.OpcodeLocalAllocate, machine.opcodeCosts[executor.OpcodeLocalAllocate], localsCount-machine.unmeteredLocals)
+		}
+	}
+	if machine.metering && localsCount > machine.unmeteredLocals {
+		machine.charge((localsCount - machine.unmeteredLocals) * uint64(machine.opcodeCosts[executor.OpcodeLocalAllocate]))
+	}
+	for i := uint64(0); i < localsCount; i++ {
+		machine.stack = append(machine.stack, 0)
+	}
+
+	labelsBase := len(machine.labels)
+	machine.labels = append(machine.labels, label{
+		height:   len(machine.stack),
+		arity:    len(signature.results),
+		position: len(function.instructions),
+	})
+
+	machine.execute(function.instructions, localsBase)
+
+	resultsCount := len(signature.results)
+	copy(machine.stack[localsBase:], machine.stack[len(machine.stack)-resultsCount:])
+	machine.stack = machine.stack[:localsBase+resultsCount]
+	machine.labels = machine.labels[:labelsBase]
+	machine.depth--
+	if machine.profiler != nil {
+		machine.popFrame()
+	}
+}
+
+func (machine *machine) callHostFunction(functionIndex uint32) {
+	host := machine.instance.hostFunctions[functionIndex]
+	base := len(machine.stack) - len(host.params)
+	if base < 0 {
+		trapf("operand stack underflow")
+	}
+
+	arguments := make([]reflect.Value, len(host.params)+1)
+	arguments[0] = reflect.ValueOf(machine.instance.contextPointer)
+	for i, param := range host.params {
+		value := machine.stack[base+i]
+		if param == valueTypeI32 {
+			arguments[i+1] = reflect.ValueOf(int32(uint32(value)))
+		} else {
+			arguments[i+1] = reflect.ValueOf(int64(value))
+		}
+	}
+	machine.stack = machine.stack[:base]
+
+	if machine.profiler != nil {
+		machine.pushFrame(functionIndex)
+	}
+	outputs := host.implementation.Call(arguments)
+	if machine.profiler != nil {
+		machine.popFrame()
+	}
+	if len(host.results) == 1 {
+		if host.results[0] == valueTypeI32 {
+			machine.push(uint64(uint32(outputs[0].Int())))
+		} else {
+			machine.push(uint64(outputs[0].Int()))
+		}
+	}
+
+	machine.checkBreakpoint()
+}
+
+func (machine *machine) callIndirect(typeIndex uint32) {
+	elementIndex := machine.popI32()
+	if elementIndex >= uint32(len(machine.table)) {
+		trapf("undefined element %d", elementIndex)
+	}
+
+	functionIndex := machine.table[elementIndex]
+	if functionIndex == nullFunction {
+		trapf("uninitialized element %d", elementIndex)
+	}
+
+	expected := machine.module.types[typeIndex]
+	actual := machine.module.typeOfFunction(functionIndex)
+	if !sameValueTypes(expected.params, actual.params) || !sameValueTypes(expected.results, actual.results) {
+		trapf("indirect call signature mismatch")
+	}
+
+	machine.call(functionIndex)
+}
+
+func sameValueTypes(first []valueType, second []valueType) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// branch unwinds the operand stack and the labels to the target of the
+// branch, keeping the values it carries, and returns the position at
+// which the execution continues
+func (machine *machine) branch(depth uint32) int {
+	labelIndex := len(machine.labels) - 1 - int(depth)
+	target := machine.labels[labelIndex]
+
+	if target.arity > 0 {
+		copy(machine.stack[target.height:], machine.stack[len(machine.stack)-target.arity:])
+	}
+	machine.stack = machine.stack[:target.height+target.arity]
+
+	if target.isLoop {
+		machine.labels = machine.labels[:labelIndex+1]
+		machine.checkBreakpoint()
+	} else {
+		machine.labels = machine.labels[:labelIndex]
+	}
+
+	return target.position
+}
+
+// effectiveAddress pops the base address and checks that the access of
+// the given size at the given offset is within the memory
+func (machine *machine) effectiveAddress(offset uint64, size uint64) uint64 {
+	address := uint64(machine.popI32()) + offset
+	if address+size > uint64(len(machine.memory.data)) {
+		trapf("out of bounds memory access")
+	}
+
+	return address
+}
+
+func (machine *machine) checkMemoryRange(start uint32, length uint32) {
+	if uint64(start)+uint64(length) > uint64(len(machine.memory.data)) {
+		trapf("out of bounds memory access")
+	}
+}
+
+func (machine *machine) execute(instructions []instruction, localsBase int) {
+	costs := machine.opcodeCosts
+	metering := machine.metering
+	frame := machine.currentFrame()
+	position := 0
+
+	for position < len(instructions) {
+		current := &instructions[position]
+		position++
+
+		if metering {
+			machine.charge(uint64(costs[current.opcode]))
+		}
+		if frame != nil {
+			frame.record(current.opcode, costs[current.opcode], 1)
+		}
+
+		switch current.opcode {
+		case executor.OpcodeUnreachable:
+			trapf("unreachable executed")
+		case executor.OpcodeNop:
+		case executor.OpcodeBlock:
+			machine.labels = append(machine.labels, label{
+				height:   len(machine.stack) - int(current.params),
+				arity:    int(current.results),
+				position: current.endPosition + 1,
+			})
+		case executor.OpcodeLoop:
+			machine.labels = append(machine.labels, label{
+				height:   len(machine.stack) - int(current.params),
+				arity:    int(current.params),
+				position: position,
+				isLoop:   true,
+			})
+		case executor.OpcodeIf:
+			condition := machine.popI32()
+			machine.labels = append(machine.labels, label{
+				height:   len(machine.stack) - int(current.params),
+				arity:    int(current.results),
+				position: current.endPosition + 1,
+			})
+			if condition == 0 {
+				if current.elsePosition != 0 {
+					position = current.elsePosition + 1
+				} else {
+					position = current.endPosition
+				}
+			}
+		case executor.OpcodeElse:
+			position = current.endPosition
+		case executor.OpcodeEnd:
+			machine.labels = machine.labels[:len(machine.labels)-1]
+		case executor.OpcodeBr:
+			position = machine.branch(uint32(current.value))
+		case executor.OpcodeBrIf:
+			if machine.popI32() != 0 {
+				position = machine.branch(uint32(current.value))
+			}
+		case executor.OpcodeBrTable:
+			index := machine.popI32()
+			defaultIndex := uint32(len(current.depths) - 1)
+			if index > defaultIndex {
+				index = defaultIndex
+			}
+			position = machine.branch(current.depths[index])
+		case executor.OpcodeReturn:
+			position = len(instructions)
+		case executor.OpcodeCall:
+			machine.call(uint32(current.value))
+		case executor.OpcodeCallIndirect:
+			machine.callIndirect(uint32(current.value))
+
+		case executor.OpcodeDrop:
+			machine.pop()
+		case executor.OpcodeSelect, executor.OpcodeTypedSelect:
+			condition := machine.popI32()
+			second := machine.pop()
+			first := machine.pop()
+			if condition != 0 {
+				machine.push(first)
+			} else {
+				machine.push(second)
+			}
+
+		case executor.OpcodeLocalGet:
+			machine.push(machine.stack[localsBase+int(current.value)])
+		case executor.OpcodeLocalSet:
+			machine.stack[localsBase+int(current.value)] = machine.pop()
+		case executor.OpcodeLocalTee:
+			machine.stack[localsBase+int(current.value)] = machine.stack[len(machine.stack)-1]
+		case executor.OpcodeGlobalGet:
+			machine.push(machine.globals[current.value])
+		case executor.OpcodeGlobalSet:
+			machine.globals[current.value] = machine.pop()
+
+		default:
+			machine.executeMemoryOrNumeric(current)
+		}
+	}
+}
+
+// executeMemoryOrNumeric executes the instructions which do not affect the
+// control flow nor the locals
+func (machine *machine) executeMemoryOrNumeric(current *instruction) {
+	switch current.opcode {
+	case executor.OpcodeI32Load:
+		address := machine.effectiveAddress(current.value, 4)
+		machine.push(uint64(binary.LittleEndian.Uint32(machine.memory.data[address:])))
+	case executor.OpcodeI64Load:
+		address := machine.effectiveAddress(current.value, 8)
+		machine.push(binary.LittleEndian.Uint64(machine.memory.data[address:]))
+	case executor.OpcodeI32Load8S:
+		address := machine.effectiveAddress(current.value, 1)
+		machine.push(uint64(uint32(int32(int8(machine.memory.data[address])))))
+	case executor.OpcodeI32Load8U, executor.OpcodeI64Load8U:
+		address := machine.effectiveAddress(current.value, 1)
+		machine.push(uint64(machine.memory.data[address]))
+	case executor.OpcodeI32Load16S:
+		address := machine.effectiveAddress(current.value, 2)
+		machine.push(uint64(uint32(int32(int16(binary.LittleEndian.Uint16(machine.memory.data[address:]))))))
+	case executor.OpcodeI32Load16U, executor.OpcodeI64Load16U:
+		address := machine.effectiveAddress(current.value, 2)
+		machine.push(uint64(binary.LittleEndian.Uint16(machine.memory.data[address:])))
+	case executor.OpcodeI64Load8S:
+		address := machine.effectiveAddress(current.value, 1)
+		machine.push(uint64(int64(int8(machine.memory.data[address]))))
+	case executor.OpcodeI64Load16S:
+		address := machine.effectiveAddress(current.value, 2)
+		machine.push(uint64(int64(int16(binary.LittleEndian.Uint16(machine.memory.data[address:])))))
+	case executor.OpcodeI64Load32S:
+		address := machine.effectiveAddress(current.value, 4)
+		machine.push(uint64(int64(int32(binary.LittleEndian.Uint32(machine.memory.data[address:])))))
+	case executor.OpcodeI64Load32U:
+		address := machine.effectiveAddress(current.value, 4)
+		machine.push(uint64(binary.LittleEndian.Uint32(machine.memory.data[address:])))
+
+	case executor.OpcodeI32Store, executor.OpcodeI64Store32:
+		value := machine.pop()
+		address := machine.effectiveAddress(current.value, 4)
+		binary.LittleEndian.PutUint32(machine.memory.data[address:], uint32(value))
+	case executor.OpcodeI64Store:
+		value := machine.pop()
+		address := machine.effectiveAddress(current.value, 8)
+		binary.LittleEndian.PutUint64(machine.memory.data[address:], value)
+	case executor.OpcodeI32Store8, executor.OpcodeI64Store8:
+		value := machine.pop()
+		address := machine.effectiveAddress(current.value, 1)
+		machine.memory.data[address] = byte(value)
+	case executor.OpcodeI32Store16, executor.OpcodeI64Store16:
+		value := machine.pop()
+		address := machine.effectiveAddress(current.value, 2)
+		binary.LittleEndian.PutUint16(machine.memory.data[address:], uint16(value))
+
+	case executor.OpcodeMemorySize:
+		machine.push(uint64(machine.memory.pages()))
+	case executor.OpcodeMemoryGrow:
+		previousPages, ok := machine.memory.grow(machine.popI32())
+		if ok {
+			machine.push(uint64(previousPages))
+		} else {
+			machine.push(uint64(math.MaxUint32))
+		}
+	case executor.OpcodeMemoryInit:
+		machine.memoryInit(uint32(current.value))
+	case executor.OpcodeDataDrop:
+		machine.droppedData[current.value] = true
+	case executor.OpcodeMemoryCopy:
+		length := machine.popI32()
+		source := machine.popI32()
+		destination := machine.popI32()
+		machine.checkMemoryRange(source, length)
+		machine.checkMemoryRange(destination, length)
+		copy(machine.memory.data[destination:destination+length], machine.memory.data[source:source+length])
+	case executor.OpcodeMemoryFill:
+		length := machine.popI32()
+		value := byte(machine.popI32())
+		destination := machine.popI32()
+		machine.checkMemoryRange(destination, length)
+		region := machine.memory.data[destination : destination+length]
+		for i := range region {
+			region[i] = value
+		}
+
+	case executor.OpcodeI32Const, executor.OpcodeI64Const:
+		machine.push(current.value)
+
+	default:
+		if current.opcode >= executor.OpcodeI64Eqz && current.opcode <= executor.OpcodeI64GeU {
+			machine.executeI64Comparison(current.opcode)
+			return
+		}
+		if current.opcode >= executor.OpcodeI64Clz && current.opcode <= executor.OpcodeI64Rotr {
+			machine.executeI64Arithmetic(current.opcode)
+			return
+		}
+		machine.executeI32OrConversion(current.opcode)
+	}
+}
+
+func (machine *machine) memoryInit(segmentIndex uint32) {
+	length := machine.popI32()
+	source := machine.popI32()
+	destination := machine.popI32()
+
+	data := machine.module.data[segmentIndex].data
+	if machine.droppedData[segmentIndex] {
+		data = nil
+	}
+	if uint64(source)+uint64(length) > uint64(len(data)) {
+		trapf("out of bounds data segment access")
+	}
+	machine.checkMemoryRange(destination, length)
+
+	copy(machine.memory.data[destination:destination+length], data[source:source+length])
+}
+
+func (machine *machine) executeI32OrConversion(opcode int) {
+	switch opcode {
+	case executor.OpcodeI32Eqz:
+		machine.pushBool(machine.popI32() == 0)
+	case executor.OpcodeI32Clz:
+		machine.push(uint64(bits.LeadingZeros32(machine.popI32())))
+	case executor.OpcodeI32Ctz:
+		machine.push(uint64(bits.TrailingZeros32(machine.popI32())))
+	case executor.OpcodeI32Popcnt:
+		machine.push(uint64(bits.OnesCount32(machine.popI32())))
+
+	case executor.OpcodeI32WrapI64:
+		machine.push(uint64(machine.popI32()))
+	case executor.OpcodeI64ExtendI32S:
+		machine.push(uint64(int64(int32(machine.popI32()))))
+	case executor.OpcodeI64ExtendI32U:
+		machine.push(uint64(machine.popI32()))
+	case executor.OpcodeI32Extend8S:
+		machine.push(uint64(uint32(int32(int8(machine.popI32())))))
+	case executor.OpcodeI32Extend16S:
+		machine.push(uint64(uint32(int32(int16(machine.popI32())))))
+	case executor.OpcodeI64Extend8S:
+		machine.push(uint64(int64(int8(machine.pop()))))
+	case executor.OpcodeI64Extend16S:
+		machine.push(uint64(int64(int16(machine.pop()))))
+	case executor.OpcodeI64Extend32S:
+		machine.push(uint64(int64(int32(machine.pop()))))
+
+	default:
+		second := machine.popI32()
+		first := machine.popI32()
+		machine.push(uint64(machine.computeI32Binary(opcode, first, second)))
+	}
+}
+
+func (machine *machine) computeI32Binary(opcode int, first uint32, second uint32) uint32 {
+	switch opcode {
+	case executor.OpcodeI32Eq:
+		return boolToU32(first == second)
+	case executor.OpcodeI32Ne:
+		return boolToU32(first != second)
+	case executor.OpcodeI32LtS:
+		return boolToU32(int32(first) < int32(second))
+	case executor.OpcodeI32LtU:
+		return boolToU32(first < second)
+	case executor.OpcodeI32GtS:
+		return boolToU32(int32(first) > int32(second))
+	case executor.OpcodeI32GtU:
+		return boolToU32(first > second)
+	case executor.OpcodeI32LeS:
+		return boolToU32(int32(first) <= int32(second))
+	case executor.OpcodeI32LeU:
+		return boolToU32(first <= second)
+	case executor.OpcodeI32GeS:
+		return boolToU32(int32(first) >= int32(second))
+	case executor.OpcodeI32GeU:
+		return boolToU32(first >= second)
+
+	case executor.OpcodeI32Add:
+		return first + second
+	case executor.OpcodeI32Sub:
+		return first - second
+	case executor.OpcodeI32Mul:
+		return first * second
+	case executor.OpcodeI32DivS:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		if int32(first) == math.MinInt32 && int32(second) == -1 {
+			trapf("integer overflow")
+		}
+		return uint32(int32(first) / int32(second))
+	case executor.OpcodeI32DivU:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		return first / second
+	case executor.OpcodeI32RemS:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		return uint32(int32(first) % int32(second))
+	case executor.OpcodeI32RemU:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		return first % second
+	case executor.OpcodeI32And:
+		return first & second
+	case executor.OpcodeI32Or:
+		return first | second
+	case executor.OpcodeI32Xor:
+		return first ^ second
+	case executor.OpcodeI32Shl:
+		return first << (second & 31)
+	case executor.OpcodeI32ShrS:
+		return uint32(int32(first) >> (second & 31))
+	case executor.OpcodeI32ShrU:
+		return first >> (second & 31)
+	case executor.OpcodeI32Rotl:
+		return bits.RotateLeft32(first, int(second&31))
+	case executor.OpcodeI32Rotr:
+		return bits.RotateLeft32(first, -int(second&31))
+	}
+
+	trapf("unsupported instruction %d", opcode)
+	return 0
+}
+
+func (machine *machine) executeI64Comparison(opcode int) {
+	if opcode == executor.OpcodeI64Eqz {
+		machine.pushBool(machine.pop() == 0)
+		return
+	}
+
+	second := machine.pop()
+	first := machine.pop()
+	switch opcode {
+	case executor.OpcodeI64Eq:
+		machine.pushBool(first == second)
+	case executor.OpcodeI64Ne:
+		machine.pushBool(first != second)
+	case executor.OpcodeI64LtS:
+		machine.pushBool(int64(first) < int64(second))
+	case executor.OpcodeI64LtU:
+		machine.pushBool(first < second)
+	case executor.OpcodeI64GtS:
+		machine.pushBool(int64(first) > int64(second))
+	case executor.OpcodeI64GtU:
+		machine.pushBool(first > second)
+	case executor.OpcodeI64LeS:
+		machine.pushBool(int64(first) <= int64(second))
+	case executor.OpcodeI64LeU:
+		machine.pushBool(first <= second)
+	case executor.OpcodeI64GeS:
+		machine.pushBool(int64(first) >= int64(second))
+	case executor.OpcodeI64GeU:
+		machine.pushBool(first >= second)
+	}
+}
+
+func (machine *machine) executeI64Arithmetic(opcode int) {
+	switch opcode {
+	case executor.OpcodeI64Clz:
+		machine.push(uint64(bits.LeadingZeros64(machine.pop())))
+		return
+	case executor.OpcodeI64Ctz:
+		machine.push(uint64(bits.TrailingZeros64(machine.pop())))
+		return
+	case executor.OpcodeI64Popcnt:
+		machine.push(uint64(bits.OnesCount64(machine.pop())))
+		return
+	}
+
+	second := machine.pop()
+	first := machine.pop()
+	switch opcode {
+	case executor.OpcodeI64Add:
+		machine.push(first + second)
+	case executor.OpcodeI64Sub:
+		machine.push(first - second)
+	case executor.OpcodeI64Mul:
+		machine.push(first * second)
+	case executor.OpcodeI64DivS:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		if int64(first) == math.MinInt64 && int64(second) == -1 {
+			trapf("integer overflow")
+		}
+		machine.push(uint64(int64(first) / int64(second)))
+	case executor.OpcodeI64DivU:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		machine.push(first / second)
+	case executor.OpcodeI64RemS:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		machine.push(uint64(int64(first) % int64(second)))
+	case executor.OpcodeI64RemU:
+		if second == 0 {
+			trapf("integer divide by zero")
+		}
+		machine.push(first % second)
+	case executor.OpcodeI64And:
+		machine.push(first & second)
+	case executor.OpcodeI64Or:
+		machine.push(first | second)
+	case executor.OpcodeI64Xor:
+		machine.push(first ^ second)
+	case executor.OpcodeI64Shl:
+		machine.push(first << (second & 63))
+	case executor.OpcodeI64ShrS:
+		machine.push(uint64(int64(first) >> (second & 63)))
+	case executor.OpcodeI64ShrU:
+		machine.push(first >> (second & 63))
+	case executor.OpcodeI64Rotl:
+		machine.push(bits.RotateLeft64(first, int(second&63)))
+	case executor.OpcodeI64Rotr:
+		machine.push(bits.RotateLeft64(first, -int(second&63)))
+	}
+}
+
+func boolToU32(value bool) uint32 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
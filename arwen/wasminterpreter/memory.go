@@ -0,0 +1,80 @@
+package wasminterpreter
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+)
+
+// wasmPageSize is the size of a page of the linear memory, in bytes
+const wasmPageSize = 65536
+
+// maxMemoryPages is the number of pages addressable with 32-bit offsets
+const maxMemoryPages = 65536
+
+// memory is the linear memory of an interpreted instance, implementing
+// executor.MemoryHandler
+type memory struct {
+	data     []byte
+	maxPages uint32
+}
+
+func newMemory(memoryLimits *wasmparser.Limits) *memory {
+	maxPages := uint32(maxMemoryPages)
+	if memoryLimits.HasMax {
+		maxPages = memoryLimits.Max
+	}
+
+	return &memory{
+		data:     make([]byte, uint64(memoryLimits.Min)*wasmPageSize),
+		maxPages: maxPages,
+	}
+}
+
+// Length returns the size of the memory, in bytes
+func (memory *memory) Length() uint32 {
+	return uint32(len(memory.data))
+}
+
+// Data returns the contents of the memory; the slice is replaced when the
+// memory grows
+func (memory *memory) Data() []byte {
+	return memory.data
+}
+
+// Grow adds the given number of pages to the memory
+func (memory *memory) Grow(pages uint32) error {
+	_, ok := memory.grow(pages)
+	if !ok {
+		return fmt.Errorf("cannot grow the memory of %d pages by %d pages", memory.pages(), pages)
+	}
+
+	return nil
+}
+
+// Destroy releases the contents of the memory
+func (memory *memory) Destroy() {
+	memory.data = nil
+}
+
+func (memory *memory) pages() uint32 {
+	return uint32(len(memory.data) / wasmPageSize)
+}
+
+// grow adds pages to the memory, returning the previous number of pages,
+// or false if the memory would exceed its maximum
+func (memory *memory) grow(pages uint32) (uint32, bool) {
+	previousPages := memory.pages()
+	if uint64(previousPages)+uint64(pages) > uint64(memory.maxPages) {
+		return previousPages, false
+	}
+	if pages == 0 {
+		return previousPages, true
+	}
+
+	newData := make([]byte, (uint64(previousPages)+uint64(pages))*wasmPageSize)
+	copy(newData, memory.data)
+	memory.data = newData
+
+	return previousPages, true
+}
@@ -0,0 +1,467 @@
+package wasminterpreter
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasmparser"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+// maxLocalsCount is the maximum number of locals declared by a function,
+// as enforced by Wasmer
+const maxLocalsCount = 4000
+
+type valueType byte
+
+const (
+	valueTypeI32 = valueType(wasmparser.ValueTypeI32)
+	valueTypeI64 = valueType(wasmparser.ValueTypeI64)
+	valueTypeF32 = valueType(wasmparser.ValueTypeF32)
+	valueTypeF64 = valueType(wasmparser.ValueTypeF64)
+)
+
+type functionType struct {
+	params  []valueType
+	results []valueType
+}
+
+type importedFunction struct {
+	module    string
+	name      string
+	typeIndex uint32
+}
+
+// function is a function defined by the module; its code is compiled
+// when the module is loaded
+type function struct {
+	typeIndex    uint32
+	localsCount  uint32
+	instructions []instruction
+}
+
+type global struct {
+	valueType    valueType
+	mutable      bool
+	initialValue uint64
+}
+
+type export struct {
+	kind  wasmparser.ExternalKind
+	index uint32
+}
+
+type elementSegment struct {
+	active    bool
+	offset    uint32
+	functions []uint32
+}
+
+type dataSegment struct {
+	active bool
+	offset uint32
+	data   []byte
+}
+
+// module is a WASM module compiled by the interpreter, ready to be
+// instantiated any number of times
+type module struct {
+	types         []*functionType
+	imports       []*importedFunction
+	functions     []*function
+	table         *wasmparser.Limits
+	memory        *wasmparser.Limits
+	globals       []*global
+	exports       map[string]*export
+	exportNames   []string
+	hasStart      bool
+	startFunction uint32
+	elements      []*elementSegment
+	data          []*dataSegment
+}
+
+// loadModule parses the WASM module and compiles the code of its functions;
+// the function bodies are not type-checked, only decoded
+func loadModule(code []byte) (*module, error) {
+	parsed, err := wasmparser.ParseModule(code)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &module{
+		exports:       make(map[string]*export),
+		hasStart:      parsed.HasStart,
+		startFunction: parsed.StartFunction,
+	}
+
+	steps := []func(*wasmparser.Module) error{
+		loaded.loadTypes,
+		loaded.loadImports,
+		loaded.loadTableAndMemory,
+		loaded.loadGlobals,
+		loaded.loadExports,
+		loaded.loadElements,
+		loaded.loadData,
+		loaded.loadFunctions,
+	}
+	for _, step := range steps {
+		err = step(parsed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = loaded.verifyIndices()
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+func (module *module) loadTypes(parsed *wasmparser.Module) error {
+	module.types = make([]*functionType, 0, len(parsed.Types))
+	for i, signature := range parsed.Types {
+		params, err := convertValueTypes(signature.Params)
+		if err != nil {
+			return fmt.Errorf("type %d: %w", i, err)
+		}
+
+		results, err := convertValueTypes(signature.Results)
+		if err != nil {
+			return fmt.Errorf("type %d: %w", i, err)
+		}
+
+		module.types = append(module.types, &functionType{
+			params:  params,
+			results: results,
+		})
+	}
+
+	return nil
+}
+
+func (module *module) loadImports(parsed *wasmparser.Module) error {
+	module.imports = make([]*importedFunction, 0, len(parsed.Imports))
+	for _, imported := range parsed.Imports {
+		if imported.Kind != wasmparser.ExternalFunction {
+			return fmt.Errorf("unsupported import %s.%s of kind %s", imported.Module, imported.Name, imported.Kind)
+		}
+
+		module.imports = append(module.imports, &importedFunction{
+			module:    imported.Module,
+			name:      imported.Name,
+			typeIndex: imported.TypeIndex,
+		})
+	}
+
+	return nil
+}
+
+func (module *module) loadTableAndMemory(parsed *wasmparser.Module) error {
+	if len(parsed.Tables) > 1 {
+		return fmt.Errorf("multiple tables")
+	}
+	for _, table := range parsed.Tables {
+		if table.ElementType != wasmparser.ValueTypeFuncRef {
+			return fmt.Errorf("unsupported table element type %s", table.ElementType)
+		}
+		err := verifyLimits(&table.Limits)
+		if err != nil {
+			return err
+		}
+		module.table = &table.Limits
+	}
+
+	if len(parsed.Memories) > 1 {
+		return fmt.Errorf("multiple memories")
+	}
+	for _, memory := range parsed.Memories {
+		err := verifyLimits(&memory.Limits)
+		if err != nil {
+			return err
+		}
+		if memory.Min > maxMemoryPages || memory.MaximumPages() > maxMemoryPages {
+			return fmt.Errorf("memory size exceeds %d pages", maxMemoryPages)
+		}
+		module.memory = &memory.Limits
+	}
+
+	return nil
+}
+
+func (module *module) loadGlobals(parsed *wasmparser.Module) error {
+	module.globals = make([]*global, 0, len(parsed.Globals))
+	for i, parsedGlobal := range parsed.Globals {
+		if !parsedGlobal.InitIsConstant {
+			return fmt.Errorf("global %d is not initialized by an integer constant", i)
+		}
+
+		loadedGlobal := &global{
+			valueType:    valueType(parsedGlobal.Type),
+			mutable:      parsedGlobal.Mutable,
+			initialValue: uint64(parsedGlobal.Init),
+		}
+		switch loadedGlobal.valueType {
+		case valueTypeI32:
+			loadedGlobal.initialValue = uint64(uint32(parsedGlobal.Init))
+		case valueTypeI64:
+		default:
+			return fmt.Errorf("global %d of unsupported type %s", i, parsedGlobal.Type)
+		}
+
+		module.globals = append(module.globals, loadedGlobal)
+	}
+
+	return nil
+}
+
+func (module *module) loadExports(parsed *wasmparser.Module) error {
+	module.exportNames = make([]string, 0, len(parsed.Exports))
+	for _, parsedExport := range parsed.Exports {
+		module.exports[parsedExport.Name] = &export{
+			kind:  parsedExport.Kind,
+			index: parsedExport.Index,
+		}
+		module.exportNames = append(module.exportNames, parsedExport.Name)
+	}
+
+	return nil
+}
+
+func (module *module) loadElements(parsed *wasmparser.Module) error {
+	module.elements = make([]*elementSegment, 0, len(parsed.ElementSegments))
+	for i, segment := range parsed.ElementSegments {
+		if segment.UsesExpressions || segment.TableIndex != 0 {
+			return fmt.Errorf("unsupported element segment %d", i)
+		}
+
+		active := !segment.Passive && !segment.Declarative
+		if active && !segment.OffsetIsConstant {
+			return fmt.Errorf("element segment %d without a constant offset", i)
+		}
+
+		module.elements = append(module.elements, &elementSegment{
+			active:    active,
+			offset:    uint32(segment.Offset),
+			functions: segment.Functions,
+		})
+	}
+
+	return nil
+}
+
+func (module *module) loadData(parsed *wasmparser.Module) error {
+	module.data = make([]*dataSegment, 0, len(parsed.DataSegments))
+	for i, segment := range parsed.DataSegments {
+		if segment.MemoryIndex != 0 {
+			return fmt.Errorf("data segment %d of unknown memory %d", i, segment.MemoryIndex)
+		}
+		if !segment.Passive && !segment.OffsetIsConstant {
+			return fmt.Errorf("data segment %d without a constant offset", i)
+		}
+
+		module.data = append(module.data, &dataSegment{
+			active: !segment.Passive,
+			offset: uint32(segment.Offset),
+			data:   segment.Data,
+		})
+	}
+
+	return nil
+}
+
+// loadFunctions compiles the code of the functions, after all the other
+// entities of the module are loaded
+func (module *module) loadFunctions(parsed *wasmparser.Module) error {
+	module.functions = make([]*function, 0, len(parsed.Functions))
+	for i, parsedFunction := range parsed.Functions {
+		if parsedFunction.LocalsCount > maxLocalsCount {
+			return fmt.Errorf("function body %d: too many locals", i)
+		}
+
+		compiler := &functionCompiler{
+			reader: wasmparser.NewReader(parsedFunction.Code),
+			module: module,
+		}
+		instructions, err := compiler.compile()
+		if err != nil {
+			return fmt.Errorf("function body %d: %w", i, err)
+		}
+
+		module.functions = append(module.functions, &function{
+			typeIndex:    parsedFunction.TypeIndex,
+			localsCount:  uint32(parsedFunction.LocalsCount),
+			instructions: instructions,
+		})
+	}
+
+	return nil
+}
+
+// verifyIndices checks the indices which are not checked by the parser
+func (module *module) verifyIndices() error {
+	functionsCount := module.functionsCount()
+
+	for _, name := range module.exportNames {
+		exported := module.exports[name]
+		switch exported.kind {
+		case wasmparser.ExternalMemory:
+			if module.memory == nil || exported.index != 0 {
+				return fmt.Errorf("export %s of unknown memory %d", name, exported.index)
+			}
+		case wasmparser.ExternalTable:
+			if module.table == nil || exported.index != 0 {
+				return fmt.Errorf("export %s of unknown table %d", name, exported.index)
+			}
+		case wasmparser.ExternalGlobal:
+			if exported.index >= uint32(len(module.globals)) {
+				return fmt.Errorf("export %s of unknown global %d", name, exported.index)
+			}
+		}
+	}
+
+	if module.hasStart {
+		if module.startFunction >= functionsCount {
+			return fmt.Errorf("unknown start function %d", module.startFunction)
+		}
+		startType := module.typeOfFunction(module.startFunction)
+		if len(startType.params) > 0 || len(startType.results) > 0 {
+			return fmt.Errorf("start function %d is not void", module.startFunction)
+		}
+	}
+
+	for _, segment := range module.elements {
+		if segment.active && module.table == nil {
+			return fmt.Errorf("element segment without table")
+		}
+		for _, functionIndex := range segment.functions {
+			if functionIndex >= functionsCount {
+				return fmt.Errorf("element of unknown function %d", functionIndex)
+			}
+		}
+	}
+
+	for _, segment := range module.data {
+		if segment.active && module.memory == nil {
+			return fmt.Errorf("data segment without memory")
+		}
+	}
+
+	return module.verifyInstructionIndices()
+}
+
+func (module *module) verifyInstructionIndices() error {
+	functionsCount := module.functionsCount()
+
+	for i, function := range module.functions {
+		localsCount := uint64(len(module.types[function.typeIndex].params)) + uint64(function.localsCount)
+
+		for _, instruction := range function.instructions {
+			var err error
+			switch instruction.opcode {
+			case executor.OpcodeCall:
+				if instruction.value >= uint64(functionsCount) {
+					err = fmt.Errorf("call of unknown function %d", instruction.value)
+				}
+			case executor.OpcodeCallIndirect:
+				if module.table == nil {
+					err = fmt.Errorf("call_indirect without table")
+				}
+			case executor.OpcodeLocalGet, executor.OpcodeLocalSet, executor.OpcodeLocalTee:
+				if instruction.value >= localsCount {
+					err = fmt.Errorf("unknown local %d", instruction.value)
+				}
+			case executor.OpcodeGlobalGet:
+				if instruction.value >= uint64(len(module.globals)) {
+					err = fmt.Errorf("unknown global %d", instruction.value)
+				}
+			case executor.OpcodeGlobalSet:
+				if instruction.value >= uint64(len(module.globals)) || !module.globals[instruction.value].mutable {
+					err = fmt.Errorf("global %d is unknown or immutable", instruction.value)
+				}
+			case executor.OpcodeMemoryInit, executor.OpcodeDataDrop:
+				if instruction.value >= uint64(len(module.data)) {
+					err = fmt.Errorf("unknown data segment %d", instruction.value)
+				}
+			}
+			if err == nil && instruction.accessesMemory && module.memory == nil {
+				err = fmt.Errorf("memory instruction without memory")
+			}
+			if err != nil {
+				return fmt.Errorf("function %d: %w", uint32(len(module.imports))+uint32(i), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (module *module) functionsCount() uint32 {
+	return uint32(len(module.imports) + len(module.functions))
+}
+
+func (module *module) typeOfFunction(index uint32) *functionType {
+	if index < uint32(len(module.imports)) {
+		return module.types[module.imports[index].typeIndex]
+	}
+
+	return module.types[module.functions[index-uint32(len(module.imports))].typeIndex]
+}
+
+// functionNames names the functions after their import or export, or else
+// after their index
+func (module *module) functionNames() []string {
+	names := make([]string, module.functionsCount())
+	for i, imported := range module.imports {
+		names[i] = imported.name
+	}
+	for _, name := range module.exportNames {
+		exported := module.exports[name]
+		if exported.kind == wasmparser.ExternalFunction && len(names[exported.index]) == 0 {
+			names[exported.index] = name
+		}
+	}
+	for i := range names {
+		if len(names[i]) == 0 {
+			names[i] = fmt.Sprintf("function[%d]", i)
+		}
+	}
+
+	return names
+}
+
+// hasExportedMemory mirrors the Wasmer instances, which only expose their
+// exported memory
+func (module *module) hasExportedMemory() bool {
+	for _, exported := range module.exports {
+		if exported.kind == wasmparser.ExternalMemory {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertValueTypes rejects the reference types, which the interpreter does
+// not support
+func convertValueTypes(parsedTypes []wasmparser.ValueType) ([]valueType, error) {
+	converted := make([]valueType, 0, len(parsedTypes))
+	for _, parsedType := range parsedTypes {
+		switch parsedType {
+		case wasmparser.ValueTypeI32, wasmparser.ValueTypeI64, wasmparser.ValueTypeF32, wasmparser.ValueTypeF64:
+			converted = append(converted, valueType(parsedType))
+		default:
+			return nil, fmt.Errorf("unsupported value type %s", parsedType)
+		}
+	}
+
+	return converted, nil
+}
+
+func verifyLimits(parsedLimits *wasmparser.Limits) error {
+	if parsedLimits.HasMax && parsedLimits.Max < parsedLimits.Min {
+		return fmt.Errorf("maximum size below the minimum")
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package wasminterpreter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+)
+
+// frameSeparator separates the frames of a stack, as in the folded stacks
+// read by the flame graph tools
+const frameSeparator = ";"
+
+// opcodeCategories caches the category of each opcode, looked up for each
+// instruction executed while profiling
+var opcodeCategories = makeOpcodeCategories()
+
+func makeOpcodeCategories() *[executor.OPCODE_COUNT]executor.OpcodeCategory {
+	categories := &[executor.OPCODE_COUNT]executor.OpcodeCategory{}
+	for opcode := range categories {
+		categories[opcode] = executor.GetOpcodeCategory(opcode)
+	}
+
+	return categories
+}
+
+// ProfileValue selects the value written for each sample of a profile
+type ProfileValue int
+
+const (
+	// ProfileOpcodes writes the number of executed opcodes
+	ProfileOpcodes ProfileValue = iota
+
+	// ProfileGas writes the gas charged for the executed opcodes, according
+	// to the opcode costs of the instances
+	ProfileGas
+)
+
+// ProfileSample holds the opcodes of a category executed by a function,
+// when called through the given stack of functions
+type ProfileSample struct {
+	Stack    []string
+	Category executor.OpcodeCategory
+	Opcodes  uint64
+	Gas      uint64
+}
+
+// Profiler counts the opcodes executed by the interpreted instances created
+// by the builders given to it, grouped by their category, for each stack of
+// function calls. A stack starts with the exported function called by
+// Arwen, followed by the functions it calls; the functions which are not
+// exported are named after their index, as in the text format of WASM. The
+// execution of a contract called synchronously by another contract
+// continues the stack of its caller, from the imported function which has
+// made the call; therefore the instances given to a profiler must run one
+// at a time, as in Arwen.
+//
+// Profiling slows the interpreter down, therefore it is only enabled for
+// the builders given to SetProfiler().
+//
+// Only the interpreted instances are profiled; the Wasmer instances are not,
+// even with the opcode trace option. The gas of a sample is the cost of its
+// opcodes as charged by the interpreter, one instruction at a time, whereas
+// Wasmer charges the cost of a whole block of instructions when entering it.
+// The two amounts are equal for the executions which complete, but differ
+// for an execution which fails in the middle of a block, e.g. by running out
+// of gas or by a trap: Wasmer has already charged the rest of the block.
+type Profiler struct {
+	mutex   sync.Mutex
+	frames  map[string]*profileFrame
+	running []*machine
+}
+
+// NewProfiler creates an empty profiler
+func NewProfiler() *Profiler {
+	return &Profiler{
+		frames:  make(map[string]*profileFrame),
+		running: make([]*machine, 0),
+	}
+}
+
+// profileFrame holds the opcodes executed by a function, when called through
+// a given stack of functions
+type profileFrame struct {
+	stack    string
+	children map[uint32]*profileFrame
+	opcodes  [executor.OpcodeCategoryCount]uint64
+	gas      [executor.OpcodeCategoryCount]uint64
+}
+
+func (frame *profileFrame) record(opcode int, cost uint32, count uint64) {
+	category := opcodeCategories[opcode]
+	frame.opcodes[category] += count
+	frame.gas[category] += uint64(cost) * count
+}
+
+// enter registers the start of an execution of the given machine, returning
+// the stack from which it is called
+func (profiler *Profiler) enter(machine *machine) string {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	callerStack := ""
+	if len(profiler.running) > 0 {
+		callerStack = profiler.running[len(profiler.running)-1].currentStack()
+	}
+	profiler.running = append(profiler.running, machine)
+
+	return callerStack
+}
+
+// exit registers the end of an execution of the given machine, adding the
+// opcodes it has executed to the profile
+func (profiler *Profiler) exit(machine *machine, frames []*profileFrame) {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	for i := len(profiler.running) - 1; i >= 0; i-- {
+		if profiler.running[i] == machine {
+			profiler.running = append(profiler.running[:i], profiler.running[i+1:]...)
+			break
+		}
+	}
+
+	for _, frame := range frames {
+		total, ok := profiler.frames[frame.stack]
+		if !ok {
+			total = &profileFrame{stack: frame.stack}
+			profiler.frames[frame.stack] = total
+		}
+
+		for category := range frame.opcodes {
+			total.opcodes[category] += frame.opcodes[category]
+			total.gas[category] += frame.gas[category]
+		}
+	}
+}
+
+// Reset discards the opcodes counted so far
+func (profiler *Profiler) Reset() {
+	profiler.mutex.Lock()
+	profiler.frames = make(map[string]*profileFrame)
+	profiler.mutex.Unlock()
+}
+
+// Samples returns the opcodes counted so far, ordered by stack and category;
+// the categories of which no opcode has been executed are left out
+func (profiler *Profiler) Samples() []*ProfileSample {
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+
+	stacks := make([]string, 0, len(profiler.frames))
+	for stack := range profiler.frames {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+
+	samples := make([]*ProfileSample, 0)
+	for _, stack := range stacks {
+		frame := profiler.frames[stack]
+		for category, opcodes := range frame.opcodes {
+			if opcodes == 0 {
+				continue
+			}
+
+			samples = append(samples, &ProfileSample{
+				Stack:    strings.Split(stack, frameSeparator),
+				Category: executor.OpcodeCategory(category),
+				Opcodes:  opcodes,
+				Gas:      frame.gas[category],
+			})
+		}
+	}
+
+	return samples
+}
+
+// WriteFoldedStacks writes the profile as folded stacks, one sample per
+// line, with the category as the last frame, e.g.
+//
+//	callBack;function[12];memory 1024
+//
+// This format is read by flamegraph.pl, speedscope and other flame graph
+// tools.
+func (profiler *Profiler) WriteFoldedStacks(writer io.Writer, value ProfileValue) error {
+	for _, sample := range profiler.Samples() {
+		sampleValue := sample.Opcodes
+		if value == ProfileGas {
+			sampleValue = sample.Gas
+		}
+		if sampleValue == 0 {
+			continue
+		}
+
+		stack := strings.Join(sample.Stack, frameSeparator)
+		_, err := fmt.Fprintf(writer, "%s%s%s %d\n", stack, frameSeparator, sample.Category.String(), sampleValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startProfiling prepares the profiling of an execution of the machine
+func (machine *machine) startProfiling() {
+	machine.callerStack = machine.profiler.enter(machine)
+	machine.profileRoots = make(map[uint32]*profileFrame)
+	machine.profileFrames = make([]*profileFrame, 0)
+	machine.activeFrames = machine.activeFrames[:0]
+}
+
+// stopProfiling adds the opcodes of the finished execution to the profiler,
+// including those executed before a trap
+func (machine *machine) stopProfiling() {
+	machine.profiler.exit(machine, machine.profileFrames)
+	machine.profileRoots = nil
+	machine.profileFrames = nil
+	machine.activeFrames = machine.activeFrames[:0]
+}
+
+// pushFrame enters the given function, returning the frame which counts its
+// opcodes for the current stack of calls
+func (machine *machine) pushFrame(functionIndex uint32) *profileFrame {
+	siblings := machine.profileRoots
+	if len(machine.activeFrames) > 0 {
+		parent := machine.activeFrames[len(machine.activeFrames)-1]
+		if parent.children == nil {
+			parent.children = make(map[uint32]*profileFrame)
+		}
+		siblings = parent.children
+	}
+
+	frame, ok := siblings[functionIndex]
+	if !ok {
+		frame = &profileFrame{
+			stack: joinFrames(machine.currentStack(), machine.functionNames[functionIndex]),
+		}
+		siblings[functionIndex] = frame
+		machine.profileFrames = append(machine.profileFrames, frame)
+	}
+
+	machine.activeFrames = append(machine.activeFrames, frame)
+	return frame
+}
+
+func (machine *machine) popFrame() {
+	machine.activeFrames = machine.activeFrames[:len(machine.activeFrames)-1]
+}
+
+// currentFrame returns the frame of the running function, or nil if the
+// machine is not profiled
+func (machine *machine) currentFrame() *profileFrame {
+	if len(machine.activeFrames) == 0 {
+		return nil
+	}
+
+	return machine.activeFrames[len(machine.activeFrames)-1]
+}
+
+func (machine *machine) currentStack() string {
+	frame := machine.currentFrame()
+	if frame == nil {
+		return machine.callerStack
+	}
+
+	return frame.stack
+}
+
+func joinFrames(stack string, name string) string {
+	if len(stack) == 0 {
+		return name
+	}
+
+	return stack + frameSeparator + name
+}
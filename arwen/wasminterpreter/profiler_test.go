@@ -0,0 +1,149 @@
+package wasminterpreter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
+	"github.com/stretchr/testify/require"
+)
+
+// callingModule exports outer() i32, which returns 1 + 1 + 1 by calling
+// twice the function 1, not exported, which adds 1 to its argument
+var callingModule = assembleModule(
+	section(1, 0x02,
+		0x60, 0x00, 0x01, 0x7F,
+		0x60, 0x01, 0x7F, 0x01, 0x7F,
+	),
+	section(3, 0x02, 0x00, 0x01),
+	section(7, concat([]byte{0x01},
+		exportEntry("outer", 0x00, 0),
+	)...),
+	section(10, concat([]byte{0x02},
+		functionBody(0x00, 0x41, 0x01, 0x10, 0x01, 0x10, 0x01, 0x0B),
+		functionBody(0x00, 0x20, 0x00, 0x41, 0x01, 0x6A, 0x0B),
+	)...),
+)
+
+func TestGetOpcodeCategory(t *testing.T) {
+	require.Equal(t, executor.OpcodeCategoryControl, executor.GetOpcodeCategory(executor.OpcodeUnreachable))
+	require.Equal(t, executor.OpcodeCategoryControl, executor.GetOpcodeCategory(executor.OpcodeCallIndirect))
+	require.Equal(t, executor.OpcodeCategoryParametric, executor.GetOpcodeCategory(executor.OpcodeSelect))
+	require.Equal(t, executor.OpcodeCategoryVariable, executor.GetOpcodeCategory(executor.OpcodeGlobalSet))
+	require.Equal(t, executor.OpcodeCategoryMemory, executor.GetOpcodeCategory(executor.OpcodeMemoryGrow))
+	require.Equal(t, executor.OpcodeCategoryConstant, executor.GetOpcodeCategory(executor.OpcodeI64Const))
+	require.Equal(t, executor.OpcodeCategoryComparison, executor.GetOpcodeCategory(executor.OpcodeF64Ge))
+	require.Equal(t, executor.OpcodeCategoryNumeric, executor.GetOpcodeCategory(executor.OpcodeI64Rotr))
+	require.Equal(t, executor.OpcodeCategoryConversion, executor.GetOpcodeCategory(executor.OpcodeI64TruncSatF64U))
+	require.Equal(t, executor.OpcodeCategoryMemory, executor.GetOpcodeCategory(executor.OpcodeMemoryFill))
+	require.Equal(t, executor.OpcodeCategoryReference, executor.GetOpcodeCategory(executor.OpcodeTableSize))
+	require.Equal(t, executor.OpcodeCategoryAtomic, executor.GetOpcodeCategory(executor.OpcodeI64AtomicRmw32CmpxchgU))
+	require.Equal(t, executor.OpcodeCategoryVector, executor.GetOpcodeCategory(executor.OpcodeI16x8RoundingAverageU))
+	require.Equal(t, executor.OpcodeCategoryVariable, executor.GetOpcodeCategory(executor.OpcodeLocalAllocate))
+	require.Equal(t, "numeric", executor.OpcodeCategoryNumeric.String())
+	require.Equal(t, "unknown", executor.OpcodeCategoryCount.String())
+}
+
+func TestProfiler_CallFrames(t *testing.T) {
+	profiler := NewProfiler()
+	builder := NewInstanceBuilderWithImports(executor.NewImports(), newTestOpcodeCosts())
+	builder.SetProfiler(profiler)
+
+	instance, err := builder.NewInstanceWithOptions(callingModule, defaultTestOptions())
+	require.Nil(t, err)
+	defer instance.Clean()
+
+	result, err := instance.GetExports()["outer"]()
+	require.Nil(t, err)
+	require.Equal(t, int32(3), result.ToI32())
+
+	_, err = instance.GetExports()["outer"]()
+	require.Nil(t, err)
+
+	buffer := &bytes.Buffer{}
+	err = profiler.WriteFoldedStacks(buffer, ProfileOpcodes)
+	require.Nil(t, err)
+	require.Equal(t, "outer;control 6\n"+
+		"outer;constant 2\n"+
+		"outer;function[1];control 4\n"+
+		"outer;function[1];variable 4\n"+
+		"outer;function[1];constant 4\n"+
+		"outer;function[1];numeric 4\n", buffer.String())
+
+	profiler.Reset()
+	require.Empty(t, profiler.Samples())
+}
+
+func TestProfiler_Gas(t *testing.T) {
+	opcodeCosts := newTestOpcodeCosts()
+	opcodeCosts[executor.OpcodeI32Add] = 10
+
+	profiler := NewProfiler()
+	builder := NewInstanceBuilderWithImports(executor.NewImports(), opcodeCosts)
+	builder.SetProfiler(profiler)
+
+	instance, err := builder.NewInstanceWithOptions(callingModule, defaultTestOptions())
+	require.Nil(t, err)
+	defer instance.Clean()
+
+	_, err = instance.GetExports()["outer"]()
+	require.Nil(t, err)
+
+	gas := uint64(0)
+	for _, sample := range profiler.Samples() {
+		gas += sample.Gas
+		if sample.Category == executor.OpcodeCategoryNumeric {
+			require.Equal(t, []string{"outer", "function[1]"}, sample.Stack)
+			require.Equal(t, uint64(2), sample.Opcodes)
+			require.Equal(t, uint64(20), sample.Gas)
+		}
+	}
+	require.Equal(t, instance.GetPointsUsed(), gas)
+}
+
+func TestProfiler_NestedInstances(t *testing.T) {
+	profiler := NewProfiler()
+	nestedBuilder := NewInstanceBuilderWithImports(executor.NewImports(), newTestOpcodeCosts())
+	nestedBuilder.SetProfiler(profiler)
+	nested, err := nestedBuilder.NewInstanceWithOptions(callingModule, defaultTestOptions())
+	require.Nil(t, err)
+	defer nested.Clean()
+
+	double := func(context unsafe.Pointer, value int32) int32 {
+		result, _ := nested.GetExports()["outer"]()
+		return value * result.ToI32()
+	}
+	imports, err := executor.NewImports().Namespace("env").Append("double", double, nil)
+	require.Nil(t, err)
+
+	builder := NewInstanceBuilderWithImports(imports, newTestOpcodeCosts())
+	builder.SetProfiler(profiler)
+	instance, err := builder.NewInstanceWithOptions(hostModule, defaultTestOptions())
+	require.Nil(t, err)
+	defer instance.Clean()
+
+	result, err := instance.GetExports()["callDouble"](2)
+	require.Nil(t, err)
+	require.Equal(t, int32(6), result.ToI32())
+
+	stacks := make(map[string]bool)
+	for _, sample := range profiler.Samples() {
+		stacks[joinFrames(strings.Join(sample.Stack, frameSeparator), sample.Category.String())] = true
+	}
+	require.True(t, stacks["callDouble;control"])
+	require.True(t, stacks["callDouble;double;outer;constant"])
+	require.True(t, stacks["callDouble;double;outer;function[1];numeric"])
+	require.False(t, stacks["outer;constant"])
+}
+
+func TestProfiler_Disabled(t *testing.T) {
+	profiler := NewProfiler()
+	instance := newTestInstance(t, callingModule, defaultTestOptions())
+	defer instance.Clean()
+
+	_, err := instance.GetExports()["outer"]()
+	require.Nil(t, err)
+	require.Empty(t, profiler.Samples())
+}
@@ -0,0 +1,283 @@
+package wasmparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValueType is the type of a WASM value
+type ValueType byte
+
+const (
+	// ValueTypeI32 is the 32-bit integer type
+	ValueTypeI32 ValueType = 0x7F
+
+	// ValueTypeI64 is the 64-bit integer type
+	ValueTypeI64 ValueType = 0x7E
+
+	// ValueTypeF32 is the 32-bit floating point type
+	ValueTypeF32 ValueType = 0x7D
+
+	// ValueTypeF64 is the 64-bit floating point type
+	ValueTypeF64 ValueType = 0x7C
+
+	// ValueTypeFuncRef is the type of the references to functions
+	ValueTypeFuncRef ValueType = 0x70
+
+	// ValueTypeExternRef is the type of the references to host objects
+	ValueTypeExternRef ValueType = 0x6F
+)
+
+// IsFloat returns true for the floating point types
+func (valueType ValueType) IsFloat() bool {
+	return valueType == ValueTypeF32 || valueType == ValueTypeF64
+}
+
+// String returns the name of the type, as written in the text format
+func (valueType ValueType) String() string {
+	switch valueType {
+	case ValueTypeI32:
+		return "i32"
+	case ValueTypeI64:
+		return "i64"
+	case ValueTypeF32:
+		return "f32"
+	case ValueTypeF64:
+		return "f64"
+	case ValueTypeFuncRef:
+		return "funcref"
+	case ValueTypeExternRef:
+		return "externref"
+	}
+
+	return fmt.Sprintf("unknown(0x%02x)", byte(valueType))
+}
+
+func isValidValueType(valueType ValueType) bool {
+	switch valueType {
+	case ValueTypeI32, ValueTypeI64, ValueTypeF32, ValueTypeF64, ValueTypeFuncRef, ValueTypeExternRef:
+		return true
+	}
+
+	return false
+}
+
+// ExternalKind is the kind of an imported or exported entity
+type ExternalKind byte
+
+const (
+	// ExternalFunction is the kind of the imported or exported functions
+	ExternalFunction ExternalKind = iota
+
+	// ExternalTable is the kind of the imported or exported tables
+	ExternalTable
+
+	// ExternalMemory is the kind of the imported or exported memories
+	ExternalMemory
+
+	// ExternalGlobal is the kind of the imported or exported globals
+	ExternalGlobal
+)
+
+// String returns the name of the kind, as written in the text format
+func (kind ExternalKind) String() string {
+	switch kind {
+	case ExternalFunction:
+		return "func"
+	case ExternalTable:
+		return "table"
+	case ExternalMemory:
+		return "memory"
+	case ExternalGlobal:
+		return "global"
+	}
+
+	return fmt.Sprintf("unknown(0x%02x)", byte(kind))
+}
+
+// FunctionSignature holds the types of the parameters and results of a function
+type FunctionSignature struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+// IsVoid returns true if the function has neither parameters nor results
+func (signature *FunctionSignature) IsVoid() bool {
+	return len(signature.Params) == 0 && len(signature.Results) == 0
+}
+
+// String returns the signature in the form "(i32, i64) -> (i32)"
+func (signature *FunctionSignature) String() string {
+	return fmt.Sprintf("(%s) -> (%s)", joinValueTypes(signature.Params), joinValueTypes(signature.Results))
+}
+
+func joinValueTypes(valueTypes []ValueType) string {
+	names := make([]string, len(valueTypes))
+	for i, valueType := range valueTypes {
+		names[i] = valueType.String()
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Limits holds the minimum and the optional maximum size of a memory, in
+// pages, or of a table, in elements
+type Limits struct {
+	Min    uint32
+	Max    uint32
+	HasMax bool
+}
+
+// Import describes an entity imported by the module; only the imported
+// functions have a signature
+type Import struct {
+	Module    string
+	Name      string
+	Kind      ExternalKind
+	TypeIndex uint32
+	Signature *FunctionSignature
+}
+
+// Export describes an entity exported by the module; only the exported
+// functions have a signature
+type Export struct {
+	Name      string
+	Kind      ExternalKind
+	Index     uint32
+	Signature *FunctionSignature
+}
+
+// maxWasm32MemoryPages is the number of pages which a 32-bit memory can
+// address, i.e. the maximum size of a memory which declares none
+const maxWasm32MemoryPages = 65536
+
+// Memory describes a memory declared or imported by the module
+type Memory struct {
+	Limits
+	Imported bool
+}
+
+// MaximumPages returns the number of pages to which the memory can grow
+func (memory *Memory) MaximumPages() uint32 {
+	if !memory.HasMax {
+		return maxWasm32MemoryPages
+	}
+
+	return memory.Max
+}
+
+// Table describes a table declared or imported by the module
+type Table struct {
+	Limits
+	ElementType ValueType
+	Imported    bool
+}
+
+// Global describes a global declared or imported by the module; the initial
+// value of a declared global is known only if given by a constant expression
+type Global struct {
+	Type           ValueType
+	Mutable        bool
+	Imported       bool
+	Init           int64
+	InitIsConstant bool
+}
+
+// ElementSegment describes a segment of function references which
+// initializes a table; the offset is known only if given by a constant
+// expression, and the functions only if not given by expressions
+type ElementSegment struct {
+	TableIndex       uint32
+	Passive          bool
+	Declarative      bool
+	Offset           int64
+	OffsetIsConstant bool
+	Functions        []uint32
+	UsesExpressions  bool
+}
+
+// DataSegment describes a segment of data which initializes the memory; the
+// offset is known only if given by a constant expression
+type DataSegment struct {
+	MemoryIndex      uint32
+	Passive          bool
+	Offset           int64
+	OffsetIsConstant bool
+	Size             uint32
+	Data             []byte `json:"-"`
+}
+
+// CustomSection describes a custom section of the module, such as "name"
+type CustomSection struct {
+	Name string
+	Size uint32
+}
+
+// Function describes a function defined by the module, with the number of
+// its declared locals, excluding its parameters, and its instructions
+type Function struct {
+	TypeIndex   uint32
+	Signature   *FunctionSignature
+	LocalsCount uint64
+	Code        []byte `json:"-"`
+}
+
+// Module is the description of a WASM module, obtained without compiling it
+type Module struct {
+	Types           []*FunctionSignature
+	Imports         []*Import
+	Exports         []*Export
+	Memories        []*Memory
+	Tables          []*Table
+	Globals         []*Global
+	ElementSegments []*ElementSegment
+	DataSegments    []*DataSegment
+	CustomSections  []*CustomSection
+	Functions       []*Function
+	HasStart        bool
+	StartFunction   uint32
+	FloatOpcodes    map[string]uint32
+}
+
+// UsesFloatOpcodes returns true if the code of the module contains floating
+// point instructions
+func (module *Module) UsesFloatOpcodes() bool {
+	return len(module.FloatOpcodes) > 0
+}
+
+// FloatOpcodeNames returns the sorted names of the floating point
+// instructions found in the code of the module
+func (module *Module) FloatOpcodeNames() []string {
+	names := make([]string, 0, len(module.FloatOpcodes))
+	for name := range module.FloatOpcodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// HasMemory returns true if the module exports a memory, mirroring the
+// Wasmer instances
+func (module *Module) HasMemory() bool {
+	for _, export := range module.Exports {
+		if export.Kind == ExternalMemory {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsFunctionImported returns true if the module imports the function from
+// any module
+func (module *Module) IsFunctionImported(name string) bool {
+	for _, imported := range module.Imports {
+		if imported.Kind == ExternalFunction && imported.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
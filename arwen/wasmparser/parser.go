@@ -0,0 +1,901 @@
+package wasmparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const wasmVersion = 1
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6D}
+
+const (
+	sectionCustom    = 0
+	sectionType      = 1
+	sectionImport    = 2
+	sectionFunction  = 3
+	sectionTable     = 4
+	sectionMemory    = 5
+	sectionGlobal    = 6
+	sectionExport    = 7
+	sectionStart     = 8
+	sectionElement   = 9
+	sectionCode      = 10
+	sectionData      = 11
+	sectionDataCount = 12
+)
+
+const (
+	opcodeEnd        = 0x0B
+	opcodeI32Const   = 0x41
+	opcodeI64Const   = 0x42
+	opcodeMiscPrefix = 0xFC
+)
+
+// ParseModule describes the WASM module without compiling it; the code of
+// the functions is decoded only to find the floating point instructions,
+// and it is kept for the interpreter to compile it
+func ParseModule(code []byte) (*Module, error) {
+	parser := &moduleParser{
+		reader: NewReader(code),
+		module: &Module{
+			Types:           make([]*FunctionSignature, 0),
+			Imports:         make([]*Import, 0),
+			Exports:         make([]*Export, 0),
+			Memories:        make([]*Memory, 0),
+			Tables:          make([]*Table, 0),
+			Globals:         make([]*Global, 0),
+			ElementSegments: make([]*ElementSegment, 0),
+			DataSegments:    make([]*DataSegment, 0),
+			CustomSections:  make([]*CustomSection, 0),
+			Functions:       make([]*Function, 0),
+			FloatOpcodes:    make(map[string]uint32),
+		},
+	}
+
+	err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.module, nil
+}
+
+type moduleParser struct {
+	reader         *Reader
+	module         *Module
+	functionTypes  []uint32
+	lastSectionID  byte
+	hasCodeSection bool
+}
+
+func (parser *moduleParser) parse() error {
+	magic, err := parser.reader.ReadBytes(uint32(len(wasmMagic)))
+	if err != nil || !bytes.Equal(magic, wasmMagic) {
+		return fmt.Errorf("missing WASM magic number")
+	}
+
+	versionBytes, err := parser.reader.ReadBytes(4)
+	if err != nil || binary.LittleEndian.Uint32(versionBytes) != wasmVersion {
+		return fmt.Errorf("unsupported WASM version")
+	}
+
+	for !parser.reader.IsAtEnd() {
+		err = parser.parseSection()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(parser.module.Functions) > 0 && !parser.hasCodeSection {
+		return fmt.Errorf("missing code section")
+	}
+
+	return parser.resolveExportSignatures()
+}
+
+func (parser *moduleParser) parseSection() error {
+	sectionID, err := parser.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	sectionSize, err := parser.reader.ReadU32()
+	if err != nil {
+		return err
+	}
+
+	sectionContents, err := parser.reader.ReadBytes(sectionSize)
+	if err != nil {
+		return fmt.Errorf("section %d: %w", sectionID, err)
+	}
+
+	if sectionID != sectionCustom && sectionID != sectionDataCount {
+		if sectionID <= parser.lastSectionID {
+			return fmt.Errorf("section %d out of order", sectionID)
+		}
+		parser.lastSectionID = sectionID
+	}
+
+	reader := NewReader(sectionContents)
+	switch sectionID {
+	case sectionCustom:
+		err = parser.parseCustomSection(reader, sectionSize)
+	case sectionType:
+		err = parser.parseTypeSection(reader)
+	case sectionImport:
+		err = parser.parseImportSection(reader)
+	case sectionFunction:
+		err = parser.parseFunctionSection(reader)
+	case sectionTable:
+		err = parser.parseTableSection(reader)
+	case sectionMemory:
+		err = parser.parseMemorySection(reader)
+	case sectionGlobal:
+		err = parser.parseGlobalSection(reader)
+	case sectionExport:
+		err = parser.parseExportSection(reader)
+	case sectionStart:
+		err = parser.parseStartSection(reader)
+	case sectionElement:
+		err = parser.parseElementSection(reader)
+	case sectionDataCount:
+		reader.position = len(sectionContents)
+	case sectionCode:
+		err = parser.parseCodeSection(reader)
+	case sectionData:
+		err = parser.parseDataSection(reader)
+	default:
+		return fmt.Errorf("unknown section %d", sectionID)
+	}
+	if err != nil {
+		return fmt.Errorf("section %d: %w", sectionID, err)
+	}
+
+	if !reader.IsAtEnd() {
+		return fmt.Errorf("section %d: unexpected trailing bytes", sectionID)
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseCustomSection(reader *Reader, sectionSize uint32) error {
+	name, err := reader.ReadName()
+	if err != nil {
+		return err
+	}
+
+	reader.position = len(reader.data)
+	parser.module.CustomSections = append(parser.module.CustomSections, &CustomSection{
+		Name: name,
+		Size: sectionSize,
+	})
+
+	return nil
+}
+
+func (parser *moduleParser) parseTypeSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	parser.module.Types = make([]*FunctionSignature, 0, count)
+	for i := uint32(0); i < count; i++ {
+		form, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if form != 0x60 {
+			return fmt.Errorf("invalid function type form 0x%02x", form)
+		}
+
+		params, err := reader.ReadValueTypes()
+		if err != nil {
+			return err
+		}
+
+		results, err := reader.ReadValueTypes()
+		if err != nil {
+			return err
+		}
+
+		parser.module.Types = append(parser.module.Types, &FunctionSignature{
+			Params:  params,
+			Results: results,
+		})
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseImportSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		moduleName, err := reader.ReadName()
+		if err != nil {
+			return err
+		}
+
+		name, err := reader.ReadName()
+		if err != nil {
+			return err
+		}
+
+		kind, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		imported := &Import{
+			Module: moduleName,
+			Name:   name,
+			Kind:   ExternalKind(kind),
+		}
+
+		switch imported.Kind {
+		case ExternalFunction:
+			typeIndex, err := reader.ReadU32()
+			if err != nil {
+				return err
+			}
+			imported.TypeIndex = typeIndex
+			imported.Signature, err = parser.signatureOfType(typeIndex)
+			if err != nil {
+				return err
+			}
+			parser.functionTypes = append(parser.functionTypes, typeIndex)
+		case ExternalTable:
+			table, err := reader.readTable()
+			if err != nil {
+				return err
+			}
+			table.Imported = true
+			parser.module.Tables = append(parser.module.Tables, table)
+		case ExternalMemory:
+			limits, err := reader.readLimits()
+			if err != nil {
+				return err
+			}
+			parser.module.Memories = append(parser.module.Memories, &Memory{Limits: *limits, Imported: true})
+		case ExternalGlobal:
+			global, err := reader.readGlobalType()
+			if err != nil {
+				return err
+			}
+			global.Imported = true
+			parser.module.Globals = append(parser.module.Globals, global)
+		default:
+			return fmt.Errorf("invalid import kind 0x%02x", kind)
+		}
+
+		parser.module.Imports = append(parser.module.Imports, imported)
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseFunctionSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		typeIndex, err := reader.ReadU32()
+		if err != nil {
+			return err
+		}
+		signature, err := parser.signatureOfType(typeIndex)
+		if err != nil {
+			return err
+		}
+		parser.functionTypes = append(parser.functionTypes, typeIndex)
+		parser.module.Functions = append(parser.module.Functions, &Function{
+			TypeIndex: typeIndex,
+			Signature: signature,
+		})
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseTableSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		table, err := reader.readTable()
+		if err != nil {
+			return err
+		}
+		parser.module.Tables = append(parser.module.Tables, table)
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseMemorySection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		limits, err := reader.readLimits()
+		if err != nil {
+			return err
+		}
+		parser.module.Memories = append(parser.module.Memories, &Memory{Limits: *limits})
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseGlobalSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		global, err := reader.readGlobalType()
+		if err != nil {
+			return err
+		}
+
+		global.Init, global.InitIsConstant, err = parser.readConstantExpression(reader)
+		if err != nil {
+			return err
+		}
+
+		parser.module.Globals = append(parser.module.Globals, global)
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseExportSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{})
+	for i := uint32(0); i < count; i++ {
+		name, err := reader.ReadName()
+		if err != nil {
+			return err
+		}
+		if _, duplicate := names[name]; duplicate {
+			return fmt.Errorf("duplicate export %s", name)
+		}
+		names[name] = struct{}{}
+
+		kind, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if kind > byte(ExternalGlobal) {
+			return fmt.Errorf("invalid export kind 0x%02x", kind)
+		}
+
+		index, err := reader.ReadU32()
+		if err != nil {
+			return err
+		}
+
+		parser.module.Exports = append(parser.module.Exports, &Export{
+			Name:  name,
+			Kind:  ExternalKind(kind),
+			Index: index,
+		})
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseStartSection(reader *Reader) error {
+	startFunction, err := reader.ReadU32()
+	if err != nil {
+		return err
+	}
+
+	parser.module.HasStart = true
+	parser.module.StartFunction = startFunction
+	return nil
+}
+
+// parseElementSection reads the element segments in any of their 8 forms:
+// the lowest bit of the flags tells the passive and declarative segments
+// apart from the active ones, the next bit tells whether an active segment
+// names its table or a passive one is declarative, and the highest bit
+// tells whether the functions are given by indices or by expressions
+func (parser *moduleParser) parseElementSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		flags, err := reader.ReadU32()
+		if err != nil {
+			return err
+		}
+		if flags > 7 {
+			return fmt.Errorf("invalid element segment flags %d", flags)
+		}
+
+		segment := &ElementSegment{
+			Passive:         flags&3 == 1,
+			Declarative:     flags&3 == 3,
+			UsesExpressions: flags&4 != 0,
+		}
+
+		if flags&1 == 0 {
+			if flags&2 != 0 {
+				segment.TableIndex, err = reader.ReadU32()
+				if err != nil {
+					return err
+				}
+			}
+			segment.Offset, segment.OffsetIsConstant, err = parser.readConstantExpression(reader)
+			if err != nil {
+				return err
+			}
+		}
+
+		if flags&3 != 0 {
+			err = parser.readElementType(reader, segment.UsesExpressions)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !segment.UsesExpressions {
+			segment.Functions, err = reader.ReadU32Vector()
+			if err != nil {
+				return err
+			}
+		} else {
+			err = parser.skipExpressions(reader)
+			if err != nil {
+				return err
+			}
+		}
+
+		parser.module.ElementSegments = append(parser.module.ElementSegments, segment)
+	}
+
+	return nil
+}
+
+// readElementType reads the type of the elements, which is a reference type
+// if they are given by expressions, or else the element kind of functions
+func (parser *moduleParser) readElementType(reader *Reader, usesExpressions bool) error {
+	if usesExpressions {
+		elementType, err := reader.ReadValueType()
+		if err != nil {
+			return err
+		}
+		if elementType != ValueTypeFuncRef && elementType != ValueTypeExternRef {
+			return fmt.Errorf("invalid element type %s", elementType)
+		}
+		return nil
+	}
+
+	elementKind, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	if elementKind != 0x00 {
+		return fmt.Errorf("invalid element kind 0x%02x", elementKind)
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) skipExpressions(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		_, _, err = parser.readConstantExpression(reader)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseCodeSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+	if int(count) != len(parser.module.Functions) {
+		return fmt.Errorf("%d function bodies for %d functions", count, len(parser.module.Functions))
+	}
+
+	for i := uint32(0); i < count; i++ {
+		bodySize, err := reader.ReadU32()
+		if err != nil {
+			return err
+		}
+
+		body, err := reader.ReadBytes(bodySize)
+		if err != nil {
+			return err
+		}
+
+		err = parser.parseFunctionBody(NewReader(body), parser.module.Functions[i])
+		if err != nil {
+			return fmt.Errorf("function body %d: %w", i, err)
+		}
+	}
+
+	parser.hasCodeSection = true
+	return nil
+}
+
+func (parser *moduleParser) parseFunctionBody(reader *Reader, function *Function) error {
+	localGroups, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < localGroups; i++ {
+		localsCount, err := reader.ReadU32()
+		if err != nil {
+			return err
+		}
+		function.LocalsCount += uint64(localsCount)
+		_, err = reader.ReadValueType()
+		if err != nil {
+			return err
+		}
+	}
+
+	function.Code = reader.data[reader.position:]
+	for !reader.IsAtEnd() {
+		_, err = parser.readInstruction(reader)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) parseDataSection(reader *Reader) error {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		flags, err := reader.ReadU32()
+		if err != nil {
+			return err
+		}
+
+		segment := &DataSegment{}
+		switch flags {
+		case 0:
+			segment.Offset, segment.OffsetIsConstant, err = parser.readConstantExpression(reader)
+		case 1:
+			segment.Passive = true
+		case 2:
+			segment.MemoryIndex, err = reader.ReadU32()
+			if err == nil {
+				segment.Offset, segment.OffsetIsConstant, err = parser.readConstantExpression(reader)
+			}
+		default:
+			return fmt.Errorf("invalid data segment flags %d", flags)
+		}
+		if err != nil {
+			return err
+		}
+
+		segment.Size, err = reader.ReadU32()
+		if err != nil {
+			return err
+		}
+
+		segment.Data, err = reader.ReadBytes(segment.Size)
+		if err != nil {
+			return err
+		}
+
+		parser.module.DataSegments = append(parser.module.DataSegments, segment)
+	}
+
+	return nil
+}
+
+// readConstantExpression reads an initializer expression up to its end
+// opcode, returning its value if it is a single integer constant
+func (parser *moduleParser) readConstantExpression(reader *Reader) (int64, bool, error) {
+	value := int64(0)
+	isConstant := false
+	instructions := 0
+
+	for {
+		start := reader.position
+		opcode, err := parser.readInstruction(reader)
+		if err != nil {
+			return 0, false, err
+		}
+		if opcode == opcodeEnd {
+			break
+		}
+
+		instructions++
+		isConstant = false
+		if instructions == 1 && (opcode == opcodeI32Const || opcode == opcodeI64Const) {
+			constReader := NewReader(reader.data[start+1 : reader.position])
+			value, err = constReader.ReadS64()
+			isConstant = err == nil
+		}
+	}
+
+	return value, isConstant && instructions == 1, nil
+}
+
+func (parser *moduleParser) resolveExportSignatures() error {
+	for _, export := range parser.module.Exports {
+		if export.Kind != ExternalFunction {
+			continue
+		}
+		if int(export.Index) >= len(parser.functionTypes) {
+			return fmt.Errorf("export %s of unknown function %d", export.Name, export.Index)
+		}
+
+		signature, err := parser.signatureOfType(parser.functionTypes[export.Index])
+		if err != nil {
+			return err
+		}
+		export.Signature = signature
+	}
+
+	return nil
+}
+
+func (parser *moduleParser) signatureOfType(typeIndex uint32) (*FunctionSignature, error) {
+	if int(typeIndex) >= len(parser.module.Types) {
+		return nil, fmt.Errorf("invalid type index %d", typeIndex)
+	}
+
+	return parser.module.Types[typeIndex], nil
+}
+
+// readInstruction reads an instruction together with its immediates,
+// counting it if it is a floating point instruction
+func (parser *moduleParser) readInstruction(reader *Reader) (byte, error) {
+	opcode, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if opcode == opcodeMiscPrefix {
+		return opcode, parser.readMiscInstruction(reader)
+	}
+
+	if name, isFloat := floatOpcodeNames[opcode]; isFloat {
+		parser.module.FloatOpcodes[name]++
+	}
+
+	switch {
+	case opcode <= 0x01, opcode == 0x05, opcode == opcodeEnd, opcode == 0x0F:
+		// unreachable, nop, else, end, return
+		return opcode, nil
+	case opcode >= 0x02 && opcode <= 0x04:
+		// block, loop, if
+		_, err = reader.ReadS64()
+	case opcode == 0x0C || opcode == 0x0D:
+		// br, br_if
+		_, err = reader.ReadU32()
+	case opcode == 0x0E:
+		// br_table
+		_, err = reader.ReadU32Vector()
+		if err == nil {
+			_, err = reader.ReadU32()
+		}
+	case opcode == 0x10:
+		// call
+		_, err = reader.ReadU32()
+	case opcode == 0x11:
+		// call_indirect
+		_, err = reader.ReadU32()
+		if err == nil {
+			_, err = reader.ReadU32()
+		}
+	case opcode == 0x1A || opcode == 0x1B:
+		// drop, select
+		return opcode, nil
+	case opcode == 0x1C:
+		// typed select
+		_, err = reader.ReadValueTypes()
+	case opcode >= 0x20 && opcode <= 0x26:
+		// local, global and table accessors
+		_, err = reader.ReadU32()
+	case opcode >= 0x28 && opcode <= 0x3E:
+		// loads and stores
+		_, err = reader.ReadU32()
+		if err == nil {
+			_, err = reader.ReadU32()
+		}
+	case opcode == 0x3F || opcode == 0x40:
+		// memory.size, memory.grow
+		_, err = reader.ReadByte()
+	case opcode == opcodeI32Const || opcode == opcodeI64Const:
+		_, err = reader.ReadS64()
+	case opcode == 0x43:
+		// f32.const
+		_, err = reader.ReadBytes(4)
+	case opcode == 0x44:
+		// f64.const
+		_, err = reader.ReadBytes(8)
+	case opcode >= 0x45 && opcode <= 0xC4:
+		// numeric instructions
+		return opcode, nil
+	case opcode == 0xD0:
+		// ref.null
+		_, err = reader.ReadByte()
+	case opcode == 0xD1:
+		// ref.is_null
+		return opcode, nil
+	case opcode == 0xD2:
+		// ref.func
+		_, err = reader.ReadU32()
+	default:
+		return opcode, fmt.Errorf("unknown opcode 0x%02x", opcode)
+	}
+
+	return opcode, err
+}
+
+func (parser *moduleParser) readMiscInstruction(reader *Reader) error {
+	subOpcode, err := reader.ReadU32()
+	if err != nil {
+		return err
+	}
+
+	if name, isFloat := floatMiscOpcodeNames[subOpcode]; isFloat {
+		parser.module.FloatOpcodes[name]++
+		return nil
+	}
+
+	switch {
+	case subOpcode == 8:
+		// memory.init
+		_, err = reader.ReadU32()
+		if err == nil {
+			_, err = reader.ReadByte()
+		}
+	case subOpcode == 9 || subOpcode == 13 || (subOpcode >= 15 && subOpcode <= 17):
+		// data.drop, elem.drop, table.grow, table.size, table.fill
+		_, err = reader.ReadU32()
+	case subOpcode == 10:
+		// memory.copy
+		_, err = reader.ReadBytes(2)
+	case subOpcode == 11:
+		// memory.fill
+		_, err = reader.ReadByte()
+	case subOpcode == 12 || subOpcode == 14:
+		// table.init, table.copy
+		_, err = reader.ReadU32()
+		if err == nil {
+			_, err = reader.ReadU32()
+		}
+	default:
+		return fmt.Errorf("unknown opcode 0xFC %d", subOpcode)
+	}
+
+	return err
+}
+
+// floatOpcodeNames maps the floating point instructions to their names in
+// executor/opcodes.go, which are also the names used by the gas schedule
+var floatOpcodeNames = map[byte]string{
+	// loads and stores
+	0x2A: "F32Load",
+	0x2B: "F64Load",
+	0x38: "F32Store",
+	0x39: "F64Store",
+
+	// constants
+	0x43: "F32Const",
+	0x44: "F64Const",
+
+	// comparisons
+	0x5B: "F32Eq",
+	0x5C: "F32Ne",
+	0x5D: "F32Lt",
+	0x5E: "F32Gt",
+	0x5F: "F32Le",
+	0x60: "F32Ge",
+	0x61: "F64Eq",
+	0x62: "F64Ne",
+	0x63: "F64Lt",
+	0x64: "F64Gt",
+	0x65: "F64Le",
+	0x66: "F64Ge",
+
+	// arithmetic
+	0x8B: "F32Abs",
+	0x8C: "F32Neg",
+	0x8D: "F32Ceil",
+	0x8E: "F32Floor",
+	0x8F: "F32Trunc",
+	0x90: "F32Nearest",
+	0x91: "F32Sqrt",
+	0x92: "F32Add",
+	0x93: "F32Sub",
+	0x94: "F32Mul",
+	0x95: "F32Div",
+	0x96: "F32Min",
+	0x97: "F32Max",
+	0x98: "F32Copysign",
+	0x99: "F64Abs",
+	0x9A: "F64Neg",
+	0x9B: "F64Ceil",
+	0x9C: "F64Floor",
+	0x9D: "F64Trunc",
+	0x9E: "F64Nearest",
+	0x9F: "F64Sqrt",
+	0xA0: "F64Add",
+	0xA1: "F64Sub",
+	0xA2: "F64Mul",
+	0xA3: "F64Div",
+	0xA4: "F64Min",
+	0xA5: "F64Max",
+	0xA6: "F64Copysign",
+
+	// conversions and reinterpretations
+	0xA8: "I32TruncF32S",
+	0xA9: "I32TruncF32U",
+	0xAA: "I32TruncF64S",
+	0xAB: "I32TruncF64U",
+	0xAE: "I64TruncF32S",
+	0xAF: "I64TruncF32U",
+	0xB0: "I64TruncF64S",
+	0xB1: "I64TruncF64U",
+	0xB2: "F32ConvertI32S",
+	0xB3: "F32ConvertI32U",
+	0xB4: "F32ConvertI64S",
+	0xB5: "F32ConvertI64U",
+	0xB6: "F32DemoteF64",
+	0xB7: "F64ConvertI32S",
+	0xB8: "F64ConvertI32U",
+	0xB9: "F64ConvertI64S",
+	0xBA: "F64ConvertI64U",
+	0xBB: "F64PromoteF32",
+	0xBC: "I32ReinterpretF32",
+	0xBD: "I64ReinterpretF64",
+	0xBE: "F32ReinterpretI32",
+	0xBF: "F64ReinterpretI64",
+}
+
+// floatMiscOpcodeNames maps the saturating conversions to integers, which
+// follow the 0xFC prefix, to their names in executor/opcodes.go
+var floatMiscOpcodeNames = map[uint32]string{
+	0: "I32TruncSatF32S",
+	1: "I32TruncSatF32U",
+	2: "I32TruncSatF64S",
+	3: "I32TruncSatF64U",
+	4: "I64TruncSatF32S",
+	5: "I64TruncSatF32U",
+	6: "I64TruncSatF64S",
+	7: "I64TruncSatF64U",
+}
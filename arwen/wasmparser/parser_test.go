@@ -0,0 +1,220 @@
+package wasmparser
+
+import (
+	"flag"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const contractsPath = "./../../test/contracts/"
+
+var fuzz = flag.Bool("fuzz", false, "Enable fuzz test")
+
+var seedFlag = flag.Int64("seed", 0, "Random seed, use it to replay fuzz scenarios")
+
+func getTestContractCode(name string) []byte {
+	code, _ := ioutil.ReadFile(filepath.Clean(contractsPath + name + "/output/" + name + ".wasm"))
+	return code
+}
+
+func TestParseModule_Malformed(t *testing.T) {
+	_, err := ParseModule([]byte("not a WASM module"))
+	require.NotNil(t, err)
+
+	code := getTestContractCode("counter")
+	_, err = ParseModule(code[:len(code)-3])
+	require.NotNil(t, err)
+}
+
+func TestParseModule_Counter(t *testing.T) {
+	module, err := ParseModule(getTestContractCode("counter"))
+	require.Nil(t, err)
+
+	require.Len(t, module.Imports, 3)
+	require.Equal(t, "env", module.Imports[0].Module)
+	require.Equal(t, "int64storageStore", module.Imports[0].Name)
+	require.Equal(t, ExternalFunction, module.Imports[0].Kind)
+	require.Equal(t, "(i32, i32, i64) -> (i32)", module.Imports[0].Signature.String())
+	require.Equal(t, module.Types[module.Imports[0].TypeIndex], module.Imports[0].Signature)
+
+	exportNames := make([]string, 0)
+	for _, export := range module.Exports {
+		exportNames = append(exportNames, export.Name)
+		if export.Kind == ExternalFunction {
+			require.True(t, export.Signature.IsVoid())
+		}
+	}
+	require.Equal(t, []string{"memory", "init", "increment", "decrement", "get"}, exportNames)
+
+	require.True(t, module.HasMemory())
+	require.Len(t, module.Memories, 1)
+	require.Equal(t, uint32(2), module.Memories[0].Min)
+	require.False(t, module.Memories[0].HasMax)
+
+	require.Len(t, module.Globals, 1)
+	require.Equal(t, ValueTypeI32, module.Globals[0].Type)
+	require.True(t, module.Globals[0].Mutable)
+	require.True(t, module.Globals[0].InitIsConstant)
+
+	require.Len(t, module.DataSegments, 1)
+	require.True(t, module.DataSegments[0].OffsetIsConstant)
+	require.Equal(t, int64(1024), module.DataSegments[0].Offset)
+	require.Equal(t, uint32(8), module.DataSegments[0].Size)
+	require.Len(t, module.DataSegments[0].Data, 8)
+
+	require.NotEmpty(t, module.Functions)
+	for _, function := range module.Functions {
+		require.NotNil(t, function.Signature)
+		require.Equal(t, module.Types[function.TypeIndex], function.Signature)
+		require.Equal(t, byte(opcodeEnd), function.Code[len(function.Code)-1])
+	}
+
+	require.False(t, module.UsesFloatOpcodes())
+}
+
+func TestParseModule_MemorylessWithCustomSection(t *testing.T) {
+	module, err := ParseModule(getTestContractCode("memoryless"))
+	require.Nil(t, err)
+
+	require.False(t, module.HasMemory())
+	require.Len(t, module.CustomSections, 1)
+	require.Equal(t, "name", module.CustomSections[0].Name)
+	require.Len(t, module.Exports, 1)
+	require.Equal(t, "(i32, i32) -> (i32)", module.Exports[0].Signature.String())
+}
+
+func TestParseModule_FloatOpcodes(t *testing.T) {
+	module, err := ParseModule(getTestContractCode("num-with-fp"))
+	require.Nil(t, err)
+	require.True(t, module.UsesFloatOpcodes())
+	require.Equal(t, []string{"F32Add", "F32Const", "F32ConvertI64U", "F32Load", "F32Mul", "F32Store"}, module.FloatOpcodeNames())
+	require.Equal(t, uint32(2), module.FloatOpcodes["F32Load"])
+}
+
+func TestParseModule_StartAndElementSegments(t *testing.T) {
+	code := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		// a void function type and a function of that type
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00,
+		0x03, 0x02, 0x01, 0x00,
+		// a table of 2 function references
+		0x04, 0x04, 0x01, 0x70, 0x00, 0x02,
+		// the function is the start function
+		0x08, 0x01, 0x00,
+		// an active segment, a passive one and one given by expressions
+		0x09, 0x13, 0x03,
+		0x00, 0x41, 0x01, 0x0b, 0x01, 0x00,
+		0x01, 0x00, 0x01, 0x00,
+		0x04, 0x41, 0x00, 0x0b, 0x01, 0xd2, 0x00, 0x0b,
+		0x0a, 0x04, 0x01, 0x02, 0x00, 0x0b,
+	}
+
+	module, err := ParseModule(code)
+	require.Nil(t, err)
+	require.True(t, module.HasStart)
+	require.Equal(t, uint32(0), module.StartFunction)
+
+	require.Len(t, module.ElementSegments, 3)
+	require.Equal(t, &ElementSegment{
+		Offset:           1,
+		OffsetIsConstant: true,
+		Functions:        []uint32{0},
+	}, module.ElementSegments[0])
+	require.Equal(t, &ElementSegment{
+		Passive:   true,
+		Functions: []uint32{0},
+	}, module.ElementSegments[1])
+	require.True(t, module.ElementSegments[2].UsesExpressions)
+	require.Empty(t, module.ElementSegments[2].Functions)
+}
+
+func TestReader_LEB128(t *testing.T) {
+	reader := NewReader([]byte{0xE5, 0x8E, 0x26})
+	value, err := reader.ReadU32()
+	require.Nil(t, err)
+	require.Equal(t, uint32(624485), value)
+
+	reader = NewReader([]byte{0xC0, 0xBB, 0x78})
+	signedValue, err := reader.ReadS64()
+	require.Nil(t, err)
+	require.Equal(t, int64(-123456), signedValue)
+
+	reader = NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x7F})
+	_, err = reader.ReadU32()
+	require.NotNil(t, err)
+
+	reader = NewReader([]byte{0x80})
+	_, err = reader.ReadU32()
+	require.NotNil(t, err)
+}
+
+func TestParseModule_HugeVectorCount(t *testing.T) {
+	// a type section declaring 2^32-1 function types, in 6 bytes
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x06, 0xff, 0xff, 0xff, 0xff, 0x0f, 0x60}
+	_, err := ParseModule(code)
+	require.NotNil(t, err)
+
+	reader := NewReader([]byte{0x03, 0x7F, 0x7F})
+	_, err = reader.ReadValueTypes()
+	require.NotNil(t, err)
+
+	reader = NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x0f, 0x01})
+	_, err = reader.ReadU32Vector()
+	require.NotNil(t, err)
+}
+
+func TestParseModule_Fuzz(t *testing.T) {
+	iterations := 2000
+	seed := int64(1)
+	if *fuzz {
+		iterations = 1000000
+		seed = time.Now().UnixNano()
+	}
+	if *seedFlag != 0 {
+		seed = *seedFlag
+	}
+	t.Logf("Random seed: %d", seed)
+	r := rand.New(rand.NewSource(seed))
+
+	codes := make([][]byte, 0)
+	for _, name := range []string{"counter", "num-with-fp", "misc", "memoryless", "answer"} {
+		codes = append(codes, getTestContractCode(name))
+	}
+
+	for i := 0; i < iterations; i++ {
+		code := mutateCode(r, codes[r.Intn(len(codes))])
+		module, err := ParseModule(code)
+		if err != nil {
+			require.Nil(t, module, "seed %d, iteration %d", seed, i)
+			continue
+		}
+		require.NotNil(t, module)
+	}
+}
+
+// mutateCode returns a copy of the code with a few random bytes changed,
+// inserted or removed, or truncated
+func mutateCode(r *rand.Rand, code []byte) []byte {
+	mutated := append(make([]byte, 0, len(code)+8), code...)
+	mutations := 1 + r.Intn(4)
+	for i := 0; i < mutations && len(mutated) > 0; i++ {
+		position := r.Intn(len(mutated))
+		switch r.Intn(4) {
+		case 0:
+			mutated[position] = byte(r.Intn(256))
+		case 1:
+			mutated[position] = 0xFF
+		case 2:
+			mutated = append(mutated[:position], append([]byte{byte(r.Intn(256))}, mutated[position:]...)...)
+		case 3:
+			mutated = mutated[:position]
+		}
+	}
+
+	return mutated
+}
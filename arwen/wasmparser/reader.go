@@ -0,0 +1,241 @@
+package wasmparser
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Reader decodes the values of the WASM binary format, such as the LEB128
+// integers and the vectors; the counts of the vectors are bounded by the
+// bytes left to read
+type Reader struct {
+	data     []byte
+	position int
+}
+
+// NewReader creates a reader of the given bytes
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// IsAtEnd returns true if all the bytes were read
+func (reader *Reader) IsAtEnd() bool {
+	return reader.position >= len(reader.data)
+}
+
+// PeekByte returns the next byte, without reading it
+func (reader *Reader) PeekByte() (byte, error) {
+	if reader.IsAtEnd() {
+		return 0, fmt.Errorf("unexpected end at offset %d", reader.position)
+	}
+
+	return reader.data[reader.position], nil
+}
+
+// ReadByte reads a single byte
+func (reader *Reader) ReadByte() (byte, error) {
+	if reader.IsAtEnd() {
+		return 0, fmt.Errorf("unexpected end at offset %d", reader.position)
+	}
+
+	value := reader.data[reader.position]
+	reader.position++
+	return value, nil
+}
+
+// ReadBytes reads the given number of bytes
+func (reader *Reader) ReadBytes(length uint32) ([]byte, error) {
+	end := uint64(reader.position) + uint64(length)
+	if end > uint64(len(reader.data)) {
+		return nil, fmt.Errorf("unexpected end at offset %d", reader.position)
+	}
+
+	value := reader.data[reader.position:end]
+	reader.position = int(end)
+	return value, nil
+}
+
+// ReadU32 reads an unsigned LEB128 integer of at most 32 bits
+func (reader *Reader) ReadU32() (uint32, error) {
+	result := uint64(0)
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		result |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			if result > 0xFFFFFFFF {
+				return 0, fmt.Errorf("integer too large at offset %d", reader.position)
+			}
+			return uint32(result), nil
+		}
+	}
+
+	return 0, fmt.Errorf("integer representation too long at offset %d", reader.position)
+}
+
+// ReadS64 reads a signed LEB128 integer of at most 64 bits
+func (reader *Reader) ReadS64() (int64, error) {
+	result := int64(0)
+	for shift := uint(0); shift < 70; shift += 7 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		result |= int64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			if shift+7 < 64 && b&0x40 != 0 {
+				result |= -1 << (shift + 7)
+			}
+			return result, nil
+		}
+	}
+
+	return 0, fmt.Errorf("integer representation too long at offset %d", reader.position)
+}
+
+// ReadCount reads the number of elements of a vector; each element takes at
+// least one byte, therefore a count above the number of bytes left to read
+// is rejected before anything is allocated for the elements
+func (reader *Reader) ReadCount() (uint32, error) {
+	count, err := reader.ReadU32()
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(count) > uint64(len(reader.data)-reader.position) {
+		return 0, fmt.Errorf("%d elements exceed the %d bytes left at offset %d", count, len(reader.data)-reader.position, reader.position)
+	}
+
+	return count, nil
+}
+
+// ReadName reads a UTF-8 name, prefixed by its length
+func (reader *Reader) ReadName() (string, error) {
+	length, err := reader.ReadU32()
+	if err != nil {
+		return "", err
+	}
+
+	name, err := reader.ReadBytes(length)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(name) {
+		return "", fmt.Errorf("invalid UTF-8 name at offset %d", reader.position)
+	}
+
+	return string(name), nil
+}
+
+// ReadValueType reads a value type, numeric or reference
+func (reader *Reader) ReadValueType() (ValueType, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	valueType := ValueType(b)
+	if !isValidValueType(valueType) {
+		return 0, fmt.Errorf("invalid value type 0x%02x", b)
+	}
+
+	return valueType, nil
+}
+
+// ReadValueTypes reads a vector of value types
+func (reader *Reader) ReadValueTypes() ([]ValueType, error) {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return nil, err
+	}
+
+	valueTypes := make([]ValueType, 0, count)
+	for i := uint32(0); i < count; i++ {
+		valueType, err := reader.ReadValueType()
+		if err != nil {
+			return nil, err
+		}
+		valueTypes = append(valueTypes, valueType)
+	}
+
+	return valueTypes, nil
+}
+
+// ReadU32Vector reads a vector of unsigned integers, such as indices
+func (reader *Reader) ReadU32Vector() ([]uint32, error) {
+	count, err := reader.ReadCount()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]uint32, 0, count)
+	for i := uint32(0); i < count; i++ {
+		value, err := reader.ReadU32()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (reader *Reader) readLimits() (*Limits, error) {
+	flags, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if flags > 1 {
+		return nil, fmt.Errorf("invalid limits flags 0x%02x", flags)
+	}
+
+	limits := &Limits{HasMax: flags == 1}
+	limits.Min, err = reader.ReadU32()
+	if err != nil {
+		return nil, err
+	}
+
+	if limits.HasMax {
+		limits.Max, err = reader.ReadU32()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return limits, nil
+}
+
+func (reader *Reader) readTable() (*Table, error) {
+	elementType, err := reader.ReadValueType()
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := reader.readLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{Limits: *limits, ElementType: elementType}, nil
+}
+
+func (reader *Reader) readGlobalType() (*Global, error) {
+	valueType, err := reader.ReadValueType()
+	if err != nil {
+		return nil, err
+	}
+
+	mutability, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if mutability > 1 {
+		return nil, fmt.Errorf("invalid global mutability 0x%02x", mutability)
+	}
+
+	return &Global{Type: valueType, Mutable: mutability == 1}, nil
+}
@@ -6,19 +6,43 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/compiledcache"
 )
 
+// compiledCodeCacheMaxEntries limits the compiled contracts kept in memory,
+// in addition to the ones persisted in the database
+const compiledCodeCacheMaxEntries = 100
+
 type database struct {
-	rootPath string
+	rootPath          string
+	compiledCodeCache arwen.CompiledCodeCache
 }
 
 // newDatabase creates a new debugging database (basically, a folder with JSON files)
 func newDatabase(rootPath string) *database {
 	db := &database{rootPath: rootPath}
 	db.initFolders()
+	db.initCompiledCodeCache()
 	return db
 }
 
+// initCompiledCodeCache persists the compiled contracts in the database, so
+// that they are not compiled again by each request
+func (db *database) initCompiledCodeCache() {
+	cache, err := compiledcache.NewCompiledCodeCache(compiledcache.CacheConfig{
+		MaxEntries: compiledCodeCacheMaxEntries,
+		Directory:  path.Join(db.rootPath, "compiled"),
+	})
+	if err != nil {
+		log.Error("database.initCompiledCodeCache", "err", err)
+		return
+	}
+
+	db.compiledCodeCache = cache
+}
+
 func (db *database) initFolders() {
 	err := os.MkdirAll(path.Join(db.rootPath, "worlds"), os.ModePerm)
 	if err != nil {
@@ -43,7 +67,7 @@ func (db *database) loadWorld(worldID string) (*world, error) {
 		}
 	}
 
-	world, err := newWorld(dataModel)
+	world, err := newWorld(dataModel, db.compiledCodeCache)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +142,33 @@ func (f *DebugFacade) QuerySmartContract(request QueryRequest) (*QueryResponse,
 	return response, err
 }
 
+// InspectSmartContract checks whether a smart contract can be deployed,
+// without instantiating it, and describes its WASM module
+func (f *DebugFacade) InspectSmartContract(request InspectRequest) (*InspectResponse, error) {
+	log.Debug("Debugf.InspectSmartContract()")
+
+	err := request.digest()
+	if err != nil {
+		return nil, err
+	}
+
+	database := f.loadDatabase(request.DatabasePath)
+	world, err := database.loadWorld(request.World)
+	if err != nil {
+		return nil, err
+	}
+
+	response := world.inspectSmartContract(request)
+
+	err = database.storeOutcome(request.Outcome, response)
+	if err != nil {
+		return nil, err
+	}
+
+	dumpOutcome(&response)
+	return response, err
+}
+
 // CreateAccount creates a test account
 func (f *DebugFacade) CreateAccount(request CreateAccountRequest) (*CreateAccountResponse, error) {
 	log.Debug("Debugf.CreateAccount()")
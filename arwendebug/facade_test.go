@@ -1,9 +1,11 @@
 package arwendebug
 
 import (
+	"errors"
 	"os"
 	"testing"
 
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
 	"github.com/stretchr/testify/require"
 )
@@ -11,6 +13,7 @@ import (
 var databasePath = "./testdata/db"
 var wasmCounterPath = "../test/contracts/counter/output/counter.wasm"
 var wasmErc20Path = "../test/contracts/erc20/output/erc20.wasm"
+var wasmMemorylessPath = "../test/contracts/memoryless/output/memoryless.wasm"
 
 func init() {
 	_ = os.RemoveAll(databasePath)
@@ -104,3 +107,16 @@ func TestFacade_UpgradeContract_CounterToERC20(t *testing.T) {
 	require.Equal(t, int64(90), balanceOfAlice)
 	require.Equal(t, int64(10), balanceOfBob)
 }
+
+func TestFacade_InspectContract(t *testing.T) {
+	context := newTestContext(t)
+
+	response := context.inspectContract(wasmCounterPath)
+	require.Nil(t, response.Error)
+	require.True(t, response.Report.Accepted)
+	require.True(t, response.Report.Module.HasMemory())
+
+	response = context.inspectContract(wasmMemorylessPath)
+	require.True(t, errors.Is(response.Error, arwen.ErrMemoryDeclarationMissing))
+	require.False(t, response.Report.Accepted)
+}
@@ -39,6 +39,7 @@ type ContractRequestBase struct {
 	ValueAsBigInt   *big.Int
 	GasPrice        uint64
 	GasLimit        uint64
+	Epoch           uint32
 }
 
 func (request *ContractRequestBase) digest() error {
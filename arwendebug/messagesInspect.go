@@ -0,0 +1,49 @@
+package arwendebug
+
+import (
+	"io/ioutil"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminspector"
+)
+
+// InspectRequest is a CLI / REST request message
+type InspectRequest struct {
+	RequestBase
+	CodeHex  string
+	Code     []byte
+	CodePath string
+	Epoch    uint32
+}
+
+func (request *InspectRequest) digest() error {
+	err := request.RequestBase.digest()
+	if err != nil {
+		return err
+	}
+
+	if len(request.CodeHex) > 0 {
+		request.Code, err = fromHex(request.CodeHex)
+		if err != nil {
+			return NewRequestErrorMessageInner("invalid contract code", err)
+		}
+	}
+
+	if len(request.CodePath) > 0 {
+		request.Code, err = ioutil.ReadFile(request.CodePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(request.Code) == 0 {
+		return NewRequestError("invalid contract code")
+	}
+
+	return nil
+}
+
+// InspectResponse is a CLI / REST response message
+type InspectResponse struct {
+	ResponseBase
+	Report *wasminspector.Report
+}
@@ -32,6 +32,7 @@ func (server *DebugServer) Start() error {
 	router.POST("/upgrade", server.handleUpgrade)
 	router.POST("/run", server.handleRun)
 	router.POST("/query", server.handleQuery)
+	router.POST("/inspect", server.handleInspect)
 
 	return router.Run(server.address)
 }
@@ -126,6 +127,24 @@ func (server *DebugServer) handleQuery(ginContext *gin.Context) {
 	returnOkResponse(ginContext, response)
 }
 
+func (server *DebugServer) handleInspect(ginContext *gin.Context) {
+	request := InspectRequest{}
+
+	err := ginContext.ShouldBindJSON(&request)
+	if err != nil {
+		returnBadRequest(ginContext, "handleInspect.ShouldBindJSON", err)
+		return
+	}
+
+	response, err := server.facade.InspectSmartContract(request)
+	if err != nil {
+		returnBadRequest(ginContext, "handleInspect.InspectSmartContract", err)
+		return
+	}
+
+	returnOkResponse(ginContext, response)
+}
+
 func returnBadRequest(context *gin.Context, errScope string, err error) {
 	context.JSON(http.StatusBadRequest, gin.H{
 		"error":        fmt.Sprintf("%T", err),
@@ -74,6 +74,22 @@ func (context *testContext) deployContract(codePath string, impersonated string,
 	return response
 }
 
+func (context *testContext) inspectContract(codePath string) *InspectResponse {
+	request := InspectRequest{
+		RequestBase: context.createRequestBase(),
+		CodePath:    codePath,
+	}
+
+	response, err := context.facade.InspectSmartContract(request)
+
+	t := context.t
+	require.Nil(t, err)
+	require.NotNil(t, response)
+	require.NotNil(t, response.Report)
+
+	return response
+}
+
 func (context *testContext) upgradeContract(contract string, codePath string, impersonated string, arguments ...string) *UpgradeResponse {
 	request := UpgradeRequest{
 		DeployRequest: DeployRequest{
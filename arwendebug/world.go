@@ -5,6 +5,7 @@ import (
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/host"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminspector"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
 	worldmock "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mock/world"
 	"github.com/ElrondNetwork/elrond-vm-common"
@@ -18,9 +19,10 @@ type worldDataModel struct {
 }
 
 type world struct {
-	id             string
-	blockchainHook *worldmock.MockWorld
-	vm             vmcommon.VMExecutionHandler
+	id                   string
+	blockchainHook       *worldmock.MockWorld
+	vm                   vmcommon.VMExecutionHandler
+	builtInFuncContainer vmcommon.BuiltInFunctionContainer
 }
 
 func newWorldDataModel(worldID string) *worldDataModel {
@@ -31,26 +33,30 @@ func newWorldDataModel(worldID string) *worldDataModel {
 }
 
 // newWorld creates a new debugging world
-func newWorld(dataModel *worldDataModel) (*world, error) {
+func newWorld(dataModel *worldDataModel, compiledCodeCache arwen.CompiledCodeCache) (*world, error) {
 	blockchainHook := worldmock.NewMockWorld()
 	blockchainHook.AcctMap = dataModel.Accounts
 
+	hostParameters := getHostParameters(blockchainHook)
+	hostParameters.CompiledCodeCache = compiledCodeCache
+
 	vm, err := host.NewArwenVM(
 		blockchainHook,
-		getHostParameters(),
+		hostParameters,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &world{
-		id:             dataModel.ID,
-		blockchainHook: blockchainHook,
-		vm:             vm,
+		id:                   dataModel.ID,
+		blockchainHook:       blockchainHook,
+		vm:                   vm,
+		builtInFuncContainer: hostParameters.BuiltInFuncContainer,
 	}, nil
 }
 
-func getHostParameters() *arwen.VMHostParameters {
+func getHostParameters(blockchainHook *worldmock.MockWorld) *arwen.VMHostParameters {
 	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
 	return &arwen.VMHostParameters{
 		VMType:                   []byte{5, 0},
@@ -59,10 +65,25 @@ func getHostParameters() *arwen.VMHostParameters {
 		ElrondProtectedKeyPrefix: []byte("ELROND"),
 		BuiltInFuncContainer:     builtInFunctions.NewBuiltInFunctionContainer(),
 		ESDTTransferParser:       esdtTransferParser,
+		EpochNotifier:            blockchainHook.EpochNotifier,
+		FeatureActivationEpochs:  worldmock.MakeFeatureActivationEpochsForTests(),
+	}
+}
+
+// setEpoch sets the epoch of the current block, which decides the features
+// enabled for the next execution
+func (w *world) setEpoch(epoch uint32) {
+	blockInfo := worldmock.BlockInfo{}
+	if w.blockchainHook.CurrentBlockInfo != nil {
+		blockInfo = *w.blockchainHook.CurrentBlockInfo
 	}
+
+	blockInfo.BlockEpoch = epoch
+	w.blockchainHook.SetCurrentBlockInfo(&blockInfo)
 }
 
 func (w *world) deploySmartContract(request DeployRequest) *DeployResponse {
+	w.setEpoch(request.Epoch)
 	input := w.prepareDeployInput(request)
 	log.Trace("w.deploySmartContract()", "input", prettyJson(input))
 
@@ -79,7 +100,22 @@ func (w *world) deploySmartContract(request DeployRequest) *DeployResponse {
 	return response
 }
 
+func (w *world) inspectSmartContract(request InspectRequest) *InspectResponse {
+	w.setEpoch(request.Epoch)
+	response := &InspectResponse{}
+
+	inspector, err := wasminspector.NewModuleInspectorForHost(w.vm.(arwen.VMHost), w.builtInFuncContainer)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	response.Report, response.Error = inspector.Inspect(request.Code)
+	return response
+}
+
 func (w *world) upgradeSmartContract(request UpgradeRequest) *UpgradeResponse {
+	w.setEpoch(request.Epoch)
 	input := w.prepareUpgradeInput(request)
 	log.Trace("w.upgradeSmartContract()", "input", prettyJson(input))
 
@@ -96,6 +132,7 @@ func (w *world) upgradeSmartContract(request UpgradeRequest) *UpgradeResponse {
 }
 
 func (w *world) runSmartContract(request RunRequest) *RunResponse {
+	w.setEpoch(request.Epoch)
 	input := w.prepareCallInput(request)
 	log.Trace("w.runSmartContract()", "input", prettyJson(input))
 
@@ -112,6 +149,7 @@ func (w *world) runSmartContract(request RunRequest) *RunResponse {
 }
 
 func (w *world) querySmartContract(request QueryRequest) *QueryResponse {
+	w.setEpoch(request.Epoch)
 	input := w.prepareCallInput(request.RunRequest)
 	log.Trace("w.querySmartContract()", "input", prettyJson(input))
 
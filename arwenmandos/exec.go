@@ -1,9 +1,12 @@
 package arwenmandos
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/compiledcache"
 	arwenHost "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/host"
 	gasSchedules "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwenmandos/gasSchedules"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
@@ -22,12 +25,43 @@ var log = logger.GetOrCreate("arwen/mandos")
 // TestVMType is the VM type argument we use in tests.
 var TestVMType = []byte{0, 0}
 
+// compiledCodeCacheMaxEntries limits the compiled contracts shared by the
+// executors of the process
+const compiledCodeCacheMaxEntries = 200
+
+// sharedCompiledCodeCache keeps the contracts compiled by all the executors
+// of the process, since the scenarios of a run deploy the same contracts over
+// and over
+var sharedCompiledCodeCache arwen.CompiledCodeCache
+var sharedCompiledCodeCacheOnce sync.Once
+
+func getSharedCompiledCodeCache() arwen.CompiledCodeCache {
+	sharedCompiledCodeCacheOnce.Do(func() {
+		cache, err := compiledcache.NewCompiledCodeCache(compiledcache.CacheConfig{
+			MaxEntries: compiledCodeCacheMaxEntries,
+		})
+		if err != nil {
+			log.Error("create compiled code cache", "error", err)
+			return
+		}
+		sharedCompiledCodeCache = cache
+	})
+
+	return sharedCompiledCodeCache
+}
+
+// TxStepObserver receives the output of each transaction step executed by
+// an ArwenTestExecutor, before the expected results are checked.
+type TxStepObserver func(step *mj.TxStep, output *vmi.VMOutput)
+
 // ArwenTestExecutor parses, interprets and executes both .test.json tests and .scen.json scenarios with Arwen.
 type ArwenTestExecutor struct {
 	World                   *worldhook.MockWorld
 	vm                      vmi.VMExecutionHandler
 	checkGas                bool
+	checkResults            bool
 	mandosGasScheduleLoaded bool
+	txStepObserver          TxStepObserver
 	fileResolver            fr.FileResolver
 	exprReconstructor       er.ExprReconstructor
 }
@@ -37,6 +71,29 @@ var _ mc.ScenarioExecutor = (*ArwenTestExecutor)(nil)
 
 // NewArwenTestExecutor prepares a new ArwenTestExecutor instance.
 func NewArwenTestExecutor() (*ArwenTestExecutor, error) {
+	return newArwenTestExecutor(getSharedCompiledCodeCache())
+}
+
+// NewArwenTestExecutorWithInstanceBuilder prepares a new ArwenTestExecutor
+// instance whose VM creates the contract instances with the given builder
+// instead of Wasmer. The executor does not share the compiled contracts of
+// the other executors, because they are compiled by Wasmer.
+func NewArwenTestExecutorWithInstanceBuilder(builder arwen.InstanceBuilder) (*ArwenTestExecutor, error) {
+	executor, err := newArwenTestExecutor(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	host, ok := executor.vm.(arwen.VMHost)
+	if !ok {
+		return nil, errors.New("the VM does not accept another instance builder")
+	}
+	host.Runtime().ReplaceInstanceBuilder(builder)
+
+	return executor, nil
+}
+
+func newArwenTestExecutor(compiledCodeCache arwen.CompiledCodeCache) (*ArwenTestExecutor, error) {
 	world := worldhook.NewMockWorld()
 
 	gasScheduleMap := config.MakeGasMapForTests()
@@ -54,6 +111,9 @@ func NewArwenTestExecutor() (*ArwenTestExecutor, error) {
 		BuiltInFuncContainer:     world.BuiltinFuncs.Container,
 		ElrondProtectedKeyPrefix: []byte(ElrondProtectedKeyPrefix),
 		ESDTTransferParser:       esdtTransferParser,
+		EpochNotifier:            world.EpochNotifier,
+		FeatureActivationEpochs:  worldhook.MakeFeatureActivationEpochsForTests(),
+		CompiledCodeCache:        compiledCodeCache,
 	})
 	if err != nil {
 		return nil, err
@@ -63,6 +123,7 @@ func NewArwenTestExecutor() (*ArwenTestExecutor, error) {
 		World:                   world,
 		vm:                      vm,
 		checkGas:                true,
+		checkResults:            true,
 		mandosGasScheduleLoaded: false,
 		fileResolver:            nil,
 		exprReconstructor:       er.ExprReconstructor{},
@@ -106,3 +167,23 @@ func (ae *ArwenTestExecutor) SetMandosGasSchedule(newGasSchedule mj.GasSchedule)
 	ae.vm.GasScheduleChange(gasSchedule)
 	return nil
 }
+
+// OverrideGasSchedule sets the gas costs of the VM, ignoring the gas schedules
+// declared by the scenarios executed afterwards
+func (ae *ArwenTestExecutor) OverrideGasSchedule(gasSchedule config.GasScheduleMap) {
+	ae.mandosGasScheduleLoaded = true
+	ae.vm.GasScheduleChange(gasSchedule)
+}
+
+// SetResultChecks enables or disables the checks of the expected transaction
+// results and account states; with the checks disabled, the scenarios only
+// execute their transactions
+func (ae *ArwenTestExecutor) SetResultChecks(enabled bool) {
+	ae.checkResults = enabled
+}
+
+// SetTxStepObserver sets the observer which receives the output of each
+// executed transaction step; a nil observer is allowed
+func (ae *ArwenTestExecutor) SetTxStepObserver(observer TxStepObserver) {
+	ae.txStepObserver = observer
+}
@@ -99,7 +99,7 @@ func (ae *ArwenTestExecutor) ExecuteSetStateStep(step *mj.SetStateStep) error {
 
 	// replace block info
 	ae.World.PreviousBlockInfo = convertBlockInfo(step.PreviousBlockInfo)
-	ae.World.CurrentBlockInfo = convertBlockInfo(step.CurrentBlockInfo)
+	ae.World.SetCurrentBlockInfo(convertBlockInfo(step.CurrentBlockInfo))
 	ae.World.Blockhashes = mj.JSONBytesFromStringValues(step.BlockHashes)
 
 	// append NewAddressMocks
@@ -125,8 +125,12 @@ func (ae *ArwenTestExecutor) ExecuteTxStep(step *mj.TxStep) (*vmi.VMOutput, erro
 		return nil, err
 	}
 
+	if ae.txStepObserver != nil {
+		ae.txStepObserver(step, output)
+	}
+
 	// check results
-	if step.ExpectedResult != nil {
+	if step.ExpectedResult != nil && ae.checkResults {
 		err = ae.checkTxResults(step.TxIdent, step.ExpectedResult, ae.checkGas, output)
 		if err != nil {
 			return nil, err
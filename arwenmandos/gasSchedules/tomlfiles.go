@@ -39,6 +39,7 @@ const (
     CompilePerByte    = 300
     AoTPreparePerByte = 50
     GetCode           = 100000
+    MemoryGrowPerPage = 10000
 
 [ElrondAPICost]
     GetSCAddress       = 100
@@ -79,6 +80,7 @@ const (
     GetReturnData        = 100
     GetNumReturnData     = 100
     GetReturnDataSize    = 100
+    SelfDestruct         = 100
 
 [EthAPICost]
     UseGas              = 100
@@ -679,6 +681,7 @@ const (
     CompilePerByte    = 300
     AoTPreparePerByte = 300
     GetCode           = 1000000
+    MemoryGrowPerPage = 100000
 
 [ElrondAPICost]
     GetSCAddress       = 100
@@ -719,6 +722,7 @@ const (
     GetReturnData        = 100
     GetNumReturnData     = 100
     GetReturnDataSize    = 100
+    SelfDestruct         = 100
 
 [EthAPICost]
     UseGas              = 100
@@ -1321,6 +1325,7 @@ const (
     CompilePerByte    = 300
     AoTPreparePerByte = 300
     GetCode           = 1000000
+    MemoryGrowPerPage = 100000
 
 [ElrondAPICost]
     GetSCAddress       = 100
@@ -1361,6 +1366,7 @@ const (
     GetReturnData        = 100
     GetNumReturnData     = 100
     GetReturnDataSize    = 100
+    SelfDestruct         = 100
 
 [EthAPICost]
     UseGas              = 100
@@ -19,6 +19,9 @@ func (ae *ArwenTestExecutor) ExecuteCheckStateStep(step *mj.CheckStateStep) erro
 	if len(step.Comment) > 0 {
 		log.Trace("CheckStateStep", "comment", step.Comment)
 	}
+	if !ae.checkResults {
+		return nil
+	}
 
 	return ae.checkAccounts(step.CheckAccounts)
 }
@@ -38,6 +41,13 @@ func (ae *ArwenTestExecutor) checkAccounts(checkAccounts *mj.CheckAccounts) erro
 
 	for _, expectedAcct := range checkAccounts.Accounts {
 		matchingAcct, isMatch := ae.World.AcctMap[string(expectedAcct.Address.Value)]
+		if expectedAcct.Deleted {
+			if isMatch {
+				return fmt.Errorf("account %s expected to be deleted, but found after running test",
+					expectedAcct.Address.Original)
+			}
+			continue
+		}
 		if !isMatch {
 			return fmt.Errorf("account %s expected but not found after running test",
 				expectedAcct.Address.Original)
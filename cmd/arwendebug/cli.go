@@ -77,6 +77,12 @@ func initializeCLI(facade *arwendebug.DebugFacade) *cli.App {
 		Destination: &args.GasPrice,
 	}
 
+	flagEpoch := cli.UintFlag{
+		Name:        "epoch",
+		Usage:       "the epoch of the block, which decides the features enabled",
+		Destination: &args.Epoch,
+	}
+
 	// For deploy / upgrade
 	flagCode := cli.StringFlag{
 		Name:        "code",
@@ -154,6 +160,7 @@ func initializeCLI(facade *arwendebug.DebugFacade) *cli.App {
 				flagValue,
 				flagGasLimit,
 				flagGasPrice,
+				flagEpoch,
 			},
 		},
 		{
@@ -176,6 +183,7 @@ func initializeCLI(facade *arwendebug.DebugFacade) *cli.App {
 				flagValue,
 				flagGasLimit,
 				flagGasPrice,
+				flagEpoch,
 			},
 		},
 		{
@@ -196,6 +204,7 @@ func initializeCLI(facade *arwendebug.DebugFacade) *cli.App {
 				flagValue,
 				flagGasLimit,
 				flagGasPrice,
+				flagEpoch,
 			},
 		},
 		{
@@ -214,6 +223,23 @@ func initializeCLI(facade *arwendebug.DebugFacade) *cli.App {
 				flagFunction,
 				flagArguments,
 				flagGasLimit,
+				flagEpoch,
+			},
+		},
+		{
+			Name:        "inspect",
+			Description: "check whether a smart contract can be deployed, without instantiating it",
+			Action: func(context *cli.Context) error {
+				_, err := facade.InspectSmartContract(args.toInspectRequest())
+				return err
+			},
+			Flags: []cli.Flag{
+				flagOutcome,
+				flagWorld,
+				flagDatabase,
+				flagCode,
+				flagCodePath,
+				flagEpoch,
 			},
 		},
 		{
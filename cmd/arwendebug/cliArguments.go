@@ -23,6 +23,7 @@ type cliArguments struct {
 	Value           string
 	GasLimit        uint64
 	GasPrice        uint64
+	Epoch           uint
 	// For blockchain-related action
 	AccountAddress string
 	AccountBalance string
@@ -52,6 +53,7 @@ func (args *cliArguments) populateContractRequestBase(request *arwendebug.Contra
 	request.Value = args.Value
 	request.GasLimit = args.GasLimit
 	request.GasPrice = args.GasPrice
+	request.Epoch = uint32(args.Epoch)
 }
 
 func (args *cliArguments) populateRequestBase(request *arwendebug.RequestBase) {
@@ -90,6 +92,16 @@ func (args *cliArguments) toQueryRequest() arwendebug.QueryRequest {
 	return *request
 }
 
+func (args *cliArguments) toInspectRequest() arwendebug.InspectRequest {
+	request := &arwendebug.InspectRequest{}
+	args.populateRequestBase(&request.RequestBase)
+
+	request.CodeHex = args.Code
+	request.CodePath = args.CodePath
+	request.Epoch = uint32(args.Epoch)
+	return *request
+}
+
 func (args *cliArguments) toCreateAccountRequest() arwendebug.CreateAccountRequest {
 	request := &arwendebug.CreateAccountRequest{}
 	args.populateRequestBase(&request.RequestBase)
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/differential"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminterpreter"
+	gasSchedules "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwenmandos/gasSchedules"
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
+)
+
+// Compares the runs of mandos scenarios with two VM backends, e.g.
+//
+//	arwendiff -second-backend interpreter test/ping-pong-egld/mandos
+//	arwendiff -first-gas v2 -second-gas v3 test/ping-pong-egld/mandos
+func main() {
+	firstBackendName := flag.String("first-backend", "wasmer", "backend of the first run: wasmer or interpreter")
+	secondBackendName := flag.String("second-backend", "wasmer", "backend of the second run: wasmer or interpreter")
+	firstGasName := flag.String("first-gas", "", "gas schedule of the first run: dummy, v1, v2 or v3; empty keeps the one of each scenario")
+	secondGasName := flag.String("second-gas", "", "gas schedule of the second run: dummy, v1, v2 or v3; empty keeps the one of each scenario")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Println("At least one argument expected - the path to a json scenario or to a directory of scenarios.")
+		os.Exit(1)
+	}
+
+	first, err := makeBackend(*firstBackendName, *firstGasName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	second, err := makeBackend(*secondBackendName, *secondGasName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	hasDifferences := false
+	for _, path := range flag.Args() {
+		reports, err := compare(path, first, second)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		for _, report := range reports {
+			if report.HasDifferences() {
+				hasDifferences = true
+				fmt.Print(report.String())
+			}
+		}
+	}
+
+	if hasDifferences {
+		os.Exit(1)
+	}
+	fmt.Println("No differences.")
+}
+
+func compare(path string, first differential.Backend, second differential.Backend) ([]*differential.Report, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		return differential.CompareScenariosInDirectory(path, first, second)
+	}
+
+	report, err := differential.CompareScenario(path, first, second)
+	if err != nil {
+		return nil, err
+	}
+	return []*differential.Report{report}, nil
+}
+
+func makeBackend(backendName string, gasName string) (differential.Backend, error) {
+	backend := differential.Backend{
+		Name: backendName,
+	}
+
+	switch backendName {
+	case "wasmer":
+	case "interpreter":
+		backend.InstanceBuilder = wasminterpreter.NewInstanceBuilder()
+	default:
+		return backend, fmt.Errorf("unknown backend: %s", backendName)
+	}
+
+	if len(gasName) == 0 {
+		return backend, nil
+	}
+
+	backend.Name += "/" + gasName
+	var err error
+	switch gasName {
+	case "dummy":
+		backend.GasSchedule = config.MakeGasMapForTests()
+	case "v1":
+		backend.GasSchedule, err = gasSchedules.LoadGasScheduleConfig(gasSchedules.GetV1())
+	case "v2":
+		backend.GasSchedule, err = gasSchedules.LoadGasScheduleConfig(gasSchedules.GetV2())
+	case "v3":
+		backend.GasSchedule, err = gasSchedules.LoadGasScheduleConfig(gasSchedules.GetV3())
+	default:
+		err = fmt.Errorf("unknown gas schedule: %s", gasName)
+	}
+
+	return backend, err
+}
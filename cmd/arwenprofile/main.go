@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen/wasminterpreter"
+	am "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwenmandos"
+	mc "github.com/ElrondNetwork/arwen-wasm-vm/v1_4/mandos-go/controller"
+)
+
+// Runs mandos scenarios with the interpreter, counting the executed opcodes
+// of the contracts, and writes them as folded stacks, e.g.
+//
+//	arwenprofile -gas -o pong.folded test/ping-pong-egld/mandos/ping-pong-call-pong.scen.json
+//	flamegraph.pl pong.folded > pong.svg
+//
+// Wasmer is not profiled: the scenarios run only with the interpreter, which
+// charges the gas one instruction at a time, while Wasmer charges a whole
+// block of instructions when entering it. The gas written with -gas is thus
+// the gas charged by Wasmer only for the executions which do not fail in the
+// middle of a block.
+func main() {
+	writeGas := flag.Bool("gas", false, "write the gas charged for the opcodes instead of their number")
+	outputPath := flag.String("o", "", "file to write the folded stacks to; empty writes them to the standard output")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Println("At least one argument expected - the path to a json scenario or to a directory of scenarios.")
+		os.Exit(1)
+	}
+
+	profiler := wasminterpreter.NewProfiler()
+	builder := wasminterpreter.NewInstanceBuilder()
+	builder.SetProfiler(profiler)
+
+	executor, err := am.NewArwenTestExecutorWithInstanceBuilder(builder)
+	if err != nil {
+		panic("Could not instantiate Arwen VM")
+	}
+
+	runner := mc.NewScenarioRunner(executor, mc.NewDefaultFileResolver())
+	for _, path := range flag.Args() {
+		err = runScenarios(runner, path)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	value := wasminterpreter.ProfileOpcodes
+	if *writeGas {
+		value = wasminterpreter.ProfileGas
+	}
+
+	var output io.Writer = os.Stdout
+	if len(*outputPath) > 0 {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	err = profiler.WriteFoldedStacks(output, value)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func runScenarios(runner *mc.ScenarioRunner, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		return runner.RunAllJSONScenariosInDirectory(path, "", ".scen.json", []string{})
+	}
+
+	return runner.RunSingleJSONScenario(path)
+}
@@ -11,6 +11,14 @@ const GasValueForTests = 1
 
 var AsyncCallbackGasLockForTests = uint64(100_000)
 
+// DefaultMemoryGrowPerPage is the cost of growing the memory of an instance by
+// one page, used by the gas schedules which predate MemoryGrowPerPage
+const DefaultMemoryGrowPerPage = 100_000
+
+// DefaultSelfDestruct is the cost of the selfDestruct EEI function, used by the
+// gas schedules which predate it; it is priced as a contract creation
+const DefaultSelfDestruct = 300_000
+
 // GasScheduleMap (alias) is the map for gas schedule
 type GasScheduleMap = map[string]map[string]uint64
 
@@ -21,6 +29,10 @@ func CreateGasConfig(gasMap GasScheduleMap) (*GasCost, error) {
 		return nil, err
 	}
 
+	if baseOps.MemoryGrowPerPage == 0 {
+		baseOps.MemoryGrowPerPage = DefaultMemoryGrowPerPage
+	}
+
 	err = checkForZeroUint64Fields(*baseOps)
 	if err != nil {
 		return nil, err
@@ -32,6 +44,10 @@ func CreateGasConfig(gasMap GasScheduleMap) (*GasCost, error) {
 		return nil, err
 	}
 
+	if elrondOps.SelfDestruct == 0 {
+		elrondOps.SelfDestruct = DefaultSelfDestruct
+	}
+
 	err = checkForZeroUint64Fields(*elrondOps)
 	if err != nil {
 		return nil, err
@@ -171,6 +187,7 @@ func FillGasMap_BaseOperationCosts(value uint64) map[string]uint64 {
 	gasMap["CompilePerByte"] = value
 	gasMap["AoTPreparePerByte"] = value
 	gasMap["GetCode"] = value
+	gasMap["MemoryGrowPerPage"] = value
 
 	return gasMap
 }
@@ -215,6 +232,7 @@ func FillGasMap_ElrondAPICosts(value, asyncCallbackGasLock uint64) map[string]ui
 	gasMap["GetReturnData"] = value
 	gasMap["GetNumReturnData"] = value
 	gasMap["GetReturnDataSize"] = value
+	gasMap["SelfDestruct"] = value
 
 	return gasMap
 }
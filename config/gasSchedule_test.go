@@ -74,3 +74,31 @@ func TestDecode_ZeroGasCostError(t *testing.T) {
 	err = checkForZeroUint64Fields(*wasmCosts)
 	assert.Error(t, err)
 }
+
+func TestCreateGasConfig_DefaultMemoryGrowPerPage(t *testing.T) {
+	gasMap := MakeGasMap(1, 1)
+	delete(gasMap["BaseOperationCost"], "MemoryGrowPerPage")
+
+	gasCost, err := CreateGasConfig(gasMap)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(DefaultMemoryGrowPerPage), gasCost.BaseOperationCost.MemoryGrowPerPage)
+
+	gasMap["BaseOperationCost"]["MemoryGrowPerPage"] = 7
+	gasCost, err = CreateGasConfig(gasMap)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), gasCost.BaseOperationCost.MemoryGrowPerPage)
+}
+
+func TestCreateGasConfig_DefaultSelfDestruct(t *testing.T) {
+	gasMap := MakeGasMap(1, 1)
+	delete(gasMap["ElrondAPICost"], "SelfDestruct")
+
+	gasCost, err := CreateGasConfig(gasMap)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(DefaultSelfDestruct), gasCost.ElrondAPICost.SelfDestruct)
+
+	gasMap["ElrondAPICost"]["SelfDestruct"] = 7
+	gasCost, err = CreateGasConfig(gasMap)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), gasCost.ElrondAPICost.SelfDestruct)
+}
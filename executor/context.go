@@ -0,0 +1,46 @@
+package executor
+
+import "unsafe"
+
+// instanceContextTag is added to the address of an instanceContext, so that
+// the pointer passed to the imported functions is odd; the Wasmer instance
+// contexts are word-aligned, hence their pointers are always even
+const instanceContextTag = 1
+
+// InstanceContextProvider gives the imported functions access to the context
+// data and to the memory of an instance which is not backed by Wasmer, such
+// as an interpreted one.
+type InstanceContextProvider interface {
+	GetContextDataPointer() unsafe.Pointer
+	GetInstanceCtxMemory() MemoryHandler
+}
+
+// instanceContext is the object pointed by the context pointers; it is not
+// zero-sized, so that the tagged pointer still points inside it and keeps
+// it alive
+type instanceContext struct {
+	provider InstanceContextProvider
+}
+
+// NewInstanceContextPointer returns the pointer which must be passed as the
+// first argument of the imported functions called by the given provider,
+// instead of a Wasmer instance context. It is told apart from the Wasmer
+// instance contexts by `IsInstanceContextPointer`, without any lookup.
+func NewInstanceContextPointer(provider InstanceContextProvider) unsafe.Pointer {
+	context := &instanceContext{provider: provider}
+	return unsafe.Pointer(uintptr(unsafe.Pointer(context)) + instanceContextTag)
+}
+
+// IsInstanceContextPointer returns true if the first argument of an imported
+// function was created by `NewInstanceContextPointer`, and false if it is a
+// Wasmer instance context.
+func IsInstanceContextPointer(pointer unsafe.Pointer) bool {
+	return uintptr(pointer)&instanceContextTag != 0
+}
+
+// IntoInstanceContextProvider returns the provider of a pointer created by
+// `NewInstanceContextPointer`.
+func IntoInstanceContextProvider(pointer unsafe.Pointer) InstanceContextProvider {
+	context := (*instanceContext)(unsafe.Pointer(uintptr(pointer) - instanceContextTag))
+	return context.provider
+}
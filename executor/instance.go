@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrFailedInstantiation = errors.New("could not create wasmer instance")
+
+var ErrInvalidBytecode = errors.New("invalid bytecode")
+
+var globalSettingsMutex sync.RWMutex
+var globalImports *Imports
+var globalOpcodeCosts [OPCODE_COUNT]uint32
+
+// ExportedFunctionError represents any kind of errors related to a
+// WebAssembly exported function. It is returned by `Instance`
+// functions only.
+type ExportedFunctionError struct {
+	functionName string
+	message      string
+}
+
+// ExportedFunctionSignature holds information about the input/output arities
+// of an exported function
+type ExportedFunctionSignature struct {
+	InputArity  int
+	OutputArity int
+}
+
+// NewExportedFunctionError constructs a new `ExportedFunctionError`,
+// where `functionName` is the name of the exported function, and
+// `message` is the error message. If the error message contains `%s`,
+// then this parameter will be replaced by `functionName`.
+func NewExportedFunctionError(functionName string, message string) *ExportedFunctionError {
+	return &ExportedFunctionError{functionName, message}
+}
+
+// ExportedFunctionError is an actual error. The `Error` function
+// returns the error message.
+func (error *ExportedFunctionError) Error() string {
+	return error.message
+}
+
+type ExportedFunctionCallback func(...interface{}) (Value, error)
+type ExportsMap map[string]ExportedFunctionCallback
+type ExportSignaturesMap map[string]*ExportedFunctionSignature
+
+type CompilationOptions struct {
+	GasLimit           uint64
+	UnmeteredLocals    uint64
+	OpcodeTrace        bool
+	Metering           bool
+	RuntimeBreakpoints bool
+}
+
+// SetImports records the imports bound to the new instances; it is called
+// by wasmer.SetImports, after giving them to Wasmer
+func SetImports(imports *Imports) {
+	globalSettingsMutex.Lock()
+	globalImports = imports
+	globalSettingsMutex.Unlock()
+}
+
+// GetImports returns the imports last given to `SetImports`, which are
+// bound to the new instances.
+func GetImports() *Imports {
+	globalSettingsMutex.RLock()
+	defer globalSettingsMutex.RUnlock()
+
+	return globalImports
+}
+
+// SetOpcodeCosts records the opcode costs charged by the new instances; it
+// is called by wasmer.SetOpcodeCosts, after giving them to Wasmer
+func SetOpcodeCosts(opcodeCosts *[OPCODE_COUNT]uint32) {
+	globalSettingsMutex.Lock()
+	globalOpcodeCosts = *opcodeCosts
+	globalSettingsMutex.Unlock()
+}
+
+// GetOpcodeCosts returns a copy of the opcode costs last given to
+// `SetOpcodeCosts`, which are charged by the new instances.
+func GetOpcodeCosts() *[OPCODE_COUNT]uint32 {
+	globalSettingsMutex.RLock()
+	defer globalSettingsMutex.RUnlock()
+
+	opcodeCosts := globalOpcodeCosts
+	return &opcodeCosts
+}
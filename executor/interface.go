@@ -0,0 +1,37 @@
+package executor
+
+// BreakpointNone and BreakpointOutOfGas are the breakpoint values which the
+// instances set by themselves; they have the values of arwen.BreakpointNone
+// and arwen.BreakpointOutOfGas
+const (
+	BreakpointNone     = 0
+	BreakpointOutOfGas = 4
+)
+
+// InstanceHandler defines the functionality of a WebAssembly instance,
+// such as a Wasmer instance or an interpreted one
+type InstanceHandler interface {
+	HasMemory() bool
+	SetContextData(data uintptr)
+	GetPointsUsed() uint64
+	SetPointsUsed(points uint64)
+	SetGasLimit(gasLimit uint64)
+	SetBreakpointValue(value uint64)
+	GetBreakpointValue() uint64
+	Cache() ([]byte, error)
+	Clean()
+	GetExports() ExportsMap
+	GetSignature(functionName string) (*ExportedFunctionSignature, bool)
+	GetData() uintptr
+	GetInstanceCtxMemory() MemoryHandler
+	GetMemory() MemoryHandler
+	IsFunctionImported(name string) bool
+}
+
+// MemoryHandler defines the functionality of the memory of an instance
+type MemoryHandler interface {
+	Length() uint32
+	Data() []byte
+	Grow(pages uint32) error
+	Destroy()
+}
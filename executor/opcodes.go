@@ -1,4 +1,7 @@
-package wasmer
+package executor
+
+// OPCODE_COUNT is the number of opcodes, the size of the opcode cost tables
+const OPCODE_COUNT = 448
 
 const (
 	OpcodeUnreachable = iota
@@ -450,3 +453,80 @@ const (
 	OpcodeI16x8RoundingAverageU
 	OpcodeLocalAllocate
 )
+
+// OpcodeCategory groups the opcodes by the kind of operation they perform
+type OpcodeCategory int
+
+const (
+	OpcodeCategoryControl OpcodeCategory = iota
+	OpcodeCategoryParametric
+	OpcodeCategoryVariable
+	OpcodeCategoryMemory
+	OpcodeCategoryConstant
+	OpcodeCategoryReference
+	OpcodeCategoryComparison
+	OpcodeCategoryNumeric
+	OpcodeCategoryConversion
+	OpcodeCategoryAtomic
+	OpcodeCategoryVector
+	OpcodeCategoryCount
+)
+
+var opcodeCategoryNames = [OpcodeCategoryCount]string{
+	"control",
+	"parametric",
+	"variable",
+	"memory",
+	"constant",
+	"reference",
+	"comparison",
+	"numeric",
+	"conversion",
+	"atomic",
+	"vector",
+}
+
+// opcodeCategoryRanges holds the first opcode of each run of consecutive
+// opcodes belonging to the same category, in the order of the opcodes
+var opcodeCategoryRanges = []struct {
+	first    int
+	category OpcodeCategory
+}{
+	{OpcodeUnreachable, OpcodeCategoryControl},
+	{OpcodeDrop, OpcodeCategoryParametric},
+	{OpcodeLocalGet, OpcodeCategoryVariable},
+	{OpcodeI32Load, OpcodeCategoryMemory},
+	{OpcodeI32Const, OpcodeCategoryConstant},
+	{OpcodeRefNull, OpcodeCategoryReference},
+	{OpcodeI32Eqz, OpcodeCategoryComparison},
+	{OpcodeI32Clz, OpcodeCategoryNumeric},
+	{OpcodeI32WrapI64, OpcodeCategoryConversion},
+	{OpcodeMemoryInit, OpcodeCategoryMemory},
+	{OpcodeTableInit, OpcodeCategoryReference},
+	{OpcodeAtomicNotify, OpcodeCategoryAtomic},
+	{OpcodeV128Load, OpcodeCategoryVector},
+	{OpcodeLocalAllocate, OpcodeCategoryVariable},
+}
+
+// GetOpcodeCategory returns the category of the given opcode; the
+// allocation of the locals of a function counts as a variable access
+func GetOpcodeCategory(opcode int) OpcodeCategory {
+	category := OpcodeCategoryControl
+	for _, categoryRange := range opcodeCategoryRanges {
+		if opcode < categoryRange.first {
+			break
+		}
+		category = categoryRange.category
+	}
+
+	return category
+}
+
+// String returns the name of the category
+func (category OpcodeCategory) String() string {
+	if category < 0 || category >= OpcodeCategoryCount {
+		return "unknown"
+	}
+
+	return opcodeCategoryNames[category]
+}
@@ -40,6 +40,7 @@ type CheckAccount struct {
 	CheckESDTData         []*CheckESDTData
 	IgnoreESDT            bool
 	MoreESDTTokensAllowed bool
+	Deleted               bool
 }
 
 // CheckStorageKeyValuePair checks a single entry in storage.
@@ -28,6 +28,7 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 		IgnoreESDT:            false,
 		MoreESDTTokensAllowed: false,
 		CheckESDTData:         nil,
+		Deleted:               false,
 	}
 	var err error
 
@@ -119,6 +120,12 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 			if err != nil {
 				return nil, fmt.Errorf("invalid asyncCallData: %w", err)
 			}
+		case "deleted":
+			deletedOJ, isBool := kvp.Value.(*oj.OJsonBool)
+			if !isBool {
+				return nil, errors.New("check account deleted flag is not boolean")
+			}
+			acct.Deleted = bool(*deletedOJ)
 
 		default:
 			return nil, fmt.Errorf("unknown account field: %s", kvp.Key)
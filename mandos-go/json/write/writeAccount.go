@@ -47,6 +47,12 @@ func checkAccountsToOJ(checkAccounts *mj.CheckAccounts) oj.OJsonObject {
 		if len(checkAccount.Comment) > 0 {
 			acctOJ.Put("comment", stringToOJ(checkAccount.Comment))
 		}
+		if checkAccount.Deleted {
+			ojTrue := oj.OJsonBool(true)
+			acctOJ.Put("deleted", &ojTrue)
+			acctsOJ.Put(bytesFromStringToString(checkAccount.Address), acctOJ)
+			continue
+		}
 		if !checkAccount.Nonce.IsUnspecified() {
 			acctOJ.Put("nonce", checkUint64ToOJ(checkAccount.Nonce))
 		}
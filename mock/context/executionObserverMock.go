@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+)
+
+var _ arwen.ExecutionObserver = (*ExecutionObserverMock)(nil)
+
+// ExecutionObserverMock records the execution events fired by the host, in
+// the order in which they were received
+type ExecutionObserverMock struct {
+	Events []*arwen.ExecutionEvent
+	Exited []bool
+}
+
+// NewExecutionObserverMock creates a new ExecutionObserverMock
+func NewExecutionObserverMock() *ExecutionObserverMock {
+	return &ExecutionObserverMock{
+		Events: make([]*arwen.ExecutionEvent, 0),
+		Exited: make([]bool, 0),
+	}
+}
+
+// OnContractEnter records a copy of the entry event
+func (observer *ExecutionObserverMock) OnContractEnter(event *arwen.ExecutionEvent) {
+	eventCopy := *event
+	observer.Events = append(observer.Events, &eventCopy)
+	observer.Exited = append(observer.Exited, false)
+}
+
+// OnContractExit records a copy of the exit event
+func (observer *ExecutionObserverMock) OnContractExit(event *arwen.ExecutionEvent) {
+	eventCopy := *event
+	observer.Events = append(observer.Events, &eventCopy)
+	observer.Exited = append(observer.Exited, true)
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (observer *ExecutionObserverMock) IsInterfaceNil() bool {
+	return observer == nil
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"errors"
+	"sync/atomic"
 	"testing"
 
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
@@ -85,14 +86,15 @@ func (instance *InstanceMock) SetGasLimit(gasLimit uint64) {
 	instance.GasLimit = gasLimit
 }
 
-// SetBreakpointValue mocked method
+// SetBreakpointValue mocked method; like the Wasmer instances, it may be
+// called by the execution watchdog while the instance is running
 func (instance *InstanceMock) SetBreakpointValue(value uint64) {
-	instance.BreakpointValue = value
+	atomic.StoreUint64(&instance.BreakpointValue, value)
 }
 
 // GetBreakpointValue mocked method
 func (instance *InstanceMock) GetBreakpointValue() uint64 {
-	return instance.BreakpointValue
+	return atomic.LoadUint64(&instance.BreakpointValue)
 }
 
 // Cache mocked method
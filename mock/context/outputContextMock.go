@@ -141,9 +141,19 @@ func (o *OutputContextMock) ClearReturnData() {
 	o.ReturnDataMock = make([][]byte, 0)
 }
 
+// GetLogs mocked method
+func (o *OutputContextMock) GetLogs() []*vmcommon.LogEntry {
+	return o.Logs
+}
+
 // SelfDestruct mocked method
-func (o *OutputContextMock) SelfDestruct(_ []byte, _ []byte) {
-	panic("not implemented")
+func (o *OutputContextMock) SelfDestruct(address []byte, _ []byte) error {
+	if o.Err != nil {
+		return o.Err
+	}
+
+	o.DeletedAccounts = append(o.DeletedAccounts, address)
+	return nil
 }
 
 // Finish mocked method
@@ -26,7 +26,7 @@ type OutputContextStub struct {
 	WriteLogCalled                    func(address []byte, topics [][]byte, data []byte)
 	TransferCalled                    func(destination []byte, sender []byte, gasLimit uint64, gasLocked uint64, value *big.Int, input []byte) error
 	TransferESDTCalled                func(destination []byte, sender []byte, transfers []*vmcommon.ESDTTransfer, input *vmcommon.ContractCallInput) (uint64, error)
-	SelfDestructCalled                func(address []byte, beneficiary []byte)
+	SelfDestructCalled                func(address []byte, beneficiary []byte) error
 	GetRefundCalled                   func() uint64
 	SetRefundCalled                   func(refund uint64)
 	ReturnCodeCalled                  func() vmcommon.ReturnCode
@@ -35,6 +35,7 @@ type OutputContextStub struct {
 	SetReturnMessageCalled            func(message string)
 	ReturnDataCalled                  func() [][]byte
 	ClearReturnDataCalled             func()
+	GetLogsCalled                     func() []*vmcommon.LogEntry
 	FinishCalled                      func(data []byte)
 	PrependFinishCalled               func(data []byte)
 	GetVMOutputCalled                 func() *vmcommon.VMOutput
@@ -166,10 +167,11 @@ func (o *OutputContextStub) TransferESDT(destination []byte, sender []byte, tran
 }
 
 // SelfDestruct mocked method
-func (o *OutputContextStub) SelfDestruct(address []byte, beneficiary []byte) {
+func (o *OutputContextStub) SelfDestruct(address []byte, beneficiary []byte) error {
 	if o.SelfDestructCalled != nil {
-		o.SelfDestructCalled(address, beneficiary)
+		return o.SelfDestructCalled(address, beneficiary)
 	}
+	return nil
 }
 
 // GetRefund mocked method
@@ -232,6 +234,14 @@ func (o *OutputContextStub) ClearReturnData() {
 	}
 }
 
+// GetLogs mocked method
+func (o *OutputContextStub) GetLogs() []*vmcommon.LogEntry {
+	if o.GetLogsCalled != nil {
+		return o.GetLogsCalled()
+	}
+	return []*vmcommon.LogEntry{}
+}
+
 // Finish mocked method
 func (o *OutputContextStub) Finish(data []byte) {
 	if o.FinishCalled != nil {
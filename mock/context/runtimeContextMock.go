@@ -18,6 +18,7 @@ type RuntimeContextMock struct {
 	CallFunction           string
 	VMType                 []byte
 	IsContractOnStack      bool
+	CurrentCallDepth       int
 	ReadOnlyFlag           bool
 	VerifyCode             bool
 	CurrentBreakpointValue arwen.BreakpointValue
@@ -31,6 +32,7 @@ type RuntimeContextMock struct {
 	FailBigIntAPI          bool
 	FailManagedBuffersAPI  bool
 	AsyncCallInfo          *arwen.AsyncCallInfo
+	CallbackClosure        []byte
 	RunningInstances       uint64
 	CurrentTxHash          []byte
 	OriginalTxHash         []byte
@@ -106,6 +108,24 @@ func (r *RuntimeContextMock) RunningInstancesCount() uint64 {
 func (r *RuntimeContextMock) SetMaxInstanceCount(uint64) {
 }
 
+// SetContractComplexityLimits mocked method
+func (r *RuntimeContextMock) SetContractComplexityLimits(_ arwen.ContractComplexityLimits) {
+}
+
+// SetMaxMemoryPages mocked method
+func (r *RuntimeContextMock) SetMaxMemoryPages(_ uint32) {
+}
+
+// MemoryPages mocked method
+func (r *RuntimeContextMock) MemoryPages() uint32 {
+	return 0
+}
+
+// ChargeMemoryGrowth mocked method
+func (r *RuntimeContextMock) ChargeMemoryGrowth(_ uint32) error {
+	return nil
+}
+
 // ClearInstanceStack mocked method
 func (r *RuntimeContextMock) ClearInstanceStack() {
 }
@@ -130,6 +150,11 @@ func (r *RuntimeContextMock) IsContractOnTheStack(_ []byte) bool {
 	return r.IsContractOnStack
 }
 
+// CallDepth mocked method
+func (r *RuntimeContextMock) CallDepth() int {
+	return r.CurrentCallDepth
+}
+
 // GetSCAddress mocked method
 func (r *RuntimeContextMock) GetSCAddress() []byte {
 	return r.SCAddress
@@ -326,11 +351,25 @@ func (r *RuntimeContextMock) GetAsyncContextInfo() *arwen.AsyncContextInfo {
 	return nil
 }
 
+// SetAsyncContextInfo mocked method
+func (r *RuntimeContextMock) SetAsyncContextInfo(_ *arwen.AsyncContextInfo) {
+}
+
 // GetAsyncContext mocked method
 func (r *RuntimeContextMock) GetAsyncContext(_ []byte) (*arwen.AsyncContext, error) {
 	return nil, nil
 }
 
+// SetCallbackClosure mocked method
+func (r *RuntimeContextMock) SetCallbackClosure(callbackClosure []byte) {
+	r.CallbackClosure = callbackClosure
+}
+
+// GetCallbackClosure mocked method
+func (r *RuntimeContextMock) GetCallbackClosure() []byte {
+	return r.CallbackClosure
+}
+
 // SetCustomCallFunction mocked method
 func (r *RuntimeContextMock) SetCustomCallFunction(_ string) {
 }
@@ -54,6 +54,8 @@ type RuntimeContextWrapper struct {
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	IsContractOnTheStackFunc func(address []byte) bool
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	CallDepthFunc func() int
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	GetAsyncCallInfoFunc func() *arwen.AsyncCallInfo
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	SetAsyncCallInfoFunc func(asyncCallInfo *arwen.AsyncCallInfo)
@@ -62,8 +64,14 @@ type RuntimeContextWrapper struct {
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	GetAsyncContextInfoFunc func() *arwen.AsyncContextInfo
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	SetAsyncContextInfoFunc func(asyncContextInfo *arwen.AsyncContextInfo)
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	GetAsyncContextFunc func(contextIdentifier []byte) (*arwen.AsyncContext, error)
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	SetCallbackClosureFunc func(callbackClosure []byte)
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	GetCallbackClosureFunc func() []byte
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	RunningInstancesCountFunc func() uint64
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	IsFunctionImportedFunc func(name string) bool
@@ -78,6 +86,14 @@ type RuntimeContextWrapper struct {
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	SetMaxInstanceCountFunc func(maxInstances uint64)
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	SetContractComplexityLimitsFunc func(limits arwen.ContractComplexityLimits)
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	SetMaxMemoryPagesFunc func(pages uint32)
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	MemoryPagesFunc func() uint32
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
+	ChargeMemoryGrowthFunc func(pagesBefore uint32) error
+	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	VerifyContractCodeFunc func() error
 	// function that will be called by the corresponding RuntimeContext function implementation (by default this will call the same wrapped context function)
 	GetInstanceFunc func() wasmer.InstanceHandler
@@ -217,6 +233,10 @@ func NewRuntimeContextWrapper(inputRuntimeContext *arwen.RuntimeContext) *Runtim
 		return runtimeWrapper.runtimeContext.IsContractOnTheStack(address)
 	}
 
+	runtimeWrapper.CallDepthFunc = func() int {
+		return runtimeWrapper.runtimeContext.CallDepth()
+	}
+
 	runtimeWrapper.GetAsyncCallInfoFunc = func() *arwen.AsyncCallInfo {
 		return runtimeWrapper.runtimeContext.GetAsyncCallInfo()
 	}
@@ -233,10 +253,22 @@ func NewRuntimeContextWrapper(inputRuntimeContext *arwen.RuntimeContext) *Runtim
 		return runtimeWrapper.runtimeContext.GetAsyncContextInfo()
 	}
 
+	runtimeWrapper.SetAsyncContextInfoFunc = func(asyncContextInfo *arwen.AsyncContextInfo) {
+		runtimeWrapper.runtimeContext.SetAsyncContextInfo(asyncContextInfo)
+	}
+
 	runtimeWrapper.GetAsyncContextFunc = func(contextIdentifier []byte) (*arwen.AsyncContext, error) {
 		return runtimeWrapper.runtimeContext.GetAsyncContext(contextIdentifier)
 	}
 
+	runtimeWrapper.SetCallbackClosureFunc = func(callbackClosure []byte) {
+		runtimeWrapper.runtimeContext.SetCallbackClosure(callbackClosure)
+	}
+
+	runtimeWrapper.GetCallbackClosureFunc = func() []byte {
+		return runtimeWrapper.runtimeContext.GetCallbackClosure()
+	}
+
 	runtimeWrapper.RunningInstancesCountFunc = func() uint64 {
 		return runtimeWrapper.runtimeContext.RunningInstancesCount()
 	}
@@ -265,6 +297,22 @@ func NewRuntimeContextWrapper(inputRuntimeContext *arwen.RuntimeContext) *Runtim
 		runtimeWrapper.runtimeContext.SetMaxInstanceCount(maxInstances)
 	}
 
+	runtimeWrapper.SetContractComplexityLimitsFunc = func(limits arwen.ContractComplexityLimits) {
+		runtimeWrapper.runtimeContext.SetContractComplexityLimits(limits)
+	}
+
+	runtimeWrapper.SetMaxMemoryPagesFunc = func(pages uint32) {
+		runtimeWrapper.runtimeContext.SetMaxMemoryPages(pages)
+	}
+
+	runtimeWrapper.MemoryPagesFunc = func() uint32 {
+		return runtimeWrapper.runtimeContext.MemoryPages()
+	}
+
+	runtimeWrapper.ChargeMemoryGrowthFunc = func(pagesBefore uint32) error {
+		return runtimeWrapper.runtimeContext.ChargeMemoryGrowth(pagesBefore)
+	}
+
 	runtimeWrapper.VerifyContractCodeFunc = func() error {
 		return runtimeWrapper.runtimeContext.VerifyContractCode()
 	}
@@ -469,6 +517,11 @@ func (contextWrapper *RuntimeContextWrapper) IsContractOnTheStack(address []byte
 	return contextWrapper.IsContractOnTheStackFunc(address)
 }
 
+// CallDepth calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) CallDepth() int {
+	return contextWrapper.CallDepthFunc()
+}
+
 // GetAsyncCallInfo calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
 func (contextWrapper *RuntimeContextWrapper) GetAsyncCallInfo() *arwen.AsyncCallInfo {
 	return contextWrapper.GetAsyncCallInfoFunc()
@@ -489,11 +542,26 @@ func (contextWrapper *RuntimeContextWrapper) GetAsyncContextInfo() *arwen.AsyncC
 	return contextWrapper.GetAsyncContextInfoFunc()
 }
 
+// SetAsyncContextInfo calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) SetAsyncContextInfo(asyncContextInfo *arwen.AsyncContextInfo) {
+	contextWrapper.SetAsyncContextInfoFunc(asyncContextInfo)
+}
+
 // GetAsyncContext calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
 func (contextWrapper *RuntimeContextWrapper) GetAsyncContext(contextIdentifier []byte) (*arwen.AsyncContext, error) {
 	return contextWrapper.GetAsyncContextFunc(contextIdentifier)
 }
 
+// SetCallbackClosure calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) SetCallbackClosure(callbackClosure []byte) {
+	contextWrapper.SetCallbackClosureFunc(callbackClosure)
+}
+
+// GetCallbackClosure calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) GetCallbackClosure() []byte {
+	return contextWrapper.GetCallbackClosureFunc()
+}
+
 // RunningInstancesCount calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
 func (contextWrapper *RuntimeContextWrapper) RunningInstancesCount() uint64 {
 	return contextWrapper.RunningInstancesCountFunc()
@@ -529,6 +597,26 @@ func (contextWrapper *RuntimeContextWrapper) SetMaxInstanceCount(maxInstances ui
 	contextWrapper.SetMaxInstanceCountFunc(maxInstances)
 }
 
+// SetContractComplexityLimits calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) SetContractComplexityLimits(limits arwen.ContractComplexityLimits) {
+	contextWrapper.SetContractComplexityLimitsFunc(limits)
+}
+
+// SetMaxMemoryPages calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) SetMaxMemoryPages(pages uint32) {
+	contextWrapper.SetMaxMemoryPagesFunc(pages)
+}
+
+// MemoryPages calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) MemoryPages() uint32 {
+	return contextWrapper.MemoryPagesFunc()
+}
+
+// ChargeMemoryGrowth calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
+func (contextWrapper *RuntimeContextWrapper) ChargeMemoryGrowth(pagesBefore uint32) error {
+	return contextWrapper.ChargeMemoryGrowthFunc(pagesBefore)
+}
+
 // VerifyContractCode calls corresponding xxxFunc function, that by default in turn calls the original method of the wrapped RuntimeContext
 func (contextWrapper *RuntimeContextWrapper) VerifyContractCode() error {
 	return contextWrapper.VerifyContractCodeFunc()
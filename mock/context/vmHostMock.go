@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"time"
+
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/crypto"
@@ -25,8 +27,9 @@ type VMHostMock struct {
 	StorageContext      arwen.StorageContext
 	ManagedTypesContext arwen.ManagedTypesContext
 
-	SCAPIMethods  *wasmer.Imports
-	IsBuiltinFunc bool
+	SCAPIMethods    *wasmer.Imports
+	IsBuiltinFunc   bool
+	CallTreeEnabled bool
 }
 
 // GetVersion mocked method
@@ -168,6 +171,79 @@ func (host *VMHostMock) GasScheduleChange(_ config.GasScheduleMap) {
 func (host *VMHostMock) SetBuiltInFunctionsContainer(_ vmcommon.BuiltInFunctionContainer) {
 }
 
+// SetExecutionObserver mocked method
+func (host *VMHostMock) SetExecutionObserver(_ arwen.ExecutionObserver) {
+}
+
+// SetCallTreeEnabled mocked method
+func (host *VMHostMock) SetCallTreeEnabled(enabled bool) {
+	host.CallTreeEnabled = enabled
+}
+
+// IsCallTreeEnabled mocked method
+func (host *VMHostMock) IsCallTreeEnabled() bool {
+	return host.CallTreeEnabled
+}
+
+// GetCallTree mocked method
+func (host *VMHostMock) GetCallTree() *arwen.CallTreeNode {
+	return nil
+}
+
+// SetAsyncGasReportEnabled mocked method
+func (host *VMHostMock) SetAsyncGasReportEnabled(_ bool) {
+}
+
+// GetAsyncGasReport mocked method
+func (host *VMHostMock) GetAsyncGasReport() *arwen.AsyncGasReport {
+	return nil
+}
+
+// SetExecutionTimeout mocked method
+func (host *VMHostMock) SetExecutionTimeout(_ time.Duration) {
+}
+
+// SetExecutionClock mocked method
+func (host *VMHostMock) SetExecutionClock(_ arwen.ExecutionClock) {
+}
+
+// SetReentrancyProtectedContracts mocked method
+func (host *VMHostMock) SetReentrancyProtectedContracts(_ [][]byte) {
+}
+
+// SetMaxCallDepth mocked method
+func (host *VMHostMock) SetMaxCallDepth(_ int) {
+}
+
+// IsFeatureEnabled mocked method
+func (host *VMHostMock) IsFeatureEnabled(_ arwen.FeatureFlag) bool {
+	return true
+}
+
+// RegisterEEIVersion mocked method
+func (host *VMHostMock) RegisterEEIVersion(_ arwen.EEIVersion, _ *wasmer.Imports) error {
+	return nil
+}
+
+// LockEEIVersion mocked method
+func (host *VMHostMock) LockEEIVersion(_ arwen.EEIVersion) (func(), error) {
+	return func() {}, nil
+}
+
+// SetPanicReportDirectory mocked method
+func (host *VMHostMock) SetPanicReportDirectory(_ string) {
+}
+
+// EstimateGas mocked method
+func (host *VMHostMock) EstimateGas(_ *vmcommon.ContractCallInput) (*arwen.GasEstimate, error) {
+	return nil, nil
+}
+
+// EstimateGasForCreate mocked method
+func (host *VMHostMock) EstimateGasForCreate(_ *vmcommon.ContractCreateInput) (*arwen.GasEstimate, error) {
+	return nil, nil
+}
+
 // IsInterfaceNil mocked method
 func (host *VMHostMock) IsInterfaceNil() bool {
 	return false
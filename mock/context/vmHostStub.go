@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"time"
+
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/config"
 	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/crypto"
@@ -43,7 +45,23 @@ type VMHostStub struct {
 	SetRuntimeContextCalled func(runtime arwen.RuntimeContext)
 	GetContextsCalled       func() (arwen.ManagedTypesContext, arwen.BlockchainContext, arwen.MeteringContext, arwen.OutputContext, arwen.RuntimeContext, arwen.StorageContext)
 
-	SetBuiltInFunctionsContainerCalled func(builtInFuncs vmcommon.BuiltInFunctionContainer)
+	SetBuiltInFunctionsContainerCalled    func(builtInFuncs vmcommon.BuiltInFunctionContainer)
+	SetExecutionObserverCalled            func(observer arwen.ExecutionObserver)
+	SetCallTreeEnabledCalled              func(enabled bool)
+	IsCallTreeEnabledCalled               func() bool
+	GetCallTreeCalled                     func() *arwen.CallTreeNode
+	SetAsyncGasReportEnabledCalled        func(enabled bool)
+	GetAsyncGasReportCalled               func() *arwen.AsyncGasReport
+	SetExecutionTimeoutCalled             func(timeout time.Duration)
+	SetExecutionClockCalled               func(clock arwen.ExecutionClock)
+	SetReentrancyProtectedContractsCalled func(addresses [][]byte)
+	SetMaxCallDepthCalled                 func(depth int)
+	IsFeatureEnabledCalled                func(flag arwen.FeatureFlag) bool
+	RegisterEEIVersionCalled              func(version arwen.EEIVersion, imports *wasmer.Imports) error
+	LockEEIVersionCalled                  func(version arwen.EEIVersion) (func(), error)
+	SetPanicReportDirectoryCalled         func(directory string)
+	EstimateGasCalled                     func(input *vmcommon.ContractCallInput) (*arwen.GasEstimate, error)
+	EstimateGasForCreateCalled            func(input *vmcommon.ContractCreateInput) (*arwen.GasEstimate, error)
 }
 
 // GetVersion mocked method
@@ -258,6 +276,126 @@ func (vhs *VMHostStub) SetBuiltInFunctionsContainer(builtInFuncs vmcommon.BuiltI
 	}
 }
 
+// SetExecutionObserver mocked method
+func (vhs *VMHostStub) SetExecutionObserver(observer arwen.ExecutionObserver) {
+	if vhs.SetExecutionObserverCalled != nil {
+		vhs.SetExecutionObserverCalled(observer)
+	}
+}
+
+// SetCallTreeEnabled mocked method
+func (vhs *VMHostStub) SetCallTreeEnabled(enabled bool) {
+	if vhs.SetCallTreeEnabledCalled != nil {
+		vhs.SetCallTreeEnabledCalled(enabled)
+	}
+}
+
+// IsCallTreeEnabled mocked method
+func (vhs *VMHostStub) IsCallTreeEnabled() bool {
+	if vhs.IsCallTreeEnabledCalled != nil {
+		return vhs.IsCallTreeEnabledCalled()
+	}
+	return false
+}
+
+// GetCallTree mocked method
+func (vhs *VMHostStub) GetCallTree() *arwen.CallTreeNode {
+	if vhs.GetCallTreeCalled != nil {
+		return vhs.GetCallTreeCalled()
+	}
+	return nil
+}
+
+// SetAsyncGasReportEnabled mocked method
+func (vhs *VMHostStub) SetAsyncGasReportEnabled(enabled bool) {
+	if vhs.SetAsyncGasReportEnabledCalled != nil {
+		vhs.SetAsyncGasReportEnabledCalled(enabled)
+	}
+}
+
+// GetAsyncGasReport mocked method
+func (vhs *VMHostStub) GetAsyncGasReport() *arwen.AsyncGasReport {
+	if vhs.GetAsyncGasReportCalled != nil {
+		return vhs.GetAsyncGasReportCalled()
+	}
+	return nil
+}
+
+// SetExecutionTimeout mocked method
+func (vhs *VMHostStub) SetExecutionTimeout(timeout time.Duration) {
+	if vhs.SetExecutionTimeoutCalled != nil {
+		vhs.SetExecutionTimeoutCalled(timeout)
+	}
+}
+
+// SetExecutionClock mocked method
+func (vhs *VMHostStub) SetExecutionClock(clock arwen.ExecutionClock) {
+	if vhs.SetExecutionClockCalled != nil {
+		vhs.SetExecutionClockCalled(clock)
+	}
+}
+
+// SetReentrancyProtectedContracts mocked method
+func (vhs *VMHostStub) SetReentrancyProtectedContracts(addresses [][]byte) {
+	if vhs.SetReentrancyProtectedContractsCalled != nil {
+		vhs.SetReentrancyProtectedContractsCalled(addresses)
+	}
+}
+
+// SetMaxCallDepth mocked method
+func (vhs *VMHostStub) SetMaxCallDepth(depth int) {
+	if vhs.SetMaxCallDepthCalled != nil {
+		vhs.SetMaxCallDepthCalled(depth)
+	}
+}
+
+// IsFeatureEnabled mocked method
+func (vhs *VMHostStub) IsFeatureEnabled(flag arwen.FeatureFlag) bool {
+	if vhs.IsFeatureEnabledCalled != nil {
+		return vhs.IsFeatureEnabledCalled(flag)
+	}
+	return true
+}
+
+// RegisterEEIVersion mocked method
+func (vhs *VMHostStub) RegisterEEIVersion(version arwen.EEIVersion, imports *wasmer.Imports) error {
+	if vhs.RegisterEEIVersionCalled != nil {
+		return vhs.RegisterEEIVersionCalled(version, imports)
+	}
+	return nil
+}
+
+// LockEEIVersion mocked method
+func (vhs *VMHostStub) LockEEIVersion(version arwen.EEIVersion) (func(), error) {
+	if vhs.LockEEIVersionCalled != nil {
+		return vhs.LockEEIVersionCalled(version)
+	}
+	return func() {}, nil
+}
+
+// SetPanicReportDirectory mocked method
+func (vhs *VMHostStub) SetPanicReportDirectory(directory string) {
+	if vhs.SetPanicReportDirectoryCalled != nil {
+		vhs.SetPanicReportDirectoryCalled(directory)
+	}
+}
+
+// EstimateGas mocked method
+func (vhs *VMHostStub) EstimateGas(input *vmcommon.ContractCallInput) (*arwen.GasEstimate, error) {
+	if vhs.EstimateGasCalled != nil {
+		return vhs.EstimateGasCalled(input)
+	}
+	return nil, nil
+}
+
+// EstimateGasForCreate mocked method
+func (vhs *VMHostStub) EstimateGasForCreate(input *vmcommon.ContractCreateInput) (*arwen.GasEstimate, error) {
+	if vhs.EstimateGasForCreateCalled != nil {
+		return vhs.EstimateGasForCreateCalled(input)
+	}
+	return nil, nil
+}
+
 // IsInterfaceNil mocked method
 func (vhs *VMHostStub) IsInterfaceNil() bool {
 	if vhs.IsInterfaceNilCalled != nil {
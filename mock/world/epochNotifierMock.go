@@ -0,0 +1,90 @@
+package worldmock
+
+import (
+	"sync"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/elrond-go-core/core/check"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+)
+
+// TestFeatureActivationEpoch is the epoch in which the test tools activate all
+// the feature flags, so that epoch 0 exercises the behaviour before activation
+const TestFeatureActivationEpoch = uint32(1)
+
+// MakeFeatureActivationEpochsForTests activates all the feature flags in
+// TestFeatureActivationEpoch
+func MakeFeatureActivationEpochsForTests() map[arwen.FeatureFlag]uint32 {
+	return MakeFeatureActivationEpochs(TestFeatureActivationEpoch)
+}
+
+// MakeFeatureActivationEpochs activates all the feature flags in the given
+// epoch; epoch 0 enables them regardless of the current epoch
+func MakeFeatureActivationEpochs(epoch uint32) map[arwen.FeatureFlag]uint32 {
+	activationEpochs := make(map[arwen.FeatureFlag]uint32, len(arwen.FeatureFlags))
+	for _, flag := range arwen.FeatureFlags {
+		activationEpochs[flag] = epoch
+	}
+
+	return activationEpochs
+}
+
+// EpochNotifierMock keeps the current epoch of the MockWorld and confirms
+// every change of it to the registered handlers
+type EpochNotifierMock struct {
+	mutex        sync.RWMutex
+	currentEpoch uint32
+	handlers     []vmcommon.EpochSubscriberHandler
+}
+
+// NewEpochNotifierMock creates a new EpochNotifierMock, starting at epoch 0
+func NewEpochNotifierMock() *EpochNotifierMock {
+	return &EpochNotifierMock{
+		handlers: make([]vmcommon.EpochSubscriberHandler, 0),
+	}
+}
+
+// RegisterNotifyHandler adds the handler and confirms the current epoch to it
+func (enm *EpochNotifierMock) RegisterNotifyHandler(handler vmcommon.EpochSubscriberHandler) {
+	if check.IfNil(handler) {
+		return
+	}
+
+	enm.mutex.Lock()
+	enm.handlers = append(enm.handlers, handler)
+	currentEpoch := enm.currentEpoch
+	enm.mutex.Unlock()
+
+	handler.EpochConfirmed(currentEpoch, 0)
+}
+
+// CheckEpoch sets the current epoch, notifying the handlers if it changed
+func (enm *EpochNotifierMock) CheckEpoch(epoch uint32, timestamp uint64) {
+	enm.mutex.Lock()
+	if enm.currentEpoch == epoch {
+		enm.mutex.Unlock()
+		return
+	}
+
+	enm.currentEpoch = epoch
+	handlers := make([]vmcommon.EpochSubscriberHandler, len(enm.handlers))
+	copy(handlers, enm.handlers)
+	enm.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler.EpochConfirmed(epoch, timestamp)
+	}
+}
+
+// CurrentEpoch returns the current epoch
+func (enm *EpochNotifierMock) CurrentEpoch() uint32 {
+	enm.mutex.RLock()
+	defer enm.mutex.RUnlock()
+
+	return enm.currentEpoch
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (enm *EpochNotifierMock) IsInterfaceNil() bool {
+	return enm == nil
+}
@@ -0,0 +1,343 @@
+package worldmock
+
+import (
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/arwen"
+	"github.com/ElrondNetwork/elrond-go-core/core/check"
+	"github.com/ElrondNetwork/elrond-go-core/data/vm"
+	vmcommon "github.com/ElrondNetwork/elrond-vm-common"
+	"github.com/ElrondNetwork/elrond-vm-common/parsers"
+)
+
+// SmartContractResult is an OutputTransfer destined to an account of another
+// shard, which the MultiShardWorld queues until a later block
+type SmartContractResult struct {
+	SenderAddress    []byte
+	RecipientAddress []byte
+	SenderShardID    uint32
+	RecipientShardID uint32
+	Value            *big.Int
+	GasPrice         uint64
+	GasLimit         uint64
+	GasLocked        uint64
+	Data             []byte
+	CallType         vm.CallType
+	OriginalTxHash   []byte
+	BlockNonce       uint64
+}
+
+// ExecutedSmartContractResult holds the outcome of executing a queued
+// SmartContractResult in its destination shard; the VMOutput is nil for the
+// results which only transfer value to an account without code
+type ExecutedSmartContractResult struct {
+	Result   *SmartContractResult
+	VMOutput *vmcommon.VMOutput
+	Err      error
+}
+
+// MultiShardWorld simulates a network of shards, each with its own MockWorld
+// and VM. The OutputTransfers produced by a transaction for the accounts of
+// other shards are queued as smart contract results, which are executed in the
+// shards of their recipients starting with the next block. Like the protocol,
+// it calls back the sender of a failed cross-shard async call, and adds the gas
+// locked by an async call to the gas of its callback.
+type MultiShardWorld struct {
+	Shards         map[uint32]*MockWorld
+	Executors      map[uint32]vmcommon.VMExecutionHandler
+	PendingResults []*SmartContractResult
+	BlockNonce     uint64
+}
+
+// NewMultiShardWorld creates a new MultiShardWorld without shards
+func NewMultiShardWorld() *MultiShardWorld {
+	return &MultiShardWorld{
+		Shards:         make(map[uint32]*MockWorld),
+		Executors:      make(map[uint32]vmcommon.VMExecutionHandler),
+		PendingResults: make([]*SmartContractResult, 0),
+	}
+}
+
+// AddShard adds the world of the shard given by its SelfShardID, together
+// with the VM which executes the transactions of the shard
+func (msw *MultiShardWorld) AddShard(world *MockWorld, executor vmcommon.VMExecutionHandler) error {
+	if world == nil {
+		return ErrNilWorldMock
+	}
+	if check.IfNil(executor) {
+		return ErrNilVMExecutionHandler
+	}
+
+	_, exists := msw.Shards[world.SelfShardID]
+	if exists {
+		return ErrShardAlreadyAdded
+	}
+
+	msw.Shards[world.SelfShardID] = world
+	msw.Executors[world.SelfShardID] = executor
+	world.multiShardWorld = msw
+
+	return nil
+}
+
+// ShardOfAddress returns the shard whose world holds the account
+func (msw *MultiShardWorld) ShardOfAddress(address []byte) (uint32, bool) {
+	for _, shardID := range msw.sortedShardIDs() {
+		if msw.Shards[shardID].AcctMap.GetAccount(address) != nil {
+			return shardID, true
+		}
+	}
+
+	return 0, false
+}
+
+// RunSmartContractCall executes the transaction in the shard of its recipient,
+// in the current block, then queues its cross-shard smart contract results
+func (msw *MultiShardWorld) RunSmartContractCall(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+	shardID, found := msw.ShardOfAddress(input.RecipientAddr)
+	if !found {
+		return nil, ErrUnknownShard
+	}
+
+	vmOutput, err := msw.Executors[shardID].RunSmartContractCall(input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = msw.processVMOutput(shardID, &input.VMInput, vmOutput, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return vmOutput, nil
+}
+
+// ProcessBlock moves all the shards to the next block, then executes the
+// smart contract results queued until the previous block; the results which
+// these executions produce are queued for the following blocks
+func (msw *MultiShardWorld) ProcessBlock() ([]*ExecutedSmartContractResult, error) {
+	msw.BlockNonce++
+	for _, world := range msw.Shards {
+		blockInfo := &BlockInfo{}
+		if world.CurrentBlockInfo != nil {
+			*blockInfo = *world.CurrentBlockInfo
+		}
+		blockInfo.BlockNonce = msw.BlockNonce
+		blockInfo.BlockRound = msw.BlockNonce
+		world.SetCurrentBlockInfo(blockInfo)
+	}
+
+	results := msw.PendingResults
+	msw.PendingResults = make([]*SmartContractResult, 0)
+
+	executedResults := make([]*ExecutedSmartContractResult, 0, len(results))
+	for _, result := range results {
+		vmOutput, err := msw.executeSmartContractResult(result)
+		executedResults = append(executedResults, &ExecutedSmartContractResult{
+			Result:   result,
+			VMOutput: vmOutput,
+			Err:      err,
+		})
+		if err != nil {
+			return executedResults, err
+		}
+	}
+
+	return executedResults, nil
+}
+
+// ProcessBlocksUntilIdle processes blocks until no smart contract result
+// remains queued, but no more than maxBlocks
+func (msw *MultiShardWorld) ProcessBlocksUntilIdle(maxBlocks int) ([]*ExecutedSmartContractResult, error) {
+	executedResults := make([]*ExecutedSmartContractResult, 0)
+	for block := 0; block < maxBlocks && len(msw.PendingResults) > 0; block++ {
+		blockResults, err := msw.ProcessBlock()
+		executedResults = append(executedResults, blockResults...)
+		if err != nil {
+			return executedResults, err
+		}
+	}
+
+	if len(msw.PendingResults) > 0 {
+		return executedResults, ErrSmartContractResultsPending
+	}
+
+	return executedResults, nil
+}
+
+func (msw *MultiShardWorld) executeSmartContractResult(result *SmartContractResult) (*vmcommon.VMOutput, error) {
+	world, ok := msw.Shards[result.RecipientShardID]
+	if !ok {
+		return nil, ErrUnknownShard
+	}
+
+	if !world.IsSmartContract(result.RecipientAddress) {
+		account := world.AcctMap.GetAccount(result.RecipientAddress)
+		if account == nil {
+			account = world.AcctMap.CreateAccount(result.RecipientAddress, world)
+			account.ShardID = result.RecipientShardID
+		}
+		account.Balance = big.NewInt(0).Add(account.Balance, copyValue(result.Value))
+		return nil, nil
+	}
+
+	data := string(result.Data)
+	if result.CallType == vm.AsynchronousCallBack && strings.HasPrefix(data, "@") {
+		// the data of a callback starts with the return code of the async
+		// call instead of a function name
+		data = arwen.CallbackFunctionName + data
+	}
+
+	function, arguments, err := parsers.NewCallArgsParser().ParseData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &vmcommon.ContractCallInput{
+		VMInput: vmcommon.VMInput{
+			CallerAddr:     result.SenderAddress,
+			Arguments:      arguments,
+			CallValue:      copyValue(result.Value),
+			CallType:       result.CallType,
+			GasPrice:       result.GasPrice,
+			GasProvided:    result.GasLimit,
+			GasLocked:      result.GasLocked,
+			OriginalTxHash: result.OriginalTxHash,
+		},
+		RecipientAddr: result.RecipientAddress,
+		Function:      function,
+	}
+
+	gasLockedForCallbacks := uint64(0)
+	switch result.CallType {
+	case vm.AsynchronousCall:
+		gasLockedForCallbacks = result.GasLocked
+	case vm.AsynchronousCallBack:
+		input.Function = arwen.CallbackFunctionName
+		input.GasProvided += result.GasLocked
+		input.GasLocked = 0
+	}
+
+	vmOutput, err := msw.Executors[result.RecipientShardID].RunSmartContractCall(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.CallType == vm.AsynchronousCall && vmOutput.ReturnCode != vmcommon.Ok {
+		msw.queueFailedAsyncCallCallback(result, vmOutput)
+		return vmOutput, nil
+	}
+
+	err = msw.processVMOutput(result.RecipientShardID, &input.VMInput, vmOutput, gasLockedForCallbacks)
+	if err != nil {
+		return nil, err
+	}
+
+	return vmOutput, nil
+}
+
+// processVMOutput applies the changes of the accounts held by the shard which
+// executed the transaction, then queues the OutputTransfers destined to the
+// accounts of the other shards
+func (msw *MultiShardWorld) processVMOutput(
+	shardID uint32,
+	input *vmcommon.VMInput,
+	vmOutput *vmcommon.VMOutput,
+	gasLockedForCallbacks uint64,
+) error {
+	if vmOutput.ReturnCode != vmcommon.Ok {
+		return nil
+	}
+
+	world := msw.Shards[shardID]
+	for _, address := range sortedOutputAccountAddresses(vmOutput) {
+		outputAccount := vmOutput.OutputAccounts[address]
+		recipientShardID, found := msw.ShardOfAddress(outputAccount.Address)
+		if !found || recipientShardID == shardID {
+			world.UpdateAccountFromOutputAccount(outputAccount)
+			continue
+		}
+
+		for _, transfer := range outputAccount.OutputTransfers {
+			result := &SmartContractResult{
+				SenderAddress:    transfer.SenderAddress,
+				RecipientAddress: outputAccount.Address,
+				SenderShardID:    shardID,
+				RecipientShardID: recipientShardID,
+				Value:            copyValue(transfer.Value),
+				GasPrice:         input.GasPrice,
+				GasLimit:         transfer.GasLimit,
+				GasLocked:        transfer.GasLocked,
+				Data:             transfer.Data,
+				CallType:         transfer.CallType,
+				OriginalTxHash:   input.OriginalTxHash,
+				BlockNonce:       msw.BlockNonce,
+			}
+			if transfer.CallType == vm.AsynchronousCallBack && result.GasLocked == 0 {
+				result.GasLocked = gasLockedForCallbacks
+			}
+			msw.PendingResults = append(msw.PendingResults, result)
+		}
+	}
+
+	for _, address := range vmOutput.DeletedAccounts {
+		world.AcctMap.DeleteAccount(address)
+	}
+
+	return nil
+}
+
+// queueFailedAsyncCallCallback queues the callback which the protocol sends
+// to the caller of a failed cross-shard async call, returning the call value
+func (msw *MultiShardWorld) queueFailedAsyncCallCallback(result *SmartContractResult, vmOutput *vmcommon.VMOutput) {
+	callbackData := "@" + hex.EncodeToString([]byte(vmOutput.ReturnCode.String())) +
+		"@" + hex.EncodeToString([]byte(vmOutput.ReturnMessage))
+
+	msw.PendingResults = append(msw.PendingResults, &SmartContractResult{
+		SenderAddress:    result.RecipientAddress,
+		RecipientAddress: result.SenderAddress,
+		SenderShardID:    result.RecipientShardID,
+		RecipientShardID: result.SenderShardID,
+		Value:            copyValue(result.Value),
+		GasPrice:         result.GasPrice,
+		GasLimit:         vmOutput.GasRemaining,
+		GasLocked:        result.GasLocked,
+		Data:             []byte(callbackData),
+		CallType:         vm.AsynchronousCallBack,
+		OriginalTxHash:   result.OriginalTxHash,
+		BlockNonce:       msw.BlockNonce,
+	})
+}
+
+func (msw *MultiShardWorld) sortedShardIDs() []uint32 {
+	shardIDs := make([]uint32, 0, len(msw.Shards))
+	for shardID := range msw.Shards {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool {
+		return shardIDs[i] < shardIDs[j]
+	})
+
+	return shardIDs
+}
+
+func sortedOutputAccountAddresses(vmOutput *vmcommon.VMOutput) []string {
+	addresses := make([]string, 0, len(vmOutput.OutputAccounts))
+	for address := range vmOutput.OutputAccounts {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
+
+func copyValue(value *big.Int) *big.Int {
+	if value == nil {
+		return big.NewInt(0)
+	}
+
+	return big.NewInt(0).Set(value)
+}
@@ -227,14 +227,23 @@ func (b *MockWorld) GetCode(acc vmcommon.UserAccountHandler) []byte {
 	return account.Code
 }
 
-// GetShardOfAddress -
+// GetShardOfAddress returns the shard of the account; the accounts held by
+// the other shards of a MultiShardWorld are looked up there, while unknown
+// accounts are considered to be in shard 0
 func (b *MockWorld) GetShardOfAddress(address []byte) uint32 {
 	account := b.AcctMap.GetAccount(address)
-	if account == nil {
-		return 0
+	if account != nil {
+		return account.ShardID
+	}
+
+	if b.multiShardWorld != nil {
+		shardID, found := b.multiShardWorld.ShardOfAddress(address)
+		if found {
+			return shardID
+		}
 	}
 
-	return account.ShardID
+	return 0
 }
 
 // IsSmartContract -
@@ -37,6 +37,9 @@ type MockWorld struct {
 	LastCreatedContractAddress []byte
 	CompiledCode               map[string][]byte
 	BuiltinFuncs               *BuiltinFunctionsWrapper
+	EpochNotifier              *EpochNotifierMock
+
+	multiShardWorld *MultiShardWorld
 }
 
 // NewMockWorld creates a new MockWorld instance
@@ -52,6 +55,7 @@ func NewMockWorld() *MockWorld {
 		NewAddressMocks:   nil,
 		CompiledCode:      make(map[string][]byte),
 		BuiltinFuncs:      nil,
+		EpochNotifier:     NewEpochNotifierMock(),
 	}
 	world.AccountsAdapter = NewMockAccountsAdapter(world)
 
@@ -81,6 +85,21 @@ func (b *MockWorld) Clear() {
 	b.CompiledCode = make(map[string][]byte)
 }
 
+// SetCurrentBlockInfo replaces the current block info and confirms its epoch
+// to the handlers registered on the EpochNotifier; a nil block info sets the
+// epoch to 0.
+func (b *MockWorld) SetCurrentBlockInfo(blockInfo *BlockInfo) {
+	b.CurrentBlockInfo = blockInfo
+
+	epoch := uint32(0)
+	timestamp := uint64(0)
+	if blockInfo != nil {
+		epoch = blockInfo.BlockEpoch
+		timestamp = blockInfo.BlockTimestamp
+	}
+	b.EpochNotifier.CheckEpoch(epoch, timestamp)
+}
+
 // SetCurrentBlockHash -
 func (b *MockWorld) SetCurrentBlockHash(blockHash []byte) {
 	if b.CurrentBlockInfo == nil {
@@ -103,7 +122,7 @@ func (b *MockWorld) NumberOfShards() uint32 {
 
 // ComputeId -
 func (b *MockWorld) ComputeId(address []byte) uint32 {
-	return b.AcctMap.GetAccount(address).ShardID
+	return b.GetShardOfAddress(address)
 }
 
 // SelfId -
@@ -113,9 +132,7 @@ func (b *MockWorld) SelfId() uint32 {
 
 // SameShard -
 func (b *MockWorld) SameShard(firstAddress []byte, secondAddress []byte) bool {
-	firstAccount := b.AcctMap.GetAccount(firstAddress)
-	secondAccount := b.AcctMap.GetAccount(secondAddress)
-	return firstAccount.ShardID == secondAccount.ShardID
+	return b.GetShardOfAddress(firstAddress) == b.GetShardOfAddress(secondAddress)
 }
 
 // CommunicationIdentifier -
@@ -8,3 +8,15 @@ var ErrInsufficientFunds = errors.New("insufficient funds")
 
 // ErrNilWorldMock signals that the WorldMock is nil but shouldn't be.
 var ErrNilWorldMock = errors.New("nil worldmock")
+
+// ErrNilVMExecutionHandler signals that a shard was added to a MultiShardWorld without a VM to execute its transactions.
+var ErrNilVMExecutionHandler = errors.New("nil vm execution handler")
+
+// ErrShardAlreadyAdded signals that a MultiShardWorld already holds a world for the shard.
+var ErrShardAlreadyAdded = errors.New("shard already added")
+
+// ErrUnknownShard signals that a MultiShardWorld holds no world for the shard of an address.
+var ErrUnknownShard = errors.New("unknown shard")
+
+// ErrSmartContractResultsPending signals that smart contract results were still queued after the maximum number of blocks.
+var ErrSmartContractResultsPending = errors.New("smart contract results still pending")
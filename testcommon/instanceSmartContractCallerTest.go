@@ -45,6 +45,7 @@ func (mockSC *InstanceTestSmartContract) WithCode(code []byte) *InstanceTestSmar
 type InstancesTestTemplate struct {
 	testTemplateConfig
 	contracts     []*InstanceTestSmartContract
+	features      []arwen.FeatureFlag
 	setup         func(arwen.VMHost, *contextmock.BlockchainHookStub)
 	assertResults func(arwen.VMHost, *contextmock.BlockchainHookStub, *VMOutputVerifier)
 }
@@ -72,6 +73,13 @@ func (callerTest *InstancesTestTemplate) WithInput(input *vmcommon.ContractCallI
 	return callerTest
 }
 
+// WithFeatures enables the given features for the contract call test, which
+// otherwise runs with all the features disabled
+func (callerTest *InstancesTestTemplate) WithFeatures(flags ...arwen.FeatureFlag) *InstancesTestTemplate {
+	callerTest.features = append(callerTest.features, flags...)
+	return callerTest
+}
+
 // WithSetup provides the setup function to be used by the contract call test
 func (callerTest *InstancesTestTemplate) WithSetup(setup func(arwen.VMHost, *contextmock.BlockchainHookStub)) *InstancesTestTemplate {
 	callerTest.setup = setup
@@ -86,7 +94,7 @@ func (callerTest *InstancesTestTemplate) AndAssertResults(assertResults func(arw
 
 func runTestWithInstances(callerTest *InstancesTestTemplate) {
 
-	host, blockchainHookStub := defaultTestArwenForContracts(callerTest.t, callerTest.contracts)
+	host, blockchainHookStub := defaultTestArwenForContracts(callerTest.t, callerTest.contracts, callerTest.features)
 
 	callerTest.setup(host, blockchainHookStub)
 
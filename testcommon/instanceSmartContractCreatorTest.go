@@ -13,6 +13,7 @@ type TestCreateTemplateConfig struct {
 	t             *testing.T
 	address       []byte
 	input         *vmcommon.ContractCreateInput
+	features      []arwen.FeatureFlag
 	setup         func(arwen.VMHost, *contextmock.BlockchainHookStub)
 	assertResults func(*contextmock.BlockchainHookStub, *VMOutputVerifier)
 }
@@ -37,6 +38,13 @@ func (callerTest *TestCreateTemplateConfig) WithAddress(address []byte) *TestCre
 	return callerTest
 }
 
+// WithFeatures enables the given features for a TestCreateTemplateConfig, which
+// otherwise runs with all the features disabled
+func (callerTest *TestCreateTemplateConfig) WithFeatures(flags ...arwen.FeatureFlag) *TestCreateTemplateConfig {
+	callerTest.features = append(callerTest.features, flags...)
+	return callerTest
+}
+
 // WithSetup provides the setup function for a TestCreateTemplateConfig
 func (callerTest *TestCreateTemplateConfig) WithSetup(setup func(arwen.VMHost, *contextmock.BlockchainHookStub)) *TestCreateTemplateConfig {
 	callerTest.setup = setup
@@ -51,7 +59,7 @@ func (callerTest *TestCreateTemplateConfig) AndAssertResults(assertResults func(
 
 func (callerTest *TestCreateTemplateConfig) runTest() {
 
-	host, stubBlockchainHook := DefaultTestArwenForDeployment(callerTest.t, 24, callerTest.address)
+	host, stubBlockchainHook := DefaultTestArwenForDeployment(callerTest.t, 24, callerTest.address, callerTest.features...)
 	callerTest.setup(host, stubBlockchainHook)
 
 	vmOutput, err := host.RunSmartContractCreate(callerTest.input)
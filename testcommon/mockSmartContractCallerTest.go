@@ -19,6 +19,7 @@ type testTemplateConfig struct {
 type MockInstancesTestTemplate struct {
 	testTemplateConfig
 	contracts     *[]MockTestSmartContract
+	features      []arwen.FeatureFlag
 	setup         func(arwen.VMHost, *worldmock.MockWorld)
 	assertResults func(*worldmock.MockWorld, *VMOutputVerifier)
 }
@@ -46,6 +47,13 @@ func (callerTest *MockInstancesTestTemplate) WithInput(input *vmcommon.ContractC
 	return callerTest
 }
 
+// WithFeatures enables the given features for the mock contract call test,
+// which otherwise runs with all the features disabled
+func (callerTest *MockInstancesTestTemplate) WithFeatures(flags ...arwen.FeatureFlag) *MockInstancesTestTemplate {
+	callerTest.features = append(callerTest.features, flags...)
+	return callerTest
+}
+
 // WithSetup provides the setup function to be used by the mock contract call test
 func (callerTest *MockInstancesTestTemplate) WithSetup(setup func(arwen.VMHost, *worldmock.MockWorld)) *MockInstancesTestTemplate {
 	callerTest.setup = setup
@@ -60,7 +68,7 @@ func (callerTest *MockInstancesTestTemplate) AndAssertResults(assertResults func
 
 func (callerTest *MockInstancesTestTemplate) runTest() {
 
-	host, world, imb := DefaultTestArwenForCallWithInstanceMocks(callerTest.t)
+	host, world, imb := DefaultTestArwenForCallWithInstanceMocks(callerTest.t, callerTest.features...)
 
 	for _, mockSC := range *callerTest.contracts {
 		mockSC.initialize(callerTest.t, host, imb)
@@ -115,7 +115,7 @@ func BuildSCModule(scName string, prefixToTestSCs string) {
 }
 
 // DefaultTestArwenForDeployment creates an Arwen vmHost configured for testing deployments
-func DefaultTestArwenForDeployment(t *testing.T, _ uint64, newAddress []byte) (arwen.VMHost, *contextmock.BlockchainHookStub) {
+func DefaultTestArwenForDeployment(t *testing.T, _ uint64, newAddress []byte, features ...arwen.FeatureFlag) (arwen.VMHost, *contextmock.BlockchainHookStub) {
 	stubBlockchainHook := &contextmock.BlockchainHookStub{}
 	stubBlockchainHook.GetUserAccountCalled = func(address []byte) (vmcommon.UserAccountHandler, error) {
 		return &contextmock.StubAccount{
@@ -126,7 +126,7 @@ func DefaultTestArwenForDeployment(t *testing.T, _ uint64, newAddress []byte) (a
 		return newAddress, nil
 	}
 
-	host := DefaultTestArwen(t, stubBlockchainHook)
+	host := DefaultTestArwenWithFeatures(t, stubBlockchainHook, features...)
 	return host, stubBlockchainHook
 }
 
@@ -161,9 +161,9 @@ func DefaultTestArwenForCallWithInstanceRecorderMock(tb testing.TB, code []byte,
 }
 
 // DefaultTestArwenForCallWithInstanceMocks creates an InstanceBuilderMock
-func DefaultTestArwenForCallWithInstanceMocks(tb testing.TB) (arwen.VMHost, *worldmock.MockWorld, *contextmock.InstanceBuilderMock) {
+func DefaultTestArwenForCallWithInstanceMocks(tb testing.TB, features ...arwen.FeatureFlag) (arwen.VMHost, *worldmock.MockWorld, *contextmock.InstanceBuilderMock) {
 	world := worldmock.NewMockWorld()
-	host := DefaultTestArwen(tb, world)
+	host := DefaultTestArwenWithFeatures(tb, world, features...)
 
 	instanceBuilderMock := contextmock.NewInstanceBuilderMock(world)
 	host.Runtime().ReplaceInstanceBuilder(instanceBuilderMock)
@@ -238,6 +238,7 @@ func DefaultTestArwenForTwoSCs(
 func defaultTestArwenForContracts(
 	t *testing.T,
 	contracts []*InstanceTestSmartContract,
+	features []arwen.FeatureFlag,
 ) (arwen.VMHost, *contextmock.BlockchainHookStub) {
 
 	stubBlockchainHook := &contextmock.BlockchainHookStub{}
@@ -270,7 +271,7 @@ func defaultTestArwenForContracts(
 		return nil
 	}
 
-	host := DefaultTestArwen(t, stubBlockchainHook)
+	host := DefaultTestArwenWithFeatures(t, stubBlockchainHook, features...)
 	return host, stubBlockchainHook
 }
 
@@ -301,6 +302,21 @@ func DefaultTestArwenWithWorldMock(tb testing.TB) (arwen.VMHost, *worldmock.Mock
 
 // DefaultTestArwen creates a host configured with a configured blockchain hook
 func DefaultTestArwen(tb testing.TB, blockchain vmcommon.BlockchainHook) arwen.VMHost {
+	return DefaultTestArwenWithFeatures(tb, blockchain)
+}
+
+// DefaultTestArwenWithFeatures creates a host configured with a configured
+// blockchain hook, which enables only the given features
+func DefaultTestArwenWithFeatures(
+	tb testing.TB,
+	blockchain vmcommon.BlockchainHook,
+	features ...arwen.FeatureFlag,
+) arwen.VMHost {
+	activationEpochs := make(map[arwen.FeatureFlag]uint32, len(features))
+	for _, flag := range features {
+		activationEpochs[flag] = 0
+	}
+
 	gasSchedule := customGasSchedule
 	if gasSchedule == nil {
 		gasSchedule = config.MakeGasMapForTests()
@@ -314,6 +330,7 @@ func DefaultTestArwen(tb testing.TB, blockchain vmcommon.BlockchainHook) arwen.V
 		BuiltInFuncContainer:     builtInFunctions.NewBuiltInFunctionContainer(),
 		ElrondProtectedKeyPrefix: []byte("ELROND"),
 		ESDTTransferParser:       esdtTransferParser,
+		FeatureActivationEpochs:  activationEpochs,
 	})
 	require.Nil(tb, err)
 	require.NotNil(tb, host)
@@ -418,6 +435,12 @@ func (contractInput *ContractCallInputBuilder) WithCurrentTxHash(txHash []byte)
 	return contractInput
 }
 
+// WithOriginalTxHash provides the OriginalTxHash for ContractCallInputBuilder
+func (contractInput *ContractCallInputBuilder) WithOriginalTxHash(txHash []byte) *ContractCallInputBuilder {
+	contractInput.ContractCallInput.OriginalTxHash = txHash
+	return contractInput
+}
+
 func (contractInput *ContractCallInputBuilder) initESDTTransferIfNeeded() {
 	if len(contractInput.ESDTTransfers) == 0 {
 		contractInput.ESDTTransfers = make([]*vmcommon.ESDTTransfer, 1)
@@ -114,6 +114,15 @@ func (v *VMOutputVerifier) BalanceDelta(address []byte, balanceDelta int64) *VMO
 	return v
 }
 
+// DeletedAccounts verifies if the deleted accounts are the same as the provided ones
+func (v *VMOutputVerifier) DeletedAccounts(addresses ...[]byte) *VMOutputVerifier {
+	require.Equal(v.T, len(addresses), len(v.VmOutput.DeletedAccounts), "DeletedAccounts")
+	for idx, address := range addresses {
+		require.Equal(v.T, address, v.VmOutput.DeletedAccounts[idx], "DeletedAccounts")
+	}
+	return v
+}
+
 // Nonce verifies if Nonce of the specified account is the same as the provided one
 func (v *VMOutputVerifier) Nonce(address []byte, nonce uint64) *VMOutputVerifier {
 	account := v.VmOutput.OutputAccounts[string(address)]
@@ -5,9 +5,11 @@ import "C"
 import (
 	"fmt"
 	"unsafe"
+
+	"github.com/ElrondNetwork/arwen-wasm-vm/v1_4/executor"
 )
 
-const OPCODE_COUNT = 448
+const OPCODE_COUNT = executor.OPCODE_COUNT
 
 // InstanceError represents any kind of errors related to a WebAssembly instance. It
 // is returned by `Instance` functions only.
@@ -28,37 +30,21 @@ func (error *InstanceError) Error() string {
 }
 
 // ExportedFunctionError represents any kind of errors related to a
-// WebAssembly exported function. It is returned by `Instance`
-// functions only.
-type ExportedFunctionError struct {
-	functionName string
-	message      string
-}
+// WebAssembly exported function; see executor.ExportedFunctionError.
+type ExportedFunctionError = executor.ExportedFunctionError
 
 // ExportedFunctionSignature holds information about the input/output arities
 // of an exported function
-type ExportedFunctionSignature struct {
-	InputArity  int
-	OutputArity int
-}
+type ExportedFunctionSignature = executor.ExportedFunctionSignature
 
-// NewExportedFunctionError constructs a new `ExportedFunctionError`,
-// where `functionName` is the name of the exported function, and
-// `message` is the error message. If the error message contains `%s`,
-// then this parameter will be replaced by `functionName`.
+// NewExportedFunctionError constructs a new `ExportedFunctionError`.
 func NewExportedFunctionError(functionName string, message string) *ExportedFunctionError {
-	return &ExportedFunctionError{functionName, message}
-}
-
-// ExportedFunctionError is an actual error. The `Error` function
-// returns the error message.
-func (error *ExportedFunctionError) Error() string {
-	return error.message
+	return executor.NewExportedFunctionError(functionName, message)
 }
 
-type ExportedFunctionCallback func(...interface{}) (Value, error)
-type ExportsMap map[string]ExportedFunctionCallback
-type ExportSignaturesMap map[string]*ExportedFunctionSignature
+type ExportedFunctionCallback = executor.ExportedFunctionCallback
+type ExportsMap = executor.ExportsMap
+type ExportSignaturesMap = executor.ExportSignaturesMap
 
 // Instance represents a WebAssembly instance.
 type Instance struct {
@@ -91,13 +77,7 @@ type Instance struct {
 	InstanceCtx InstanceContext
 }
 
-type CompilationOptions struct {
-	GasLimit           uint64
-	UnmeteredLocals    uint64
-	OpcodeTrace        bool
-	Metering           bool
-	RuntimeBreakpoints bool
-}
+type CompilationOptions = executor.CompilationOptions
 
 func newWrappedError(target error) error {
 	var lastError string
@@ -122,11 +102,14 @@ func SetImports(imports *Imports) error {
 	if result != cWasmerOk {
 		return newWrappedError(ErrFailedCacheImports)
 	}
+
+	executor.SetImports(imports)
 	return nil
 }
 
 func SetOpcodeCosts(opcode_costs *[OPCODE_COUNT]uint32) {
 	cWasmerSetOpcodeCosts(opcode_costs)
+	executor.SetOpcodeCosts(opcode_costs)
 }
 
 func NewInstanceWithOptions(
@@ -259,6 +242,8 @@ func (instance *Instance) SetGasLimit(gasLimit uint64) {
 	cWasmerInstanceSetGasLimit(instance.instance, gasLimit)
 }
 
+// SetBreakpointValue sets the breakpoint value; it may be called while the
+// instance is running, from another goroutine, e.g. by the execution watchdog
 func (instance *Instance) SetBreakpointValue(value uint64) {
 	cWasmerInstanceSetBreakpointValue(instance.instance, value)
 }
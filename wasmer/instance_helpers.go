@@ -10,40 +10,40 @@ func generateWasmerImports(imports *Imports) (*cWasmerImportT, int) {
 	var wasmImports = make([]cWasmerImportT, numberOfImports)
 	var importFunctionNth = 0
 
-	for _, namespacedImports := range imports.imports {
-		for importName, importFunction := range namespacedImports {
-			var wasmInputsArity = len(importFunction.wasmInputs)
-			var wasmOutputsArity = len(importFunction.wasmOutputs)
+	imports.ForEach(func(importName string, importFunction Import) {
+		var wasmInputs = toWasmerValueTags(importFunction.Inputs)
+		var wasmOutputs = toWasmerValueTags(importFunction.Outputs)
+		var wasmInputsArity = len(wasmInputs)
+		var wasmOutputsArity = len(wasmOutputs)
 
-			var importFunctionInputsCPointer *cWasmerValueTag
-			var importFunctionOutputsCPointer *cWasmerValueTag
+		var importFunctionInputsCPointer *cWasmerValueTag
+		var importFunctionOutputsCPointer *cWasmerValueTag
 
-			if wasmInputsArity > 0 {
-				importFunctionInputsCPointer = (*cWasmerValueTag)(unsafe.Pointer(&importFunction.wasmInputs[0]))
-			}
-
-			if wasmOutputsArity > 0 {
-				importFunctionOutputsCPointer = (*cWasmerValueTag)(unsafe.Pointer(&importFunction.wasmOutputs[0]))
-			}
+		if wasmInputsArity > 0 {
+			importFunctionInputsCPointer = (*cWasmerValueTag)(unsafe.Pointer(&wasmInputs[0]))
+		}
 
-			importFunction.importedFunctionPointer = cWasmerImportFuncNew(
-				importFunction.cgoPointer,
-				importFunctionInputsCPointer,
-				cUint(wasmInputsArity),
-				importFunctionOutputsCPointer,
-				cUint(wasmOutputsArity),
-			)
-
-			var importedFunction = cNewWasmerImportT(
-				importFunction.namespace,
-				importName,
-				importFunction.importedFunctionPointer,
-			)
-
-			wasmImports[importFunctionNth] = importedFunction
-			importFunctionNth++
+		if wasmOutputsArity > 0 {
+			importFunctionOutputsCPointer = (*cWasmerValueTag)(unsafe.Pointer(&wasmOutputs[0]))
 		}
-	}
+
+		var importedFunctionPointer = cWasmerImportFuncNew(
+			importFunction.CgoPointer,
+			importFunctionInputsCPointer,
+			cUint(wasmInputsArity),
+			importFunctionOutputsCPointer,
+			cUint(wasmOutputsArity),
+		)
+
+		var importedFunction = cNewWasmerImportT(
+			importFunction.Namespace,
+			importName,
+			importedFunctionPointer,
+		)
+
+		wasmImports[importFunctionNth] = importedFunction
+		importFunctionNth++
+	})
 
 	var wasmImportsCPointer *cWasmerImportT
 
@@ -54,6 +54,21 @@ func generateWasmerImports(imports *Imports) (*cWasmerImportT, int) {
 	return wasmImportsCPointer, numberOfImports
 }
 
+func toWasmerValueTags(valueTypes []ValueType) []cWasmerValueTag {
+	var valueTags = make([]cWasmerValueTag, len(valueTypes))
+
+	for nth, valueType := range valueTypes {
+		switch valueType {
+		case TypeI32:
+			valueTags[nth] = cWasmI32
+		case TypeI64:
+			valueTags[nth] = cWasmI64
+		}
+	}
+
+	return valueTags
+}
+
 func retrieveExportedMemory(wasmExports *cWasmerExportsT) (Memory, bool, error) {
 	var numberOfExports = int(cWasmerExportsLen(wasmExports))
 
@@ -1,2 +1,7 @@
 // Package wasmer is a Go library to run WebAssembly binaries.
 package wasmer
+
+// LibraryVersion identifies the build of the Wasmer library bound by this
+// package; it must be changed whenever the library is updated, because the
+// code compiled by one build cannot be loaded by another
+const LibraryVersion = "1"